@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/rad-security/kbom/internal/config"
+	"github.com/rad-security/kbom/internal/fleet"
+	"github.com/rad-security/kbom/internal/model"
+	"github.com/rad-security/kbom/internal/utils"
+)
+
+var AggregateCmd = &cobra.Command{
+	Use:   "aggregate <kbom.json>...",
+	Short: "Merge per-cluster KBOMs into one fleet document",
+	Long: `Merge KBOMs generated in the native JSON format, e.g. with --all-contexts, into one fleet document.
+It lists the clusters running each image digest, the Kubernetes versions in use, the node OS distributions
+and the clusters each CRD and Helm chart is installed in. CycloneDX formats nest the CRDs and Helm charts
+in a component per cluster.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runAggregate,
+}
+
+func init() {
+	AggregateCmd.Flags().StringVarP(&output, "output", "o", StdOutput, "Output (stdout, file)")
+	AggregateCmd.Flags().StringVarP(&format, "format", "f", JSONFormat.Name, fmt.Sprintf("Format (%s)", strings.Join(formatNames(), ", ")))
+	AggregateCmd.Flags().StringVarP(&outPath, "out-path", "p", ".", "Path to write the fleet file to. Works only with --output=file")
+
+	utils.BindFlags(AggregateCmd)
+}
+
+func runAggregate(cmd *cobra.Command, args []string) error {
+	parsedFormat, err := formatFromName(format)
+	if err != nil {
+		return err
+	}
+
+	kboms := make([]*model.KBOM, 0, len(args))
+	for _, filePath := range args {
+		kbom, err := readKBOM(filePath)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filePath, err)
+		}
+		// the fleet index and CycloneDX documents decode without error, but carry none of the KBOM fields
+		if kbom.ID == "" && kbom.Cluster.Name == "" {
+			return fmt.Errorf("%s: not a KBOM in the %s format", filePath, JSONFormat.Name)
+		}
+
+		kboms = append(kboms, kbom)
+	}
+
+	doc := fleet.Aggregate(kboms)
+	doc.ID = uuid.New().String()
+	doc.GeneratedAt = generatedAt
+	doc.GeneratedBy = model.Tool{
+		Vendor:     Company,
+		BuildTime:  config.BuildTime,
+		Name:       config.AppName,
+		Version:    config.AppVersion,
+		Commit:     config.LastCommitHash,
+		CommitTime: config.LastCommitTime,
+	}
+
+	writer, err := getFleetWriter(doc, parsedFormat)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	return printFleet(writer, doc, parsedFormat)
+}
+
+// printFleet encodes the fleet document in the given format to w
+func printFleet(w io.Writer, doc *model.Fleet, f Format) error {
+	switch f.Name {
+	case JSONFormat.Name:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	case YAMLFormat.Name:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		return enc.Encode(doc)
+	case CycloneDXJsonFormat.Name:
+		enc := cyclonedx.NewBOMEncoder(w, cyclonedx.BOMFileFormatJSON)
+		enc.SetPretty(true)
+		enc.SetEscapeHTML(false)
+		return enc.Encode(transformFleetToCycloneDXBOM(doc))
+	case CycloneDXXMLFormat.Name:
+		enc := cyclonedx.NewBOMEncoder(w, cyclonedx.BOMFileFormatXML)
+		enc.SetPretty(true)
+		enc.SetEscapeHTML(false)
+		return enc.Encode(transformFleetToCycloneDXBOM(doc))
+	default:
+		return fmt.Errorf("format %q is not supported", f.Name)
+	}
+}
+
+func getFleetWriter(doc *model.Fleet, format Format) (io.WriteCloser, error) {
+	switch output {
+	case StdOutput:
+		return out, nil
+	case FileOutput:
+		name := fmt.Sprintf("kbom-aggregate-%s.%s", doc.GeneratedAt.Format("2006-01-02-15-04-05"), format.FileExtension)
+		return os.Create(path.Join(outPath, name))
+	default:
+		return nil, fmt.Errorf("output %q is not supported", output)
+	}
+}
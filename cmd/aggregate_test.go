@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+func TestAggregate(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{filepath.Join(dir, "prod.json"), filepath.Join(dir, "staging.json")}
+	require.NoError(t, os.WriteFile(files[0], []byte(`{"id": "id-1", "cluster": {"name": "prod", "k8s_version": "1.28.3",
+		"nodes": [{"name": "n1", "os": {"name": "ubuntu", "version": "22.04"}}],
+		"components": {"images": [{"name": "docker.io/library/nginx", "version": "1.25", "digest": "sha256:aaa"}],
+		"resources": {"apiextensions.k8s.io/v1, Resource=customresourcedefinitions": {"kind": "CustomResourceDefinition",
+		"resources": [{"name": "certificates.cert-manager.io", "additional_properties": {"chart": "cert-manager-v1.13.2"}}]}}}}}`), 0o600))
+	require.NoError(t, os.WriteFile(files[1], []byte(`{"id": "id-2", "cluster": {"name": "staging", "k8s_version": "1.29.0",
+		"components": {"images": [{"name": "docker.io/library/nginx", "version": "1.25", "digest": "sha256:aaa"}]}}}`), 0o600))
+
+	mock := &stdoutMock{buf: bytes.Buffer{}}
+	out = mock
+	output = StdOutput
+	generatedAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	format = "wrong"
+	assert.EqualError(t, runAggregate(nil, files), "format \"wrong\" is not supported")
+
+	format = JSONFormat.Name
+	assert.ErrorContains(t, runAggregate(nil, []string{filepath.Join(dir, "missing.json")}), "missing.json: open")
+
+	index := filepath.Join(dir, "kbom-fleet.json")
+	require.NoError(t, os.WriteFile(index, []byte(`{"clusters": [{"context": "prod"}]}`), 0o600))
+	assert.EqualError(t, runAggregate(nil, []string{index}), index+": not a KBOM in the json format")
+
+	require.NoError(t, runAggregate(nil, files))
+	fleet := &model.Fleet{}
+	require.NoError(t, json.Unmarshal(mock.buf.Bytes(), fleet))
+	assert.Equal(t, generatedAt, fleet.GeneratedAt)
+	assert.Len(t, fleet.Clusters, 2)
+	assert.Equal(t, []model.FleetImage{
+		{Name: "docker.io/library/nginx", Digest: "sha256:aaa", Tags: []string{"1.25"}, Clusters: []string{"prod", "staging"}},
+	}, fleet.Images)
+	assert.Equal(t, []model.FleetPresence{{Name: "certificates.cert-manager.io", Clusters: []string{"prod"}}}, fleet.CRDs)
+
+	mock.buf.Reset()
+	format = CycloneDXJsonFormat.Name
+	defer func() { format = JSONFormat.Name }()
+	require.NoError(t, runAggregate(nil, files))
+
+	bom := &cyclonedx.BOM{}
+	require.NoError(t, cyclonedx.NewBOMDecoder(&mock.buf, cyclonedx.BOMFileFormatJSON).Decode(bom))
+	assert.Equal(t, FleetType, bom.Metadata.Component.Name)
+
+	clusters := map[string]cyclonedx.Component{}
+	for _, c := range *bom.Components {
+		if c.Type == cyclonedx.ComponentTypePlatform {
+			clusters[c.Version] = c
+		}
+	}
+	require.Len(t, clusters, 2)
+	prod := clusters["1.28.3"]
+	assert.Equal(t, "pkg:k8s/k8s.io%2Fkubernetes@1.28.3", prod.PackageURL)
+	require.NotNil(t, prod.Components)
+	assert.ElementsMatch(t, []string{"certificates.cert-manager.io", "cert-manager-v1.13.2"},
+		[]string{(*prod.Components)[0].Name, (*prod.Components)[1].Name})
+	assert.Nil(t, clusters["1.29.0"].Components)
+
+	dependencies := map[string][]string{}
+	for _, d := range *bom.Dependencies {
+		dependencies[d.Ref] = *d.Dependencies
+	}
+	nginxRef := "pkg:oci/nginx@sha256%3Aaaa?repository_url=docker.io%2Flibrary%2Fnginx"
+	assert.Equal(t, []string{"pkg:generic/ubuntu@22.04", nginxRef}, dependencies[prod.BOMRef])
+	assert.Equal(t, []string{nginxRef}, dependencies[clusters["1.29.0"].BOMRef])
+	assert.ElementsMatch(t, []string{prod.BOMRef, clusters["1.29.0"].BOMRef}, dependencies[bom.Metadata.Component.BOMRef])
+}
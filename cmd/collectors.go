@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/rad-security/kbom/internal/kube"
+	"github.com/rad-security/kbom/internal/model"
+)
+
+const (
+	// allSections selects every optional section with --include
+	allSections = "all"
+
+	excludeUsage = "Optional sections to leave out, e.g. of --include all"
+)
+
+// collector fills an optional section of the KBOM, enabled with --include. resources are the resources it lists,
+// checked by preflight. Sections resolving pods to workloads set podTemplates, they get the templates listed once per
+// KBOM and nil otherwise.
+type collector struct {
+	name         string
+	resources    []schema.GroupResource
+	podTemplates bool
+	collect      func(ctx context.Context, k8sClient kube.K8sClient, podTemplates *kube.PodTemplates, kbom *model.KBOM) error
+}
+
+// workloadResources are listed by the sections resolving pods to the workloads running them
+var workloadResources = []schema.GroupResource{
+	{Group: "apps", Resource: "deployments"},
+	{Group: "apps", Resource: "statefulsets"},
+	{Group: "apps", Resource: "daemonsets"},
+	{Group: "apps", Resource: "replicasets"},
+	{Group: "batch", Resource: "cronjobs"},
+	{Group: "batch", Resource: "jobs"},
+	{Resource: "pods"},
+}
+
+var collectors = []collector{
+	{
+		name: "rbac",
+		resources: []schema.GroupResource{
+			{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"},
+			{Group: "rbac.authorization.k8s.io", Resource: "roles"},
+			{Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"},
+			{Group: "rbac.authorization.k8s.io", Resource: "rolebindings"},
+		},
+		collect: func(ctx context.Context, k8sClient kube.K8sClient, podTemplates *kube.PodTemplates, kbom *model.KBOM) error {
+			rbac, err := k8sClient.RBAC(ctx)
+			if err != nil {
+				return err
+			}
+
+			kbom.Cluster.Components.RBAC = rbac
+			return nil
+		},
+	},
+	{
+		name:         "podsecurity",
+		resources:    append([]schema.GroupResource{{Resource: "namespaces"}}, workloadResources...),
+		podTemplates: true,
+		collect: func(ctx context.Context, k8sClient kube.K8sClient, podTemplates *kube.PodTemplates, kbom *model.KBOM) error {
+			podSecurity, err := k8sClient.PodSecurity(ctx, podTemplates)
+			if err != nil {
+				return err
+			}
+
+			kbom.Cluster.Components.PodSecurity = podSecurity
+			return nil
+		},
+	},
+	{
+		name: "admission",
+		resources: append([]schema.GroupResource{
+			{Group: "admissionregistration.k8s.io", Resource: "validatingwebhookconfigurations"},
+			{Group: "admissionregistration.k8s.io", Resource: "mutatingwebhookconfigurations"},
+			{Group: "admissionregistration.k8s.io", Resource: "validatingadmissionpolicies"},
+			{Group: "admissionregistration.k8s.io", Resource: "validatingadmissionpolicybindings"},
+		}, workloadResources...),
+		podTemplates: true,
+		collect: func(ctx context.Context, k8sClient kube.K8sClient, podTemplates *kube.PodTemplates, kbom *model.KBOM) error {
+			admission, err := k8sClient.Admission(ctx, podTemplates)
+			if err != nil {
+				return err
+			}
+
+			kbom.Cluster.Components.Admission = admission
+			return nil
+		},
+	},
+	{
+		name: "network",
+		resources: append([]schema.GroupResource{
+			{Resource: "services"},
+			{Resource: "namespaces"},
+			{Group: "networking.k8s.io", Resource: "ingresses"},
+			{Group: "networking.k8s.io", Resource: "networkpolicies"},
+			{Group: "gateway.networking.k8s.io", Resource: "gateways"},
+			{Group: "gateway.networking.k8s.io", Resource: "httproutes"},
+			{Group: "gateway.networking.k8s.io", Resource: "grpcroutes"},
+			{Group: "gateway.networking.k8s.io", Resource: "tlsroutes"},
+			{Group: "gateway.networking.k8s.io", Resource: "tcproutes"},
+		}, workloadResources...),
+		podTemplates: true,
+		collect: func(ctx context.Context, k8sClient kube.K8sClient, podTemplates *kube.PodTemplates, kbom *model.KBOM) error {
+			network, err := k8sClient.Network(ctx, podTemplates)
+			if err != nil {
+				return err
+			}
+
+			kbom.Cluster.Components.Network = network
+			return nil
+		},
+	},
+	{
+		name: "storage",
+		resources: append([]schema.GroupResource{
+			{Group: "storage.k8s.io", Resource: "csidrivers"},
+			{Group: "storage.k8s.io", Resource: "storageclasses"},
+			{Resource: "persistentvolumes"},
+			{Resource: "persistentvolumeclaims"},
+		}, workloadResources...),
+		podTemplates: true,
+		collect: func(ctx context.Context, k8sClient kube.K8sClient, podTemplates *kube.PodTemplates, kbom *model.KBOM) error {
+			storage, err := k8sClient.Storage(ctx, podTemplates)
+			if err != nil {
+				return err
+			}
+
+			kbom.Cluster.Components.Storage = storage
+			return nil
+		},
+	},
+	{
+		name: "mesh",
+		resources: append([]schema.GroupResource{
+			{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"},
+		}, workloadResources...),
+		podTemplates: true,
+		collect: func(ctx context.Context, k8sClient kube.K8sClient, podTemplates *kube.PodTemplates, kbom *model.KBOM) error {
+			serviceMesh, err := k8sClient.ServiceMesh(ctx, podTemplates)
+			if err != nil {
+				return err
+			}
+
+			kbom.Cluster.Components.ServiceMesh = serviceMesh
+			return nil
+		},
+	},
+	{
+		name:      "nodepools",
+		resources: []schema.GroupResource{{Resource: "nodes"}},
+		collect: func(ctx context.Context, k8sClient kube.K8sClient, podTemplates *kube.PodTemplates, kbom *model.KBOM) error {
+			nodePools, err := k8sClient.NodePools(ctx, nodePoolLabel)
+			if err != nil {
+				return err
+			}
+
+			kbom.Cluster.NodePools = nodePools
+			return nil
+		},
+	},
+	{
+		name:         "workloads",
+		resources:    workloadResources,
+		podTemplates: true,
+		collect: func(ctx context.Context, k8sClient kube.K8sClient, podTemplates *kube.PodTemplates, kbom *model.KBOM) error {
+			workloads, err := k8sClient.Workloads(ctx, podTemplates)
+			if err != nil {
+				return err
+			}
+
+			kbom.Cluster.Components.Workloads = workloads
+			return nil
+		},
+	},
+	{
+		name: "pullsecrets",
+		resources: append([]schema.GroupResource{
+			{Resource: "secrets"},
+			{Resource: "serviceaccounts"},
+		}, workloadResources...),
+		podTemplates: true,
+		collect: func(ctx context.Context, k8sClient kube.K8sClient, podTemplates *kube.PodTemplates, kbom *model.KBOM) error {
+			pullSecrets, err := k8sClient.PullSecrets(ctx, podTemplates)
+			if err != nil {
+				return err
+			}
+
+			kbom.Cluster.Components.PullSecrets = pullSecrets
+			return nil
+		},
+	},
+}
+
+// permissions returns the permissions the collector needs
+func (c *collector) permissions() []kube.Permission {
+	return kube.ListPermissions(c.name, c.resources...)
+}
+
+func collectorNames() []string {
+	names := make([]string, 0, len(collectors))
+	for _, c := range collectors {
+		names = append(names, c.name)
+	}
+
+	return names
+}
+
+// enabledCollectors returns the collectors selected by name with --include, or all of them with "all", except the ones
+// left out with --exclude, in registration order
+func enabledCollectors(include, exclude []string) ([]collector, error) {
+	for _, name := range append(slices.Clone(include), exclude...) {
+		if name != allSections && !slices.Contains(collectorNames(), name) {
+			return nil, fmt.Errorf("section %q is not supported, use one of: %s", name, strings.Join(collectorNames(), ", "))
+		}
+	}
+
+	enabled := make([]collector, 0, len(include))
+	for _, c := range collectors {
+		if (slices.Contains(include, allSections) || slices.Contains(include, c.name)) && !slices.Contains(exclude, c.name) {
+			enabled = append(enabled, c)
+		}
+	}
+
+	return enabled, nil
+}
+
+// includeUsage is the usage of the --include flag, listing the sections
+func includeUsage() string {
+	return fmt.Sprintf("Optional sections to include (%s, %s)", allSections, strings.Join(collectorNames(), ", "))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"hash/fnv"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/CycloneDX/cyclonedx-go"
@@ -11,6 +12,7 @@ import (
 	"github.com/mitchellh/hashstructure/v2"
 
 	"github.com/rad-security/kbom/internal/model"
+	"github.com/rad-security/kbom/internal/vex"
 )
 
 const (
@@ -20,9 +22,18 @@ const (
 	K8sComponentName    = "k8s:component:name"
 	K8sComponentVersion = "k8s:component:version"
 
+	NamespaceKind = "Namespace"
+
 	ClusterType   = "cluster"
 	NodeType      = "node"
 	ContainerType = "container"
+	CSIDriverType = "csi-driver"
+	RuntimeType   = "container-runtime"
+	OSType        = "operating-system"
+	KernelType    = "kernel"
+	FleetType     = "fleet"
+	CRDType       = "CustomResourceDefinition"
+	HelmChartType = "helm-chart"
 )
 
 func transformToCycloneDXBOM(kbom *model.KBOM) *cyclonedx.BOM { //nolint:funlen
@@ -57,24 +68,29 @@ func transformToCycloneDXBOM(kbom *model.KBOM) *cyclonedx.BOM { //nolint:funlen
 		},
 	}
 
-	if kbom.Cluster.Location.Name != "" && kbom.Cluster.Location.Name != "unknown" {
+	location := kbom.Cluster.Location
+	if location == nil {
+		location = &model.Location{}
+	}
+
+	if location.Name != "" && location.Name != "unknown" {
 		clusterProperties = append(clusterProperties, cyclonedx.Property{
 			Name:  RADPrefix + "k8s:cluster:location:name",
-			Value: kbom.Cluster.Location.Name,
+			Value: location.Name,
 		})
 	}
 
-	if kbom.Cluster.Location.Region != "" {
+	if location.Region != "" {
 		clusterProperties = append(clusterProperties, cyclonedx.Property{
 			Name:  RADPrefix + "k8s:cluster:location:region",
-			Value: kbom.Cluster.Location.Region,
+			Value: location.Region,
 		})
 	}
 
-	if kbom.Cluster.Location.Zone != "" {
+	if location.Zone != "" {
 		clusterProperties = append(clusterProperties, cyclonedx.Property{
 			Name:  RADPrefix + "k8s:cluster:location:zone",
-			Value: kbom.Cluster.Location.Zone,
+			Value: location.Zone,
 		})
 	}
 
@@ -88,9 +104,13 @@ func transformToCycloneDXBOM(kbom *model.KBOM) *cyclonedx.BOM { //nolint:funlen
 	cdxBOM.Metadata.Component = &clusterComponent
 
 	clusterDependencies := make(map[string]string)
+	findingRefs := map[string]string{
+		findingKey(model.ClusterTarget, "", "", kbom.Cluster.Name): clusterComponent.BOMRef,
+	}
 	for i := range kbom.Cluster.Nodes {
 		n := kbom.Cluster.Nodes[i]
 		bomRef := id(n)
+		findingRefs[findingKey(model.NodeTarget, "", "", n.Name)] = bomRef
 		components = append(components, cyclonedx.Component{
 			BOMRef: bomRef,
 			Type:   cyclonedx.ComponentTypePlatform,
@@ -185,6 +205,10 @@ func transformToCycloneDXBOM(kbom *model.KBOM) *cyclonedx.BOM { //nolint:funlen
 		clusterDependencies[bomRef] = bomRef
 	}
 
+	softwareComponents, softwareDependencies := nodeSoftwareComponents(kbom.Cluster.Nodes)
+	components = append(components, softwareComponents...)
+	dependencies = append(dependencies, softwareDependencies...)
+
 	for _, img := range kbom.Cluster.Components.Images {
 		bomRef := img.PkgID()
 		container := cyclonedx.Component{
@@ -218,8 +242,18 @@ func transformToCycloneDXBOM(kbom *model.KBOM) *cyclonedx.BOM { //nolint:funlen
 					Name:  RADPrefix + "pkg:digest",
 					Value: img.Digest,
 				},
+				{
+					Name:  RADPrefix + "pkg:registry",
+					Value: img.Registry,
+				},
+				{
+					Name:  RADPrefix + "pkg:repository",
+					Value: img.Repository,
+				},
 			},
 		}
+		*container.Properties = append(*container.Properties, imagePinningProperties(&img)...)
+		*container.Properties = append(*container.Properties, imagePlatformProperties(img.Platforms)...)
 
 		components = append(components, container)
 
@@ -228,6 +262,29 @@ func transformToCycloneDXBOM(kbom *model.KBOM) *cyclonedx.BOM { //nolint:funlen
 		}
 	}
 
+	if storage := kbom.Cluster.Components.Storage; storage != nil {
+		csiComponents, csiDependencies := csiDriverComponents(storage.CSIDrivers, kbom.Cluster.Components.Images)
+		for _, c := range csiComponents {
+			clusterDependencies[c.BOMRef] = c.BOMRef
+		}
+		components = append(components, csiComponents...)
+		dependencies = append(dependencies, csiDependencies...)
+	}
+
+	if workloads := kbom.Cluster.Components.Workloads; workloads != nil {
+		workloadComps, workloadDependencies := workloadComponents(workloads.Items, kbom.Cluster.Components.Images,
+			kbom.Cluster.Components.PodSecurity)
+		components = append(components, workloadComps...)
+		dependencies = append(dependencies, workloadDependencies...)
+	}
+
+	namespaceSecurity := make(map[string]model.NamespaceSecurity)
+	if kbom.Cluster.Components.PodSecurity != nil {
+		for _, ns := range kbom.Cluster.Components.PodSecurity.Namespaces {
+			namespaceSecurity[ns.Name] = ns
+		}
+	}
+
 	for _, resList := range kbom.Cluster.Components.Resources {
 		for _, res := range resList.Resources {
 			properties := []cyclonedx.Property{
@@ -252,6 +309,13 @@ func transformToCycloneDXBOM(kbom *model.KBOM) *cyclonedx.BOM { //nolint:funlen
 				})
 			}
 
+			if chart, ok := res.AdditionalProperties["chart"]; ok {
+				properties = append(properties, cyclonedx.Property{
+					Name:  RADPrefix + "k8s:component:helmChart",
+					Value: chart,
+				})
+			}
+
 			if resList.Namespaced {
 				properties = append(properties, cyclonedx.Property{
 					Name:  RADPrefix + "k8s:component:namespace",
@@ -259,8 +323,14 @@ func transformToCycloneDXBOM(kbom *model.KBOM) *cyclonedx.BOM { //nolint:funlen
 				})
 			}
 
+			if ns, ok := namespaceSecurity[res.Name]; ok && resList.Kind == NamespaceKind {
+				properties = append(properties, namespaceSecurityProperties(&ns)...)
+			}
+
+			bomRef := id(res)
+			findingRefs[findingKey(model.ResourceTarget, resList.Kind, res.Namespace, res.Name)] = bomRef
 			resource := cyclonedx.Component{
-				BOMRef:     id(res),
+				BOMRef:     bomRef,
 				Type:       cyclonedx.ComponentTypeApplication, // TODO: this is not perfect but we don't have a better option
 				Name:       res.Name,
 				Version:    res.APIVersion,
@@ -287,9 +357,400 @@ func transformToCycloneDXBOM(kbom *model.KBOM) *cyclonedx.BOM { //nolint:funlen
 	cdxBOM.Components = &components
 	cdxBOM.Dependencies = &dependencies
 
+	if len(kbom.Findings) > 0 {
+		emitted := map[string]bool{clusterComponent.BOMRef: true}
+		for i := range components {
+			emitted[components[i].BOMRef] = true
+		}
+		vulnerabilities := transformFindings(kbom.Findings, emitted, findingRefs)
+		cdxBOM.Vulnerabilities = &vulnerabilities
+	}
+
 	return cdxBOM
 }
 
+// nodeSoftwareComponents returns a component per distinct container runtime, OS and kernel of the nodes,
+// together with the dependencies of each node on them
+func nodeSoftwareComponents(nodes []model.Node) ([]cyclonedx.Component, []cyclonedx.Dependency) {
+	components := make([]cyclonedx.Component, 0)
+	dependencies := make([]cyclonedx.Dependency, 0, len(nodes))
+	seen := make(map[string]bool)
+	for i := range nodes {
+		n := nodes[i]
+		software := []struct {
+			software      *model.Software
+			k8sType       string
+			componentType cyclonedx.ComponentType
+		}{
+			{software: n.Runtime, k8sType: RuntimeType, componentType: cyclonedx.ComponentTypeApplication},
+			{software: n.OS, k8sType: OSType, componentType: cyclonedx.ComponentTypeOS},
+			{software: n.Kernel, k8sType: KernelType, componentType: cyclonedx.ComponentTypeOS},
+		}
+
+		refs := make([]string, 0, len(software))
+		for _, sw := range software {
+			if sw.software == nil {
+				continue
+			}
+
+			bomRef := sw.software.PkgID()
+			refs = append(refs, bomRef)
+			if seen[bomRef] {
+				continue
+			}
+			seen[bomRef] = true
+
+			components = append(components, cyclonedx.Component{
+				BOMRef:     bomRef,
+				Type:       sw.componentType,
+				Name:       sw.software.Name,
+				Version:    sw.software.Version,
+				PackageURL: bomRef,
+				Properties: &[]cyclonedx.Property{
+					{
+						Name:  CdxPrefix + K8sComponentType,
+						Value: sw.k8sType,
+					},
+					{
+						Name:  CdxPrefix + K8sComponentName,
+						Value: sw.software.Name,
+					},
+				},
+			})
+		}
+
+		if len(refs) > 0 {
+			dependencies = append(dependencies, cyclonedx.Dependency{Ref: id(n), Dependencies: &refs})
+		}
+	}
+
+	return components, dependencies
+}
+
+// workloadComponents returns an application component per workload depending on its container images.
+// Security settings are added as properties when the podsecurity section is present.
+func workloadComponents(workloads []model.Workload, images []model.Image,
+	podSecurity *model.PodSecurity) ([]cyclonedx.Component, []cyclonedx.Dependency) {
+	imageRefs := make(map[string]string, len(images))
+	for i := range images {
+		imageRefs[images[i].FullName] = images[i].PkgID()
+	}
+
+	security := make(map[string]model.WorkloadSecurity)
+	if podSecurity != nil {
+		for _, ns := range podSecurity.Namespaces {
+			for _, w := range ns.Workloads {
+				security[findingKey(model.ResourceTarget, w.Kind, w.Namespace, w.Name)] = w
+			}
+		}
+	}
+
+	components := make([]cyclonedx.Component, 0, len(workloads))
+	dependencies := make([]cyclonedx.Dependency, 0, len(workloads))
+	for i := range workloads {
+		w := workloads[i]
+		properties := []cyclonedx.Property{
+			{
+				Name:  CdxPrefix + K8sComponentType,
+				Value: w.Kind,
+			},
+			{
+				Name:  CdxPrefix + K8sComponentName,
+				Value: w.Name,
+			},
+			{
+				Name:  RADPrefix + "k8s:component:namespace",
+				Value: w.Namespace,
+			},
+			{
+				Name:  RADPrefix + "k8s:workload:serviceAccount",
+				Value: w.ServiceAccount,
+			},
+		}
+
+		if w.Replicas != nil {
+			properties = append(properties, cyclonedx.Property{
+				Name:  RADPrefix + "k8s:workload:replicas",
+				Value: fmt.Sprintf("%d", *w.Replicas),
+			})
+		}
+
+		for _, o := range w.Owners {
+			properties = append(properties, cyclonedx.Property{
+				Name:  RADPrefix + "k8s:workload:owner",
+				Value: o.Kind + "/" + o.Name,
+			})
+		}
+
+		if ws, ok := security[findingKey(model.ResourceTarget, w.Kind, w.Namespace, w.Name)]; ok {
+			properties = append(properties, workloadSecurityProperties(&ws)...)
+		}
+
+		refs := make([]string, 0, len(w.Containers))
+		for _, c := range w.Containers {
+			if ref, ok := imageRefs[c.Image]; ok && !slices.Contains(refs, ref) {
+				refs = append(refs, ref)
+			}
+		}
+		slices.Sort(refs)
+
+		bomRef := id(w)
+		components = append(components, cyclonedx.Component{
+			BOMRef:     bomRef,
+			Type:       cyclonedx.ComponentTypeApplication,
+			Name:       w.Name,
+			Properties: &properties,
+		})
+		dependencies = append(dependencies, cyclonedx.Dependency{Ref: bomRef, Dependencies: &refs})
+	}
+
+	return components, dependencies
+}
+
+func workloadSecurityProperties(w *model.WorkloadSecurity) []cyclonedx.Property {
+	flags := []struct {
+		name  string
+		value bool
+	}{
+		{name: "privileged", value: w.Privileged},
+		{name: "hostNetwork", value: w.HostNetwork},
+		{name: "hostPID", value: w.HostPID},
+		{name: "hostIPC", value: w.HostIPC},
+		{name: "hostPath", value: len(w.HostPathMounts) > 0},
+		{name: "runAsNonRoot", value: w.RunAsNonRoot},
+		{name: "readOnlyRootFilesystem", value: w.ReadOnlyRootFilesystem},
+	}
+
+	properties := make([]cyclonedx.Property, 0, len(flags)+1)
+	for _, f := range flags {
+		properties = append(properties, cyclonedx.Property{
+			Name:  RADPrefix + "k8s:workload:security:" + f.name,
+			Value: fmt.Sprintf("%t", f.value),
+		})
+	}
+
+	if len(w.AddedCapabilities) > 0 {
+		properties = append(properties, cyclonedx.Property{
+			Name:  RADPrefix + "k8s:workload:security:addedCapabilities",
+			Value: strings.Join(w.AddedCapabilities, ","),
+		})
+	}
+
+	return properties
+}
+
+// csiDriverComponents returns a component per CSI driver depending on the images of its plugin workloads
+func csiDriverComponents(drivers []model.CSIDriver, images []model.Image) ([]cyclonedx.Component, []cyclonedx.Dependency) {
+	imageRefs := make(map[string]string, len(images))
+	for i := range images {
+		imageRefs[images[i].FullName] = images[i].PkgID()
+	}
+
+	components := make([]cyclonedx.Component, 0, len(drivers))
+	dependencies := make([]cyclonedx.Dependency, 0, len(drivers))
+	for i := range drivers {
+		d := drivers[i]
+		properties := []cyclonedx.Property{
+			{
+				Name:  CdxPrefix + K8sComponentType,
+				Value: CSIDriverType,
+			},
+			{
+				Name:  CdxPrefix + K8sComponentName,
+				Value: d.Name,
+			},
+			{
+				Name:  RADPrefix + "k8s:csi:attachRequired",
+				Value: fmt.Sprintf("%t", d.AttachRequired),
+			},
+			{
+				Name:  RADPrefix + "k8s:csi:podInfoOnMount",
+				Value: fmt.Sprintf("%t", d.PodInfoOnMount),
+			},
+		}
+
+		if len(d.LifecycleModes) > 0 {
+			properties = append(properties, cyclonedx.Property{
+				Name:  RADPrefix + "k8s:csi:lifecycleModes",
+				Value: strings.Join(d.LifecycleModes, ","),
+			})
+		}
+
+		refs := make([]string, 0)
+		for _, p := range d.Plugins {
+			properties = append(properties, cyclonedx.Property{
+				Name:  RADPrefix + "k8s:csi:plugin:" + p.Component,
+				Value: p.Namespace + "/" + p.Workload,
+			})
+
+			for _, c := range p.Containers {
+				if ref, ok := imageRefs[c.Image]; ok && !slices.Contains(refs, ref) {
+					refs = append(refs, ref)
+				}
+			}
+		}
+		slices.Sort(refs)
+
+		bomRef := id(d)
+		components = append(components, cyclonedx.Component{
+			BOMRef:     bomRef,
+			Type:       cyclonedx.ComponentTypeApplication,
+			Name:       d.Name,
+			Version:    d.Version,
+			Properties: &properties,
+		})
+		dependencies = append(dependencies, cyclonedx.Dependency{Ref: bomRef, Dependencies: &refs})
+	}
+
+	return components, dependencies
+}
+
+func namespaceSecurityProperties(ns *model.NamespaceSecurity) []cyclonedx.Property {
+	properties := make([]cyclonedx.Property, 0)
+
+	psa := map[string]string{
+		"enforce": ns.PodSecurityAdmission.Enforce,
+		"audit":   ns.PodSecurityAdmission.Audit,
+		"warn":    ns.PodSecurityAdmission.Warn,
+	}
+	for _, mode := range []string{"enforce", "audit", "warn"} {
+		if psa[mode] != "" {
+			properties = append(properties, cyclonedx.Property{
+				Name:  RADPrefix + "k8s:namespace:psa:" + mode,
+				Value: psa[mode],
+			})
+		}
+	}
+
+	counts := []struct {
+		name  string
+		value int
+	}{
+		{name: "workloads", value: ns.Summary.Workloads},
+		{name: "workloads:privileged", value: ns.Summary.Privileged},
+		{name: "workloads:hostNamespaces", value: ns.Summary.HostNamespaces},
+		{name: "workloads:hostPath", value: ns.Summary.HostPath},
+		{name: "workloads:addedCapabilities", value: ns.Summary.AddedCapabilities},
+		{name: "workloads:runAsRoot", value: ns.Summary.RunAsRoot},
+		{name: "workloads:writableRootFilesystem", value: ns.Summary.WritableRootFilesystem},
+		{name: "workloads:seccompUnconfined", value: ns.Summary.SeccompUnconfined},
+	}
+	for _, c := range counts {
+		properties = append(properties, cyclonedx.Property{
+			Name:  RADPrefix + "k8s:namespace:" + c.name,
+			Value: fmt.Sprintf("%d", c.value),
+		})
+	}
+
+	return properties
+}
+
+func transformFindings(findings []model.Finding, emitted map[string]bool, refs map[string]string) []cyclonedx.Vulnerability {
+	vulnerabilities := make([]cyclonedx.Vulnerability, 0, len(findings))
+	for i := range findings {
+		f := findings[i]
+
+		// a finding affects the component of its package, e.g. the container runtime of a node, and the component of its
+		// target only when the package has none, e.g. the kubelet
+		ref := f.Target.Ref
+		if !emitted[ref] {
+			if r, ok := refs[findingKey(f.Target.Type, f.Target.Kind, f.Target.Namespace, f.Target.Name)]; ok {
+				ref = r
+			}
+		}
+
+		vulnerability := cyclonedx.Vulnerability{
+			ID:          f.ID,
+			Source:      &cyclonedx.Source{Name: f.Source},
+			Description: f.Summary,
+			Affects:     &[]cyclonedx.Affects{{Ref: ref}},
+			Properties: &[]cyclonedx.Property{
+				{
+					Name:  RADPrefix + "vuln:package",
+					Value: f.Package,
+				},
+				{
+					Name:  RADPrefix + "vuln:version",
+					Value: f.Version,
+				},
+			},
+		}
+
+		if len(f.Aliases) > 0 {
+			references := make([]cyclonedx.VulnerabilityReference, 0, len(f.Aliases))
+			for _, alias := range f.Aliases {
+				references = append(references, cyclonedx.VulnerabilityReference{ID: alias})
+			}
+			vulnerability.References = &references
+		}
+
+		if f.Severity != "" || f.CVSS != "" {
+			vulnerability.Ratings = &[]cyclonedx.VulnerabilityRating{
+				{
+					Severity: cyclonedx.Severity(f.Severity),
+					Method:   scoringMethod(f.CVSS),
+					Vector:   f.CVSS,
+				},
+			}
+		}
+
+		if len(f.Fixed) > 0 {
+			vulnerability.Recommendation = fmt.Sprintf("Upgrade %s to %s", f.Package, strings.Join(f.Fixed, " or "))
+		}
+
+		if f.Status != "" {
+			vulnerability.Analysis = &cyclonedx.VulnerabilityAnalysis{
+				State:         analysisStates[f.Status],
+				Justification: analysisJustifications[f.Justification],
+				Detail:        f.ImpactStatement,
+			}
+			if f.ActionStatement != "" {
+				vulnerability.Recommendation = f.ActionStatement
+			}
+		}
+
+		vulnerabilities = append(vulnerabilities, vulnerability)
+	}
+
+	return vulnerabilities
+}
+
+// analysisStates maps OpenVEX statuses to CycloneDX impact analysis states
+var analysisStates = map[string]cyclonedx.ImpactAnalysisState{
+	vex.StatusNotAffected:        cyclonedx.IASNotAffected,
+	vex.StatusAffected:           cyclonedx.IASExploitable,
+	vex.StatusFixed:              cyclonedx.IASResolved,
+	vex.StatusUnderInvestigation: cyclonedx.IASInTriage,
+}
+
+// analysisJustifications maps OpenVEX justifications to CycloneDX impact analysis justifications
+var analysisJustifications = map[string]cyclonedx.ImpactAnalysisJustification{
+	"component_not_present":                             cyclonedx.IAJCodeNotPresent,
+	"vulnerable_code_not_present":                       cyclonedx.IAJCodeNotPresent,
+	"vulnerable_code_not_in_execute_path":               cyclonedx.IAJCodeNotReachable,
+	"vulnerable_code_cannot_be_controlled_by_adversary": cyclonedx.IAJRequiresEnvironment,
+	"inline_mitigations_already_exist":                  cyclonedx.IAJProtectedByMitigatingControl,
+}
+
+func scoringMethod(vector string) cyclonedx.ScoringMethod {
+	switch {
+	case strings.HasPrefix(vector, "CVSS:3.1"):
+		return cyclonedx.ScoringMethodCVSSv31
+	case strings.HasPrefix(vector, "CVSS:3.0"):
+		return cyclonedx.ScoringMethodCVSSv3
+	case strings.HasPrefix(vector, "AV:"):
+		return cyclonedx.ScoringMethodCVSSv2
+	case vector != "":
+		return cyclonedx.ScoringMethodOther
+	default:
+		return ""
+	}
+}
+
+func findingKey(targetType, kind, namespace, name string) string {
+	return strings.Join([]string{targetType, kind, namespace, name}, "/")
+}
+
 func id(obj interface{}) string {
 	f, err := hashstructure.Hash(obj, hashstructure.FormatV2, &hashstructure.HashOptions{
 		ZeroNil:      true,
@@ -305,3 +766,206 @@ func id(obj interface{}) string {
 
 	return fmt.Sprintf("%016x", f)
 }
+
+// imagePinningProperties reports how the image is pinned, only for the properties that are set
+func imagePinningProperties(img *model.Image) []cyclonedx.Property {
+	var props []cyclonedx.Property
+	if img.SpecDigest != "" {
+		props = append(props, cyclonedx.Property{Name: RADPrefix + "pkg:specDigest", Value: img.SpecDigest})
+	}
+	for _, digest := range img.RuntimeDigests {
+		props = append(props, cyclonedx.Property{Name: RADPrefix + "pkg:runtimeDigest", Value: digest})
+	}
+	if img.MutableTag {
+		props = append(props, cyclonedx.Property{Name: RADPrefix + "pkg:mutableTag", Value: "true"})
+	}
+	if img.LatestTag {
+		props = append(props, cyclonedx.Property{Name: RADPrefix + "pkg:latestTag", Value: "true"})
+	}
+	if img.DigestDrift {
+		props = append(props, cyclonedx.Property{Name: RADPrefix + "pkg:digestDrift", Value: "true"})
+	}
+
+	return props
+}
+
+// imagePlatformProperties lists the platforms running the image with the digest resolved on each,
+// keeping registry manifest (possibly manifest list) digests apart from platform specific image IDs
+func imagePlatformProperties(platforms []model.ImagePlatform) []cyclonedx.Property {
+	var props []cyclonedx.Property
+	for _, p := range platforms {
+		props = append(props, cyclonedx.Property{Name: RADPrefix + "pkg:platform", Value: p.Platform})
+
+		switch p.DigestKind {
+		case model.DigestKindRepository:
+			props = append(props, cyclonedx.Property{Name: RADPrefix + "pkg:repoDigest", Value: p.Platform + "@" + p.Digest})
+		case model.DigestKindPlatform:
+			props = append(props, cyclonedx.Property{Name: RADPrefix + "pkg:platformDigest", Value: p.Platform + "@" + p.Digest})
+		}
+	}
+
+	return props
+}
+
+// transformFleetToCycloneDXBOM returns a BOM with a component per cluster nesting its CRDs and Helm charts.
+// Images and node OS distributions are shared between clusters, so they are top level components the clusters depend on.
+func transformFleetToCycloneDXBOM(fleet *model.Fleet) *cyclonedx.BOM {
+	cdxBOM := cyclonedx.NewBOM()
+
+	cdxBOM.SerialNumber = uuid.New().URN()
+	cdxBOM.Metadata = &cyclonedx.Metadata{
+		Timestamp: fleet.GeneratedAt.Format(time.RFC3339),
+		Tools: &[]cyclonedx.Tool{
+			{
+				Vendor:  fleet.GeneratedBy.Vendor,
+				Name:    fleet.GeneratedBy.Name,
+				Version: fleet.GeneratedBy.Version,
+			},
+		},
+		Component: &cyclonedx.Component{
+			BOMRef: fleet.ID,
+			Type:   cyclonedx.ComponentTypePlatform,
+			Name:   FleetType,
+			Properties: &[]cyclonedx.Property{
+				{Name: CdxPrefix + K8sComponentType, Value: FleetType},
+				{Name: RADPrefix + "k8s:fleet:clusters", Value: fmt.Sprintf("%d", len(fleet.Clusters))},
+			},
+		},
+	}
+
+	// clusterDependencies maps the cluster names to the refs of their images and OS distributions
+	clusterDependencies := make(map[string][]string, len(fleet.Clusters))
+	components := make([]cyclonedx.Component, 0, len(fleet.Clusters)+len(fleet.Images)+len(fleet.NodeOS))
+	for i := range fleet.NodeOS {
+		nodeOS := fleet.NodeOS[i]
+		software := model.Software{Name: nodeOS.Name, Version: nodeOS.Version}
+		bomRef := software.PkgID()
+		components = append(components, cyclonedx.Component{
+			BOMRef:     bomRef,
+			Type:       cyclonedx.ComponentTypeOS,
+			Name:       nodeOS.Name,
+			Version:    nodeOS.Version,
+			PackageURL: bomRef,
+			Properties: &[]cyclonedx.Property{
+				{Name: CdxPrefix + K8sComponentType, Value: OSType},
+				{Name: CdxPrefix + K8sComponentName, Value: nodeOS.Name},
+				{Name: RADPrefix + "k8s:fleet:nodes", Value: fmt.Sprintf("%d", nodeOS.NodesCount)},
+			},
+		})
+		for _, c := range nodeOS.Clusters {
+			clusterDependencies[c] = append(clusterDependencies[c], bomRef)
+		}
+	}
+
+	for i := range fleet.Images {
+		img := fleet.Images[i]
+		pkg := model.Image{Name: img.Name, Digest: img.Digest}
+		if img.Digest == "" && len(img.Tags) > 0 {
+			pkg.Version = img.Tags[0]
+		}
+		bomRef := pkg.PkgID()
+		properties := []cyclonedx.Property{
+			{Name: CdxPrefix + K8sComponentType, Value: ContainerType},
+			{Name: CdxPrefix + K8sComponentName, Value: img.Name},
+			{Name: RADPrefix + "pkg:type", Value: "oci"},
+			{Name: RADPrefix + "pkg:name", Value: img.Name},
+			{Name: RADPrefix + "pkg:digest", Value: img.Digest},
+		}
+		for _, tag := range img.Tags {
+			properties = append(properties, cyclonedx.Property{Name: RADPrefix + "pkg:version", Value: tag})
+		}
+		components = append(components, cyclonedx.Component{
+			BOMRef:     bomRef,
+			Type:       cyclonedx.ComponentTypeContainer,
+			Name:       img.Name,
+			Version:    img.Digest,
+			PackageURL: bomRef,
+			Properties: &properties,
+		})
+		for _, c := range img.Clusters {
+			clusterDependencies[c] = append(clusterDependencies[c], bomRef)
+		}
+	}
+
+	nested := fleetClusterComponents(fleet)
+	clusterRefs := make([]string, 0, len(fleet.Clusters))
+	dependencies := make([]cyclonedx.Dependency, 0, len(fleet.Clusters)+1)
+	for i := range fleet.Clusters {
+		c := fleet.Clusters[i]
+		bomRef := id(c)
+		clusterRefs = append(clusterRefs, bomRef)
+		properties := []cyclonedx.Property{
+			{Name: CdxPrefix + K8sComponentType, Value: ClusterType},
+			{Name: CdxPrefix + K8sComponentName, Value: c.Name},
+			{Name: RADPrefix + "k8s:cluster:nodes", Value: fmt.Sprintf("%d", c.NodesCount)},
+			{Name: RADPrefix + "k8s:cluster:kbomId", Value: c.KBOMID},
+		}
+		if c.Location != nil && c.Location.Name != "" && c.Location.Name != "unknown" {
+			properties = append(properties, cyclonedx.Property{Name: RADPrefix + "k8s:cluster:location:name", Value: c.Location.Name})
+		}
+		if c.Location != nil && c.Location.Region != "" {
+			properties = append(properties, cyclonedx.Property{Name: RADPrefix + "k8s:cluster:location:region", Value: c.Location.Region})
+		}
+
+		cluster := model.Cluster{K8sVersion: c.K8sVersion}
+		component := cyclonedx.Component{
+			BOMRef:     bomRef,
+			Type:       cyclonedx.ComponentTypePlatform,
+			Name:       cluster.BOMName(),
+			Version:    c.K8sVersion,
+			PackageURL: cluster.BOMRef(),
+			Properties: &properties,
+		}
+		if children := nested[c.Name]; len(children) > 0 {
+			component.Components = &children
+		}
+		components = append(components, component)
+
+		refs := clusterDependencies[c.Name]
+		if refs == nil {
+			refs = []string{}
+		}
+		dependencies = append(dependencies, cyclonedx.Dependency{Ref: bomRef, Dependencies: &refs})
+	}
+	dependencies = append(dependencies, cyclonedx.Dependency{Ref: fleet.ID, Dependencies: &clusterRefs})
+
+	cdxBOM.Components = &components
+	cdxBOM.Dependencies = &dependencies
+
+	return cdxBOM
+}
+
+// fleetClusterComponents returns the CRD and Helm chart components of each cluster by cluster name
+func fleetClusterComponents(fleet *model.Fleet) map[string][]cyclonedx.Component {
+	nested := make(map[string][]cyclonedx.Component, len(fleet.Clusters))
+	for _, crd := range fleet.CRDs {
+		for _, c := range crd.Clusters {
+			nested[c] = append(nested[c], cyclonedx.Component{
+				BOMRef: id(c + "/" + CRDType + "/" + crd.Name),
+				Type:   cyclonedx.ComponentTypeApplication,
+				Name:   crd.Name,
+				Properties: &[]cyclonedx.Property{
+					{Name: CdxPrefix + K8sComponentType, Value: CRDType},
+					{Name: CdxPrefix + K8sComponentName, Value: crd.Name},
+				},
+			})
+		}
+	}
+
+	for _, chart := range fleet.HelmCharts {
+		for _, c := range chart.Clusters {
+			nested[c] = append(nested[c], cyclonedx.Component{
+				BOMRef: id(c + "/" + HelmChartType + "/" + chart.Name),
+				Type:   cyclonedx.ComponentTypeApplication,
+				Name:   chart.Name,
+				Properties: &[]cyclonedx.Property{
+					{Name: CdxPrefix + K8sComponentType, Value: HelmChartType},
+					{Name: CdxPrefix + K8sComponentName, Value: chart.Name},
+					{Name: RADPrefix + "k8s:component:helmChart", Value: chart.Name},
+				},
+			})
+		}
+	}
+
+	return nested
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/rad-security/kbom/internal/model"
+	"github.com/rad-security/kbom/internal/utils"
+	"github.com/rad-security/kbom/internal/vex"
+	"github.com/rad-security/kbom/internal/vuln"
+)
+
+const stdinPath = "-"
+
+var (
+	vulnDBPath string
+	vexPaths   []string
+
+	in io.Reader = os.Stdin
+)
+
+var EnrichCmd = &cobra.Command{
+	Use:   "enrich <kbom.json>",
+	Short: "Enrich KBOM with vulnerabilities from a local OSV database and OpenVEX documents",
+	Long: `Match the Kubernetes, kubelet, container runtime, Helm chart and operator versions
+recorded in a KBOM against a local directory of OSV advisories and apply OpenVEX statements
+to the findings. No network access is required. Use "-" to read the KBOM from stdin.
+With --redact the KBOM is also redacted, e.g. to share an existing KBOM.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEnrich,
+}
+
+func init() {
+	EnrichCmd.Flags().StringVar(&vulnDBPath, "vuln-db", "", "Path to a local directory with OSV advisories")
+	EnrichCmd.Flags().StringSliceVar(&vexPaths, "vex", nil, "Paths to OpenVEX documents to apply to the findings")
+	EnrichCmd.Flags().StringVarP(&output, "output", "o", StdOutput, "Output (stdout, file)")
+	EnrichCmd.Flags().StringVarP(&format, "format", "f", JSONFormat.Name, fmt.Sprintf("Format (%s)", strings.Join(formatNames(), ", ")))
+	EnrichCmd.Flags().StringVarP(&outPath, "out-path", "p", ".", "Path to write KBOM file to. Works only with --output=file")
+	addRedactFlags(EnrichCmd)
+
+	utils.BindFlags(EnrichCmd)
+}
+
+func runEnrich(cmd *cobra.Command, args []string) error {
+	kbom, err := readKBOM(args[0])
+	if err != nil {
+		return err
+	}
+
+	if redactor, err = newRedactor(); err != nil {
+		return err
+	}
+
+	return enrichKBOM(kbom)
+}
+
+func enrichKBOM(kbom *model.KBOM) error {
+	parsedFormat, err := formatFromName(format)
+	if err != nil {
+		return err
+	}
+
+	if vulnDBPath == "" && len(vexPaths) == 0 && redactor == nil {
+		return fmt.Errorf("at least one of --vuln-db, --vex or --redact is required")
+	}
+
+	if err := enrich(kbom); err != nil {
+		return err
+	}
+	redactor.Redact(kbom)
+
+	return writeKBOM(kbom, parsedFormat)
+}
+
+// enrich attaches findings from the vulnerability database and applies VEX statements to them
+func enrich(kbom *model.KBOM) error {
+	if vulnDBPath != "" {
+		db, err := vuln.LoadDB(vulnDBPath)
+		if err != nil {
+			return err
+		}
+
+		log.Debug().Int("count", db.Len()).Str("path", vulnDBPath).Msg("Loaded vulnerability database")
+
+		kbom.Findings = db.Match(vuln.Packages(kbom))
+	}
+
+	if len(vexPaths) > 0 {
+		statements, err := vex.Load(vexPaths)
+		if err != nil {
+			return err
+		}
+
+		log.Debug().Int("count", len(statements)).Msg("Loaded VEX statements")
+
+		vex.Apply(kbom, statements)
+	}
+
+	return nil
+}
+
+func readKBOM(filePath string) (*model.KBOM, error) {
+	r := in
+	if filePath != stdinPath {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	kbom := &model.KBOM{}
+	if err := json.NewDecoder(r).Decode(kbom); err != nil {
+		return nil, fmt.Errorf("failed to read KBOM: %w", err)
+	}
+
+	return kbom, nil
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+const enrichAdvisory = `{
+  "id": "GO-2023-0001",
+  "aliases": ["CVE-2023-0001"],
+  "affected": [{
+    "package": {"ecosystem": "Go", "name": "k8s.io/kubernetes"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.25.3"}]}]
+  }]
+}`
+
+func TestEnrichKBOM(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "GO-2023-0001.json"), []byte(enrichAdvisory), 0o600))
+
+	in = strings.NewReader(`{"id": "00000001", "cluster": {"name": "test-cluster", "k8s_version": "1.25.1"}}`)
+	defer func() { in = os.Stdin }()
+
+	kbom, err := readKBOM(stdinPath)
+	require.NoError(t, err)
+
+	mock := &stdoutMock{buf: bytes.Buffer{}}
+	out = mock
+	output = StdOutput
+	vulnDBPath = dir
+	defer func() { vulnDBPath = "" }()
+
+	format = "wrong"
+	assert.EqualError(t, enrichKBOM(kbom), "format \"wrong\" is not supported")
+
+	format = CycloneDXJsonFormat.Name
+	require.NoError(t, enrichKBOM(kbom))
+	assert.Contains(t, mock.buf.String(), `"ref": "pkg:k8s/k8s.io%2Fkubernetes@1.25.1"`)
+
+	mock.buf.Reset()
+	format = JSONFormat.Name
+	require.NoError(t, enrichKBOM(kbom))
+
+	enriched := &model.KBOM{}
+	require.NoError(t, json.Unmarshal(mock.buf.Bytes(), enriched))
+	require.Len(t, enriched.Findings, 1)
+	assert.Equal(t, "GO-2023-0001", enriched.Findings[0].ID)
+	assert.Equal(t, []string{"CVE-2023-0001"}, enriched.Findings[0].Aliases)
+	assert.Equal(t, model.ClusterTarget, enriched.Findings[0].Target.Type)
+}
+
+func TestEnrichKBOMRedact(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("secret\n"), 0o600))
+
+	in = strings.NewReader(`{"id": "00000001", "cluster": {"name": "test-cluster", "k8s_version": "1.25.1",
+		"nodes": [{"name": "node-1", "machine_id": "abc", "labels": {"kubernetes.io/hostname": "node-1"}}]}}`)
+	defer func() { in = os.Stdin }()
+
+	kbom, err := readKBOM(stdinPath)
+	require.NoError(t, err)
+
+	mock := &stdoutMock{buf: bytes.Buffer{}}
+	out = mock
+	output = StdOutput
+	format = JSONFormat.Name
+	redactNames, redactKeyFile, redactMetadata = []string{"nodes", "cluster"}, keyFile, []string{"*"}
+	defer func() { redactNames, redactKeyFile, redactor = nil, "", nil }()
+
+	redactor, err = newRedactor()
+	require.NoError(t, err)
+	require.NoError(t, enrichKBOM(kbom))
+
+	redacted := &model.KBOM{}
+	require.NoError(t, json.Unmarshal(mock.buf.Bytes(), redacted))
+	assert.Regexp(t, "^redacted-[0-9a-f]{16}$", redacted.Cluster.Name)
+	assert.Regexp(t, "^redacted-[0-9a-f]{16}$", redacted.Cluster.Nodes[0].Name)
+	assert.Equal(t, redacted.Cluster.Nodes[0].Name, redacted.Cluster.Nodes[0].Labels["kubernetes.io/hostname"])
+	assert.Empty(t, redacted.Cluster.Nodes[0].MachineID)
+
+	redactKeyFile = ""
+	_, err = newRedactor()
+	assert.EqualError(t, err, "a key is required to hash the redacted values")
+}
+
+func TestTransformFindingsRefs(t *testing.T) {
+	runtime := model.Software{Name: "containerd", Version: "1.7.2"}
+	kbom := &model.KBOM{Cluster: model.Cluster{Name: "test-cluster", K8sVersion: "1.25.1", Nodes: []model.Node{{
+		Name:        "node-1",
+		Runtime:     &runtime,
+		Capacity:    &model.Capacity{},
+		Allocatable: &model.Capacity{},
+	}}}}
+	kbom.Findings = []model.Finding{
+		{ID: "GHSA-runtime", Target: model.FindingTarget{Type: model.NodeTarget, Name: "node-1", Ref: runtime.PkgID()}},
+		{ID: "GHSA-kubelet", Target: model.FindingTarget{Type: model.NodeTarget, Name: "node-1", Ref: "pkg:generic/kubelet@1.25.1"}},
+	}
+
+	bom := transformToCycloneDXBOM(kbom)
+	require.Len(t, *bom.Vulnerabilities, 2)
+	assert.Equal(t, "pkg:generic/containerd@1.7.2", (*(*bom.Vulnerabilities)[0].Affects)[0].Ref,
+		"the finding affects the runtime component")
+	assert.Equal(t, id(kbom.Cluster.Nodes[0]), (*(*bom.Vulnerabilities)[1].Affects)[0].Ref,
+		"the finding affects the node without a component of its own")
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rad-security/kbom/internal/kube"
+	"github.com/rad-security/kbom/internal/model"
+)
+
+// --fail-on policies: exit non-zero on any collection error, only when a core phase failed, or never
+const (
+	FailOnAny      = "any"
+	FailOnCritical = "critical"
+	FailOnNone     = "none"
+)
+
+var (
+	failOn         string
+	failOnPolicies = []string{FailOnAny, FailOnCritical, FailOnNone}
+)
+
+func validateFailOn(policy string) error {
+	if !slices.Contains(failOnPolicies, policy) {
+		return fmt.Errorf("fail-on policy %q is not supported, use one of: %s", policy, strings.Join(failOnPolicies, ", "))
+	}
+
+	return nil
+}
+
+// resourceErrors returns the collection errors of the resources a phase skipped, sorted by resource
+func resourceErrors(phase string, errs map[string]error) []model.CollectionError {
+	res := make([]model.CollectionError, 0, len(errs))
+	for resource, err := range errs {
+		res = append(res, kube.NewCollectionError(phase, resource, err))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Resource < res[j].Resource
+	})
+
+	return res
+}
+
+func hasCriticalErrors(errs []model.CollectionError) bool {
+	return slices.ContainsFunc(errs, func(e model.CollectionError) bool {
+		return e.Critical
+	})
+}
+
+// collectionFailure returns an error naming the first collection error that fails the run under the policy
+func collectionFailure(errs []model.CollectionError, policy string) error {
+	var failed []model.CollectionError
+	for _, e := range errs {
+		if policy == FailOnAny || (policy == FailOnCritical && e.Critical) {
+			failed = append(failed, e)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	what := failed[0].Phase
+	if failed[0].Resource != "" {
+		what += " " + failed[0].Resource
+	}
+	if len(failed) == 1 {
+		return fmt.Errorf("%s failed: %s", what, failed[0].Message)
+	}
+
+	return fmt.Errorf("%s failed: %s (and %d more collection errors)", what, failed[0].Message, len(failed)-1)
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/rad-security/kbom/internal/kube"
+	"github.com/rad-security/kbom/internal/model"
+)
+
+var (
+	allContexts bool
+	contexts    []string
+	fleetIndex  bool
+	concurrency int
+)
+
+// unsafeFileChars are replaced in context names, which often contain ARNs or slashes, to build file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fleetContexts returns the contexts selected by --all-contexts or --contexts, none when generating a single KBOM
+func fleetContexts() ([]string, error) {
+	if !allContexts && len(contexts) == 0 {
+		return nil, nil
+	}
+	if k8sContext != "" {
+		return nil, fmt.Errorf("--context can not be combined with --contexts or --all-contexts")
+	}
+	if allContexts && len(contexts) > 0 {
+		return nil, fmt.Errorf("--contexts can not be combined with --all-contexts")
+	}
+	if metricsTextfile != "" {
+		return nil, fmt.Errorf("--metrics-textfile can not be combined with --contexts or --all-contexts")
+	}
+	if allContexts {
+		return kube.Contexts()
+	}
+
+	return contexts, nil
+}
+
+// generateFleet generates the KBOM of every context concurrently and writes one file per cluster to --out-path.
+// A failing cluster does not stop the others, the run fails after all clusters are done.
+func generateFleet(names []string, newClient func(string) (kube.K8sClient, error)) error {
+	parsedFormat, err := formatFromName(format)
+	if err != nil {
+		return err
+	}
+
+	enabled, err := enabledCollectors(include, exclude)
+	if err != nil {
+		return err
+	}
+
+	if err := validateFailOn(failOn); err != nil {
+		return err
+	}
+
+	index := model.FleetIndex{GeneratedAt: generatedAt, Clusters: make([]model.FleetCluster, len(names))}
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			index.Clusters[i] = generateCluster(name, newClient, enabled, parsedFormat)
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, c := range index.Clusters {
+		if c.Error != "" {
+			failed++
+			log.Error().Str("context", c.Context).Str("error", c.Error).Msg("Failed to generate KBOM")
+		}
+	}
+
+	if fleetIndex {
+		if err := writeFleetIndex(&index); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to generate KBOM for %d of %d contexts", failed, len(names))
+	}
+
+	return nil
+}
+
+func generateCluster(name string, newClient func(string) (kube.K8sClient, error), enabled []collector, f Format) model.FleetCluster {
+	res := model.FleetCluster{Context: redactor.Context(name)}
+	// errors often quote the context, it is replaced like in the index when redacted
+	errorMessage := func(err error) string {
+		return strings.ReplaceAll(err.Error(), name, res.Context)
+	}
+
+	k8sClient, err := newClient(name)
+	if err != nil {
+		res.Error = errorMessage(err)
+		return res
+	}
+
+	kbom, err := buildKBOM(context.Background(), k8sClient, uuid.New().String(), time.Now(), enabled, failOn)
+	if kbom == nil {
+		res.Error = errorMessage(err)
+		return res
+	}
+	// a KBOM failing the --fail-on policy is still written, with the error recorded in the index
+	if err != nil {
+		res.Error = errorMessage(err)
+	}
+	res.ID = kbom.ID
+	res.Cluster = kbom.Cluster.Name
+	res.K8sVersion = kbom.Cluster.K8sVersion
+
+	fileName := fmt.Sprintf("kbom-%s-%s.%s", contextFileName(res.Context), kbom.GeneratedAt.Format("2006-01-02-15-04-05"),
+		f.FileExtension)
+	if err := writeFile(path.Join(outPath, fileName), kbom, f); err != nil {
+		res.Error = errorMessage(err)
+		return res
+	}
+	res.File = fileName
+
+	return res
+}
+
+// contextFileName returns the context name with the unsafe characters replaced, suffixed with a short hash of the name
+// so contexts differing only in those characters, like a/b and a:b, get different files
+func contextFileName(name string) string {
+	sum := sha256.Sum256([]byte(name))
+
+	return unsafeFileChars.ReplaceAllString(name, "_") + "-" + hex.EncodeToString(sum[:])[:8]
+}
+
+func writeFile(filePath string, kbom *model.KBOM, f Format) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return printKBOM(file, kbom, f)
+}
+
+func writeFleetIndex(index *model.FleetIndex) error {
+	file, err := os.Create(path.Join(outPath, fmt.Sprintf("kbom-fleet-%s.json", index.GeneratedAt.Format("2006-01-02-15-04-05"))))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	enc := json.NewEncoder(file)
+	enc.SetIndent("", "  ")
+	return enc.Encode(index)
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rad-security/kbom/internal/kube"
+	"github.com/rad-security/kbom/internal/model"
+	"github.com/rad-security/kbom/internal/redact"
+)
+
+func TestGenerateFleet(t *testing.T) {
+	outPath = t.TempDir()
+	format = JSONFormat.Name
+	include = nil
+	fleetIndex = true
+	failOn = FailOnCritical
+	generatedAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	defer func() { outPath, fleetIndex = ".", false }()
+
+	newClient := func(name string) (kube.K8sClient, error) {
+		switch name {
+		case "unreachable":
+			return nil, errors.New("connection refused")
+		case "forbidden":
+			return &mockedK8sClient{
+				allImages: func(context.Context) ([]model.Image, error) {
+					return nil, errors.New("pods is forbidden")
+				},
+			}, nil
+		default:
+			return &mockedK8sClient{
+				clusterName: func(context.Context) (string, error) { return name, nil },
+			}, nil
+		}
+	}
+
+	err := generateFleet([]string{"arn:aws:eks:eu-west-1:123456789012:cluster/prod", "unreachable", "forbidden", "staging"}, newClient)
+	assert.EqualError(t, err, "failed to generate KBOM for 2 of 4 contexts")
+
+	files, err := filepath.Glob(filepath.Join(outPath, "kbom-*.json"))
+	require.NoError(t, err)
+	assert.Len(t, files, 4, "one file per collected cluster, also when incomplete, and the index")
+
+	data, err := os.ReadFile(filepath.Join(outPath, "kbom-fleet-2024-01-01-00-00-00.json"))
+	require.NoError(t, err)
+	index := model.FleetIndex{}
+	require.NoError(t, json.Unmarshal(data, &index))
+	require.Len(t, index.Clusters, 4)
+
+	prod := index.Clusters[0]
+	assert.Equal(t, "arn:aws:eks:eu-west-1:123456789012:cluster/prod", prod.Cluster)
+	assert.Regexp(t, `^kbom-arn_aws_eks_eu-west-1_123456789012_cluster_prod-[0-9a-f]{8}-\d{4}(-\d{2}){5}\.json$`, prod.File)
+	assert.FileExists(t, filepath.Join(outPath, prod.File))
+
+	assert.Equal(t, model.FleetCluster{Context: "unreachable", Error: "connection refused"}, index.Clusters[1])
+	forbidden := index.Clusters[2]
+	assert.Equal(t, "AllImages failed: pods is forbidden", forbidden.Error)
+	assert.FileExists(t, filepath.Join(outPath, forbidden.File))
+	assert.Equal(t, "staging", index.Clusters[3].Cluster)
+	assert.Equal(t, "1.25.1", index.Clusters[3].K8sVersion)
+}
+
+func TestGenerateFleetRedact(t *testing.T) {
+	outPath = t.TempDir()
+	format = JSONFormat.Name
+	include = nil
+	fleetIndex = true
+	failOn = FailOnCritical
+	generatedAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	opts, err := redact.NewOptions([]string{redact.Cluster}, nil)
+	require.NoError(t, err)
+	redactor, err = redact.New(opts, []byte("secret"))
+	require.NoError(t, err)
+	defer func() { outPath, fleetIndex, redactor = ".", false, nil }()
+
+	prod := "arn:aws:eks:eu-west-1:123456789012:cluster/prod"
+	newClient := func(name string) (kube.K8sClient, error) {
+		if name == "unreachable" {
+			return nil, fmt.Errorf("context %q is not reachable", name)
+		}
+		return &mockedK8sClient{clusterName: func(context.Context) (string, error) { return name, nil }}, nil
+	}
+
+	err = generateFleet([]string{prod, "unreachable"}, newClient)
+	assert.EqualError(t, err, "failed to generate KBOM for 1 of 2 contexts")
+
+	data, err := os.ReadFile(filepath.Join(outPath, "kbom-fleet-2024-01-01-00-00-00.json"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "123456789012")
+	assert.NotContains(t, string(data), "unreachable")
+
+	index := model.FleetIndex{}
+	require.NoError(t, json.Unmarshal(data, &index))
+	require.Len(t, index.Clusters, 2)
+	assert.Equal(t, redactor.Context(prod), index.Clusters[0].Context)
+	assert.Equal(t, index.Clusters[0].Context, index.Clusters[0].Cluster)
+	assert.True(t, strings.HasPrefix(index.Clusters[0].File, "kbom-"+contextFileName(redactor.Context(prod))+"-"))
+	assert.Equal(t, fmt.Sprintf("context %q is not reachable", redactor.Context("unreachable")), index.Clusters[1].Error)
+}
+
+func TestFleetContexts(t *testing.T) {
+	defer func() { k8sContext, contexts, allContexts = "", nil, false }()
+
+	names, err := fleetContexts()
+	require.NoError(t, err)
+	assert.Nil(t, names)
+
+	contexts = []string{"a", "b"}
+	names, err = fleetContexts()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, names)
+
+	k8sContext = "a"
+	_, err = fleetContexts()
+	assert.Error(t, err)
+
+	k8sContext, allContexts = "", true
+	_, err = fleetContexts()
+	assert.Error(t, err)
+
+	allContexts, metricsTextfile = false, "kbom.prom"
+	defer func() { metricsTextfile = "" }()
+	_, err = fleetContexts()
+	assert.EqualError(t, err, "--metrics-textfile can not be combined with --contexts or --all-contexts")
+}
+
+func TestContextFileName(t *testing.T) {
+	assert.NotEqual(t, contextFileName("a/b"), contextFileName("a:b"))
+	assert.Regexp(t, `^a_b-[0-9a-f]{8}$`, contextFileName("a/b"))
+}
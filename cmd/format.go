@@ -5,26 +5,31 @@ import "fmt"
 type Format struct {
 	Name          string
 	FileExtension string
+	ContentType   string
 }
 
 var JSONFormat = Format{
 	Name:          "json",
 	FileExtension: "json",
+	ContentType:   "application/json",
 }
 
 var YAMLFormat = Format{
 	Name:          "yaml",
 	FileExtension: "yaml",
+	ContentType:   "application/yaml",
 }
 
 var CycloneDXJsonFormat = Format{
 	Name:          "cyclonedx-json",
 	FileExtension: "json",
+	ContentType:   "application/vnd.cyclonedx+json",
 }
 
 var CycloneDXXMLFormat = Format{
 	Name:          "cyclonedx-xml",
 	FileExtension: "xml",
+	ContentType:   "application/vnd.cyclonedx+xml",
 }
 
 func formatNames() []string {
@@ -12,11 +12,13 @@ import (
 
 	"github.com/CycloneDX/cyclonedx-go"
 	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 
 	"github.com/rad-security/kbom/internal/config"
 	"github.com/rad-security/kbom/internal/kube"
+	"github.com/rad-security/kbom/internal/metrics"
 	"github.com/rad-security/kbom/internal/model"
 	"github.com/rad-security/kbom/internal/utils"
 )
@@ -31,13 +33,19 @@ const (
 )
 
 var (
-	short   bool
-	output  string
-	format  string
-	outPath string
+	short         bool
+	output        string
+	format        string
+	outPath       string
+	include       []string
+	exclude       []string
+	nodePoolLabel string
 
 	generatedAt = time.Now()
 	kbomID      = uuid.New().String()
+
+	metricsTextfile   string
+	collectionMetrics = metrics.New()
 )
 
 var GenerateCmd = &cobra.Command{
@@ -50,12 +58,42 @@ func init() {
 	GenerateCmd.Flags().BoolVar(&short, "short", false, "Short - only include metadata, nodes, images and resources counters")
 	GenerateCmd.Flags().StringVarP(&output, "output", "o", StdOutput, "Output (stdout, file)")
 	GenerateCmd.Flags().StringVarP(&format, "format", "f", JSONFormat.Name, fmt.Sprintf("Format (%s)", strings.Join(formatNames(), ", ")))
-	GenerateCmd.Flags().StringVarP(&outPath, "out-path", "p", ".", "Path to write KBOM file to. Works only with --output=file")
+	GenerateCmd.Flags().StringVarP(&outPath, "out-path", "p", ".",
+		"Path to write KBOM files to. Works only with --output=file, --contexts or --all-contexts")
+	GenerateCmd.Flags().StringSliceVar(&include, "include", nil, includeUsage())
+	GenerateCmd.Flags().StringSliceVar(&exclude, "exclude", nil, excludeUsage)
+	GenerateCmd.Flags().StringVar(&nodePoolLabel, "node-pool-label", "",
+		"Fallback node label to group node pools by (with --include nodepools)")
+	GenerateCmd.Flags().StringVar(&vulnDBPath, "vuln-db", "", "Path to a local directory with OSV advisories to match against")
+	GenerateCmd.Flags().StringSliceVar(&vexPaths, "vex", nil, "Paths to OpenVEX documents to apply to the findings")
+	GenerateCmd.Flags().BoolVar(&allContexts, "all-contexts", false, "Generate a KBOM for every context of the kubeconfig")
+	GenerateCmd.Flags().StringSliceVar(&contexts, "contexts", nil, "Generate a KBOM for each of the given kubeconfig contexts")
+	GenerateCmd.Flags().BoolVar(&fleetIndex, "fleet-index", false,
+		"Write an index of the per-cluster KBOMs and failures (with --contexts or --all-contexts)")
+	GenerateCmd.Flags().IntVar(&concurrency, "concurrency", 8, "Number of clusters generated concurrently (with --contexts or --all-contexts)")
+	GenerateCmd.Flags().StringVar(&failOn, "fail-on", FailOnCritical,
+		fmt.Sprintf("Collection errors to exit non-zero on (%s)", strings.Join(failOnPolicies, ", ")))
+	GenerateCmd.Flags().StringVar(&metricsTextfile, "metrics-textfile", "",
+		"Path to write Prometheus metrics to, for the node exporter textfile collector")
+	addRedactFlags(GenerateCmd)
 
 	utils.BindFlags(GenerateCmd)
 }
 
 func runGenerate(cmd *cobra.Command, _ []string) error {
+	var err error
+	if redactor, err = newRedactor(); err != nil {
+		return err
+	}
+
+	names, err := fleetContexts()
+	if err != nil {
+		return err
+	}
+	if len(names) > 0 {
+		return generateFleet(names, kube.NewContextClient)
+	}
+
 	k8sClient, err := kube.NewClient(k8sContext)
 	if err != nil {
 		return err
@@ -70,43 +108,89 @@ func generateKBOM(k8sClient kube.K8sClient) error {
 		return err
 	}
 
-	ctx := context.Background()
-	k8sVersion, caCertDigest, err := k8sClient.Metadata(ctx)
+	enabled, err := enabledCollectors(include, exclude)
 	if err != nil {
 		return err
 	}
 
-	clusterName, err := k8sClient.ClusterName(ctx)
-	if err != nil {
+	if err := validateFailOn(failOn); err != nil {
 		return err
 	}
 
-	full := !short
-	nodes, err := k8sClient.AllNodes(ctx, full)
-	if err != nil {
+	kbom, err := buildKBOM(context.Background(), k8sClient, kbomID, generatedAt, enabled, failOn)
+	// the metrics are written for failed runs too, so collection failures can be alerted on
+	if metricsTextfile != "" {
+		if err := collectionMetrics.WriteTextfile(metricsTextfile); err != nil {
+			log.Error().Err(err).Msg("Failed to write metrics textfile")
+		}
+	}
+	if kbom == nil {
 		return err
 	}
 
-	loc, err := k8sClient.Location(ctx)
-	if err != nil {
+	// a KBOM failing the --fail-on policy is still written, its collection errors tell what is missing
+	if err := writeKBOM(kbom, parsedFormat); err != nil {
 		return err
 	}
 
-	allImages, err := k8sClient.AllImages(ctx)
+	return err
+}
+
+// buildKBOM collects the KBOM of the cluster, including the enabled optional sections and the enrich findings.
+// Failures to collect a part of the cluster are recorded in the KBOM collection errors instead of aborting it, the KBOM is
+// returned together with an error when they fail the run under the failOn policy. Only a failure to reach the cluster
+// for its metadata aborts the collection.
+func buildKBOM(ctx context.Context, k8sClient kube.K8sClient, id string, at time.Time, enabled []collector,
+	failOn string) (*model.KBOM, error) {
+	start := time.Now()
+	k8sVersion, caCertDigest, err := k8sClient.Metadata(ctx)
+	collectionMetrics.ObservePhase(model.PhaseMetadata, start, err)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	resources, err := k8sClient.AllResources(ctx, full)
-	if err != nil {
-		return err
+	var collectionErrors []model.CollectionError
+	// observe records the failure of a phase, failures of the core inventory phases are critical
+	observe := func(phase string, err error, critical bool) {
+		if err == nil {
+			return
+		}
+
+		log.Warn().Err(err).Str("phase", phase).Msg("Failed to collect")
+		collectionError := kube.NewCollectionError(phase, "", err)
+		collectionError.Critical = critical
+		collectionErrors = append(collectionErrors, collectionError)
 	}
 
+	clusterName, err := k8sClient.ClusterName(ctx)
+	observe(model.PhaseClusterName, err, false)
+
+	full := !short
+	start = time.Now()
+	nodes, err := k8sClient.AllNodes(ctx, full)
+	collectionMetrics.ObservePhase(model.PhaseAllNodes, start, err)
+	observe(model.PhaseAllNodes, err, true)
+
+	loc, err := k8sClient.Location(ctx)
+	observe(model.PhaseLocation, err, false)
+
+	start = time.Now()
+	allImages, err := k8sClient.AllImages(ctx)
+	collectionMetrics.ObservePhase(model.PhaseAllImages, start, err)
+	observe(model.PhaseAllImages, err, true)
+	collectionErrors = append(collectionErrors, resourceErrors(model.PhaseAllImages, k8sClient.ImageErrors())...)
+
+	start = time.Now()
+	resources, err := k8sClient.AllResources(ctx, full)
+	collectionMetrics.ObservePhase(model.PhaseAllResources, start, err)
+	observe(model.PhaseAllResources, err, true)
+	collectionErrors = append(collectionErrors, resourceErrors(model.PhaseAllResources, k8sClient.ResourceListErrors())...)
+
 	kbom := model.KBOM{
-		ID:          kbomID,
+		ID:          id,
 		BOMFormat:   BOMFormat,
 		SpecVersion: SpecVersion,
-		GeneratedAt: generatedAt,
+		GeneratedAt: at,
 		GeneratedBy: model.Tool{
 			Vendor:     Company,
 			BuildTime:  config.BuildTime,
@@ -124,49 +208,92 @@ func generateKBOM(k8sClient kube.K8sClient) error {
 			NodesCount:   len(nodes),
 			Nodes:        nodes,
 			Components: model.Components{
-				Images:    allImages,
-				Resources: resources,
+				Images:     allImages,
+				Registries: model.SummarizeRegistries(allImages),
+				Resources:  resources,
 			},
 		},
 	}
 
-	if err := printKBOM(&kbom, parsedFormat); err != nil {
-		return err
+	collectSections(ctx, k8sClient, enabled, &kbom, observe)
+	kbom.CollectionErrors = collectionErrors
+
+	if err := enrich(&kbom); err != nil {
+		return nil, err
 	}
 
-	return nil
+	if !hasCriticalErrors(kbom.CollectionErrors) {
+		collectionMetrics.ObserveKBOM(&kbom, k8sClient.ResourceListErrors())
+	}
+	// the metrics are observed before the redaction, they are not shared with the KBOM
+	redactor.Redact(&kbom)
+
+	return &kbom, collectionFailure(kbom.CollectionErrors, failOn)
 }
 
-func printKBOM(kbom *model.KBOM, f Format) error {
+// collectSections fills the enabled optional sections of the KBOM. The pod templates are listed once, before the first
+// section needing them, and their failure is recorded for each of these sections.
+func collectSections(ctx context.Context, k8sClient kube.K8sClient, enabled []collector, kbom *model.KBOM,
+	observe func(phase string, err error, critical bool)) {
+	var (
+		podTemplates *kube.PodTemplates
+		templatesErr error
+		listed       bool
+	)
+	for _, c := range enabled {
+		start := time.Now()
+		if c.podTemplates && !listed {
+			podTemplates, templatesErr = k8sClient.PodTemplates(ctx)
+			listed = true
+		}
+
+		var err error
+		if c.podTemplates && templatesErr != nil {
+			err = templatesErr
+		} else {
+			err = c.collect(ctx, k8sClient, podTemplates, kbom)
+		}
+		collectionMetrics.ObservePhase(c.name, start, err)
+		observe(c.name, err, false)
+	}
+}
+
+// writeKBOM writes the KBOM to a file or stdout, depending on the --output flag
+func writeKBOM(kbom *model.KBOM, f Format) error {
 	writer, err := getWriter(kbom, f)
 	if err != nil {
 		return err
 	}
 	defer writer.Close()
 
-	switch format {
+	return printKBOM(writer, kbom, f)
+}
+
+// printKBOM encodes the KBOM in the given format to w
+func printKBOM(w io.Writer, kbom *model.KBOM, f Format) error {
+	switch f.Name {
 	case JSONFormat.Name:
-		enc := json.NewEncoder(writer)
+		enc := json.NewEncoder(w)
 		enc.SetIndent("", "  ")
 		return enc.Encode(kbom)
 	case YAMLFormat.Name:
-		enc := yaml.NewEncoder(writer)
+		enc := yaml.NewEncoder(w)
 		enc.SetIndent(2)
 		return enc.Encode(kbom)
 	case CycloneDXJsonFormat.Name:
 		cyclonexKbom := transformToCycloneDXBOM(kbom)
-		enc := cyclonedx.NewBOMEncoder(writer, cyclonedx.BOMFileFormatJSON)
+		enc := cyclonedx.NewBOMEncoder(w, cyclonedx.BOMFileFormatJSON)
 		enc.SetPretty(true)
 		enc.SetEscapeHTML(false)
 		return enc.Encode(cyclonexKbom)
 	case CycloneDXXMLFormat.Name:
 		cyclonexKbom := transformToCycloneDXBOM(kbom)
-		enc := cyclonedx.NewBOMEncoder(writer, cyclonedx.BOMFileFormatXML)
+		enc := cyclonedx.NewBOMEncoder(w, cyclonedx.BOMFileFormatXML)
 		enc.SetPretty(true)
 		enc.SetEscapeHTML(false)
 		return enc.Encode(cyclonexKbom)
 	default:
-		return fmt.Errorf("format %q is not supported", format)
+		return fmt.Errorf("format %q is not supported", f.Name)
 	}
 }
 
@@ -3,14 +3,21 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/rad-security/kbom/internal/kube"
+	"github.com/rad-security/kbom/internal/metrics"
 	"github.com/rad-security/kbom/internal/model"
 )
 
@@ -24,8 +31,10 @@ func TestGenerateKBOM(t *testing.T) {
 		timeMock   string
 
 		// flags
-		output string
-		format string
+		output  string
+		format  string
+		include []string
+		failOn  string
 
 		expectedOut string
 		expectedErr error
@@ -48,7 +57,8 @@ func TestGenerateKBOM(t *testing.T) {
 					return nil, fmt.Errorf("location error")
 				},
 			},
-			expectedErr: fmt.Errorf("location error"),
+			failOn:      FailOnAny,
+			expectedErr: fmt.Errorf("Location failed: location error"),
 		},
 		{
 			name: "all nodes error",
@@ -57,7 +67,7 @@ func TestGenerateKBOM(t *testing.T) {
 					return nil, fmt.Errorf("all nodes error")
 				},
 			},
-			expectedErr: fmt.Errorf("all nodes error"),
+			expectedErr: fmt.Errorf("AllNodes failed: all nodes error"),
 		},
 		{
 			name: "all resources error",
@@ -66,7 +76,7 @@ func TestGenerateKBOM(t *testing.T) {
 					return nil, fmt.Errorf("all resources error")
 				},
 			},
-			expectedErr: fmt.Errorf("all resources error"),
+			expectedErr: fmt.Errorf("AllResources failed: all resources error"),
 		},
 		{
 			name: "all images error",
@@ -75,7 +85,25 @@ func TestGenerateKBOM(t *testing.T) {
 					return nil, fmt.Errorf("all images error")
 				},
 			},
-			expectedErr: fmt.Errorf("all images error"),
+			expectedErr: fmt.Errorf("AllImages failed: all images error"),
+		},
+		{
+			name:       "unknown section",
+			clientMock: &mockedK8sClient{},
+			include:    []string{"unknown"},
+			expectedErr: fmt.Errorf("section \"unknown\" is not supported, use one of: " +
+				"rbac, podsecurity, admission, network, storage, mesh, nodepools, workloads, pullsecrets"),
+		},
+		{
+			name: "rbac error",
+			clientMock: &mockedK8sClient{
+				rbac: func(context.Context) (*model.RBAC, error) {
+					return nil, fmt.Errorf("rbac error")
+				},
+			},
+			include:     []string{"rbac"},
+			failOn:      FailOnAny,
+			expectedErr: fmt.Errorf("rbac failed: rbac error"),
 		},
 		{
 			name:        "print KBOM - stdout - wrong format",
@@ -188,16 +216,24 @@ func TestGenerateKBOM(t *testing.T) {
 				allImages: func(context.Context) ([]model.Image, error) {
 					return []model.Image{
 						{
-							Name:     "nginx",
-							Version:  "1.17.1",
-							FullName: "nginx:1.17.1",
-							Digest:   "sha256:0000000000000000000000000000000000000000000000000000000000000001",
+							Name:       "nginx",
+							Version:    "1.17.1",
+							FullName:   "nginx:1.17.1",
+							Digest:     "sha256:0000000000000000000000000000000000000000000000000000000000000001",
+							Registry:   "docker.io",
+							Repository: "library/nginx",
+							Tag:        "1.17.1",
+							Normalized: "docker.io/library/nginx:1.17.1",
 						},
 						{
-							Name:     "redis",
-							Version:  "7.0.1",
-							FullName: "redis:7.0.1",
-							Digest:   "sha256:0000000000000000000000000000000000000000000000000000000000000002",
+							Name:       "redis",
+							Version:    "7.0.1",
+							FullName:   "redis:7.0.1",
+							Digest:     "sha256:0000000000000000000000000000000000000000000000000000000000000002",
+							Registry:   "docker.io",
+							Repository: "library/redis",
+							Tag:        "7.0.1",
+							Normalized: "docker.io/library/redis:7.0.1",
 						},
 					}, nil
 				},
@@ -269,12 +305,19 @@ func TestGenerateKBOM(t *testing.T) {
 				output = StdOutput
 			}
 
+			include = tc.include
+			failOn = tc.failOn
+			if failOn == "" {
+				failOn = FailOnCritical
+			}
+
 			err := generateKBOM(tc.clientMock)
 			if tc.expectedErr != nil {
+				// the KBOM of a failed collection is still written, TestGenerateKBOMCollectionErrors covers it
 				assert.EqualError(t, err, tc.expectedErr.Error())
-			} else {
-				assert.NoError(t, err)
+				return
 			}
+			assert.NoError(t, err)
 
 			if output == FileOutput {
 				filename := fmt.Sprintf("kbom-%s-2023-04-26-10-00-00.%s", mockCACert[:8], format)
@@ -296,6 +339,104 @@ func TestGenerateKBOM(t *testing.T) {
 	}
 }
 
+func TestGenerateKBOMMetricsTextfile(t *testing.T) {
+	out = &stdoutMock{buf: bytes.Buffer{}}
+	format = JSONFormat.Name
+	output = StdOutput
+	include = nil
+	metricsTextfile = filepath.Join(t.TempDir(), "kbom.prom")
+	collectionMetrics = metrics.New()
+	defer func() { metricsTextfile = "" }()
+
+	err := generateKBOM(&mockedK8sClient{
+		allImages: func(context.Context) ([]model.Image, error) {
+			return nil, fmt.Errorf("invalid reference format")
+		},
+	})
+	assert.EqualError(t, err, "AllImages failed: invalid reference format")
+
+	data, err := os.ReadFile(metricsTextfile)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `kbom_collection_errors_total{phase="AllImages"} 1`)
+	assert.Contains(t, string(data), `kbom_collection_duration_seconds{phase="Metadata"}`)
+}
+
+func TestGenerateKBOMCollectionErrors(t *testing.T) {
+	output = StdOutput
+	format = JSONFormat.Name
+	include = []string{"rbac"}
+	defer func() { include, failOn = nil, FailOnCritical }()
+
+	client := &mockedK8sClient{
+		listErrors: map[string]error{
+			"v1, Resource=secrets": apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", errors.New("denied")),
+		},
+		imageErrors: map[string]error{"default/web/app": fmt.Errorf("%w %q", kube.ErrInvalidImage, "Invalid:Ref")},
+		rbac: func(context.Context) (*model.RBAC, error) {
+			return nil, fmt.Errorf("rbac error")
+		},
+	}
+
+	failOn = "wrong"
+	assert.EqualError(t, generateKBOM(client), `fail-on policy "wrong" is not supported, use one of: any, critical, none`)
+
+	mock := &stdoutMock{buf: bytes.Buffer{}}
+	out = mock
+	failOn = FailOnCritical
+	require.NoError(t, generateKBOM(client))
+
+	kbom := &model.KBOM{}
+	require.NoError(t, json.Unmarshal(mock.buf.Bytes(), kbom))
+	assert.Equal(t, []model.CollectionError{
+		{Phase: model.PhaseAllImages, Resource: "default/web/app", Class: model.ErrorClassInvalid,
+			Message: `invalid image reference "Invalid:Ref"`},
+		{Phase: model.PhaseAllResources, Resource: "v1, Resource=secrets", Class: model.ErrorClassForbidden,
+			Message: `secrets is forbidden: denied`},
+		{Phase: "rbac", Class: model.ErrorClassUnknown, Message: "rbac error"},
+	}, kbom.CollectionErrors)
+
+	mock.buf.Reset()
+	failOn = FailOnAny
+	assert.EqualError(t, generateKBOM(client),
+		`AllImages default/web/app failed: invalid image reference "Invalid:Ref" (and 2 more collection errors)`)
+	assert.NotEmpty(t, mock.buf.String(), "the KBOM is written also when the run fails")
+
+	client.allNodes = func(context.Context, bool) ([]model.Node, error) {
+		return nil, fmt.Errorf("all nodes error")
+	}
+	mock.buf.Reset()
+	failOn = FailOnNone
+	require.NoError(t, generateKBOM(client))
+
+	kbom = &model.KBOM{}
+	require.NoError(t, json.Unmarshal(mock.buf.Bytes(), kbom))
+	assert.Equal(t, model.CollectionError{Phase: model.PhaseAllNodes, Class: model.ErrorClassUnknown, Critical: true,
+		Message: "all nodes error"}, kbom.CollectionErrors[0])
+}
+
+func TestBuildKBOMPodTemplates(t *testing.T) {
+	enabled, err := enabledCollectors([]string{allSections}, nil)
+	require.NoError(t, err)
+
+	calls := 0
+	client := &mockedK8sClient{
+		podTemplates: func(context.Context) (*kube.PodTemplates, error) {
+			calls++
+			return nil, errors.New("pods is forbidden")
+		},
+	}
+
+	kbom, err := buildKBOM(context.Background(), client, "00000001", time.Now(), enabled, FailOnNone)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls, "the pod templates are listed once for all sections")
+
+	var phases []string
+	for _, e := range kbom.CollectionErrors {
+		phases = append(phases, e.Phase)
+	}
+	assert.Equal(t, []string{"podsecurity", "admission", "network", "storage", "mesh", "workloads", "pullsecrets"}, phases)
+}
+
 type mockedK8sClient struct {
 	clusterName  func(context.Context) (string, error)
 	metadata     func(context.Context) (string, string, error)
@@ -303,6 +444,18 @@ type mockedK8sClient struct {
 	allImages    func(context.Context) ([]model.Image, error)
 	allNodes     func(context.Context, bool) ([]model.Node, error)
 	allResources func(context.Context, bool) (map[string]model.ResourceList, error)
+	listErrors   map[string]error
+	imageErrors  map[string]error
+	rbac         func(context.Context) (*model.RBAC, error)
+	podTemplates func(context.Context) (*kube.PodTemplates, error)
+	podSecurity  func(context.Context) (*model.PodSecurity, error)
+	admission    func(context.Context) (*model.Admission, error)
+	network      func(context.Context) (*model.Network, error)
+	storage      func(context.Context) (*model.Storage, error)
+	serviceMesh  func(context.Context) (*model.ServiceMesh, error)
+	nodePools    func(context.Context, string) ([]model.NodePool, error)
+	workloads    func(context.Context) (*model.Workloads, error)
+	pullSecrets  func(context.Context) (*model.PullSecrets, error)
 }
 
 func (m *mockedK8sClient) ClusterName(ctx context.Context) (clusterName string, err error) {
@@ -347,6 +500,84 @@ func (m *mockedK8sClient) AllResources(ctx context.Context, full bool) (map[stri
 	return m.allResources(ctx, full)
 }
 
+func (m *mockedK8sClient) ResourceListErrors() map[string]error {
+	return m.listErrors
+}
+
+func (m *mockedK8sClient) ImageErrors() map[string]error {
+	return m.imageErrors
+}
+
+func (m *mockedK8sClient) RBAC(ctx context.Context) (*model.RBAC, error) {
+	if m.rbac == nil {
+		return nil, nil
+	}
+	return m.rbac(ctx)
+}
+
+func (m *mockedK8sClient) PodTemplates(ctx context.Context) (*kube.PodTemplates, error) {
+	if m.podTemplates == nil {
+		return &kube.PodTemplates{}, nil
+	}
+	return m.podTemplates(ctx)
+}
+
+func (m *mockedK8sClient) PodSecurity(ctx context.Context, _ *kube.PodTemplates) (*model.PodSecurity, error) {
+	if m.podSecurity == nil {
+		return nil, nil
+	}
+	return m.podSecurity(ctx)
+}
+
+func (m *mockedK8sClient) Admission(ctx context.Context, _ *kube.PodTemplates) (*model.Admission, error) {
+	if m.admission == nil {
+		return nil, nil
+	}
+	return m.admission(ctx)
+}
+
+func (m *mockedK8sClient) Network(ctx context.Context, _ *kube.PodTemplates) (*model.Network, error) {
+	if m.network == nil {
+		return nil, nil
+	}
+	return m.network(ctx)
+}
+
+func (m *mockedK8sClient) Storage(ctx context.Context, _ *kube.PodTemplates) (*model.Storage, error) {
+	if m.storage == nil {
+		return nil, nil
+	}
+	return m.storage(ctx)
+}
+
+func (m *mockedK8sClient) ServiceMesh(ctx context.Context, _ *kube.PodTemplates) (*model.ServiceMesh, error) {
+	if m.serviceMesh == nil {
+		return nil, nil
+	}
+	return m.serviceMesh(ctx)
+}
+
+func (m *mockedK8sClient) NodePools(ctx context.Context, fallbackLabel string) ([]model.NodePool, error) {
+	if m.nodePools == nil {
+		return nil, nil
+	}
+	return m.nodePools(ctx, fallbackLabel)
+}
+
+func (m *mockedK8sClient) Workloads(ctx context.Context, _ *kube.PodTemplates) (*model.Workloads, error) {
+	if m.workloads == nil {
+		return nil, nil
+	}
+	return m.workloads(ctx)
+}
+
+func (m *mockedK8sClient) PullSecrets(ctx context.Context, _ *kube.PodTemplates) (*model.PullSecrets, error) {
+	if m.pullSecrets == nil {
+		return nil, nil
+	}
+	return m.pullSecrets(ctx)
+}
+
 var mockCACert = "1234567890"
 
 var expectedOutJSON = `{
@@ -452,13 +683,31 @@ var expectedOutJSON = `{
           "full_name": "nginx:1.17.1",
           "name": "nginx",
           "version": "1.17.1",
-          "digest": "sha256:0000000000000000000000000000000000000000000000000000000000000001"
+          "digest": "sha256:0000000000000000000000000000000000000000000000000000000000000001",
+          "registry": "docker.io",
+          "repository": "library/nginx",
+          "tag": "1.17.1",
+          "normalized": "docker.io/library/nginx:1.17.1"
         },
         {
           "full_name": "redis:7.0.1",
           "name": "redis",
           "version": "7.0.1",
-          "digest": "sha256:0000000000000000000000000000000000000000000000000000000000000002"
+          "digest": "sha256:0000000000000000000000000000000000000000000000000000000000000002",
+          "registry": "docker.io",
+          "repository": "library/redis",
+          "tag": "7.0.1",
+          "normalized": "docker.io/library/redis:7.0.1"
+        }
+      ],
+      "registries": [
+        {
+          "registry": "docker.io",
+          "images": 2,
+          "repositories": [
+            "library/nginx",
+            "library/redis"
+          ]
         }
       ],
       "resources": {
@@ -0,0 +1,453 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	"github.com/rad-security/kbom/internal/config"
+	"github.com/rad-security/kbom/internal/kube"
+	"github.com/rad-security/kbom/internal/operator"
+	"github.com/rad-security/kbom/internal/utils"
+)
+
+const (
+	OperatorMode = "operator"
+	ServeMode    = "serve"
+	CronJobMode  = "cronjob"
+
+	servePort     = 8080
+	tokenKey      = "token"
+	tokenDir      = "/etc/kbom/token"
+	nobodyUserID  = 65534
+	nameLabel     = "app.kubernetes.io/name"
+	manifestSplit = "---\n"
+
+	chartNamespace = "{{ .Release.Namespace }}"
+	chartImage     = "{{ .Values.image }}"
+	chartToken     = "{{ .Values.token }}"
+)
+
+var installModes = []string{OperatorMode, ServeMode, CronJobMode}
+
+// installConfig are the settings of the rendered manifests
+type installConfig struct {
+	Mode      string
+	Name      string
+	Namespace string
+	Image     string
+	Schedule  string
+	Storage   string
+	// ListAllResources grants list on every resource, without it only the resources of the core and the sections
+	ListAllResources bool
+	// RequireToken renders a Secret with the bearer token the serve mode requires, Token is generated when empty
+	RequireToken bool
+	Token        string
+}
+
+var (
+	installCfg installConfig
+	chartDir   string
+)
+
+var InstallManifestsCmd = &cobra.Command{
+	Use:   "install-manifests",
+	Short: "Render the manifests to run kbom in the cluster",
+	Long: `Render the ServiceAccount, ClusterRole, ClusterRoleBinding and the Deployment of the operator or serve mode,
+or a CronJob running generate. The serve mode requires a bearer token generated into a Secret, unless
+--require-token=false. The ClusterRole grants the permissions of the core and of the sections selected with
+--include and --exclude, the same registry generate collects them with. With --list-all-resources=false the resources
+inventory is limited to the resources of the core and the sections, listing the others fails with forbidden collection
+errors. The operator mode also renders its CRDs and a Role for its namespace. With --chart-dir the manifests are written
+as a Helm chart instead.`,
+	RunE: runInstallManifests,
+}
+
+func init() {
+	InstallManifestsCmd.Flags().StringVar(&installCfg.Mode, "mode", OperatorMode,
+		fmt.Sprintf("Mode to run kbom in (%s)", strings.Join(installModes, ", ")))
+	InstallManifestsCmd.Flags().StringVar(&installCfg.Name, "name", "kbom", "Name of the resources")
+	InstallManifestsCmd.Flags().StringVar(&installCfg.Namespace, "namespace", "kbom", "Namespace to install to")
+	InstallManifestsCmd.Flags().StringVar(&installCfg.Image, "image", "", "kbom container image")
+	InstallManifestsCmd.Flags().StringVar(&installCfg.Schedule, "schedule", "0 */6 * * *", "Schedule of the CronJob (with --mode cronjob)")
+	InstallManifestsCmd.Flags().StringVar(&installCfg.Storage, "storage", operator.CRDStorage,
+		fmt.Sprintf("Report storage of the operator (%s, %s)", operator.CRDStorage, operator.ConfigMapStorage))
+	InstallManifestsCmd.Flags().BoolVar(&installCfg.ListAllResources, "list-all-resources", true,
+		"Grant list on every resource for the resources inventory")
+	InstallManifestsCmd.Flags().BoolVar(&installCfg.RequireToken, "require-token", true,
+		"Require a bearer token generated into a Secret for the KBOM endpoints (with --mode serve)")
+	InstallManifestsCmd.Flags().StringSliceVar(&include, "include", nil, includeUsage())
+	InstallManifestsCmd.Flags().StringSliceVar(&exclude, "exclude", nil, excludeUsage)
+	InstallManifestsCmd.Flags().StringVar(&chartDir, "chart-dir", "", "Directory to write a Helm chart to instead of printing the manifests")
+	_ = InstallManifestsCmd.MarkFlagRequired("image")
+
+	utils.BindFlags(InstallManifestsCmd)
+}
+
+func runInstallManifests(_ *cobra.Command, _ []string) error {
+	enabled, err := enabledCollectors(include, exclude)
+	if err != nil {
+		return err
+	}
+
+	if chartDir != "" {
+		return writeChart(chartDir, installCfg, enabled)
+	}
+
+	manifests, err := renderManifests(installCfg, enabled)
+	if err != nil {
+		return err
+	}
+
+	files, crds, err := installCRDs(installCfg)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if _, err := out.Write(append([]byte(manifestSplit), crds[file]...)); err != nil {
+			return err
+		}
+	}
+	_, err = out.Write(manifests)
+
+	return err
+}
+
+// installPermissions returns the permissions of the core and the enabled sections, and with listAll the list permission of
+// every resource, each permission once
+func installPermissions(enabled []collector, listAll bool) []kube.Permission {
+	all := append([]kube.Permission{}, kube.CorePermissions...)
+	for i := range enabled {
+		all = append(all, enabled[i].permissions()...)
+	}
+	if listAll {
+		all = append(all, kube.AllResourcesPermission)
+	}
+
+	return uniquePermissions(all)
+}
+
+// installObjects returns the objects to run kbom in the given mode with the permissions of the enabled sections
+func installObjects(cfg installConfig, enabled []collector) ([]runtime.Object, error) {
+	if cfg.Image == "" {
+		return nil, errors.New("image is required")
+	}
+
+	meta := metav1.ObjectMeta{Name: cfg.Name, Namespace: cfg.Namespace, Labels: map[string]string{nameLabel: cfg.Name}}
+	clusterMeta := metav1.ObjectMeta{Name: cfg.Name, Labels: meta.Labels}
+	role := kube.ClusterRole(cfg.Name, installPermissions(enabled, cfg.ListAllResources))
+	role.Labels = meta.Labels
+	objects := []runtime.Object{
+		&corev1.ServiceAccount{TypeMeta: typeMeta(corev1.SchemeGroupVersion.String(), "ServiceAccount"), ObjectMeta: meta},
+		role,
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta:   typeMeta(rbacv1.SchemeGroupVersion.String(), "ClusterRoleBinding"),
+			ObjectMeta: clusterMeta,
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: cfg.Name},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: cfg.Name, Namespace: cfg.Namespace}},
+		},
+	}
+
+	var (
+		modeObjects []runtime.Object
+		err         error
+	)
+	switch cfg.Mode {
+	case OperatorMode:
+		modeObjects, err = operatorObjects(cfg, meta, sectionArgs(enabled))
+	case ServeMode:
+		modeObjects, err = serveObjects(cfg, meta, sectionArgs(enabled))
+	case CronJobMode:
+		modeObjects = cronJobObjects(cfg, meta, sectionArgs(enabled))
+	default:
+		err = fmt.Errorf("mode %q is not supported, use one of: %s", cfg.Mode, strings.Join(installModes, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return append(objects, modeObjects...), nil
+}
+
+// operatorObjects returns the Role of the operator in its namespace, its binding and the operator Deployment
+func operatorObjects(cfg installConfig, meta metav1.ObjectMeta, args []string) ([]runtime.Object, error) {
+	if cfg.Storage != operator.CRDStorage && cfg.Storage != operator.ConfigMapStorage {
+		return nil, fmt.Errorf("storage %q is not supported, use one of: %s, %s", cfg.Storage, operator.CRDStorage,
+			operator.ConfigMapStorage)
+	}
+
+	// the lease name is the default of the operator command, which the rendered arguments do not change
+	role := kube.Role(cfg.Name, cfg.Namespace,
+		operator.Permissions(operator.Config{Storage: cfg.Storage, LeaderElect: true, LeaseName: "kbom-operator"}))
+	role.Labels = meta.Labels
+
+	return []runtime.Object{
+		role,
+		&rbacv1.RoleBinding{
+			TypeMeta:   typeMeta(rbacv1.SchemeGroupVersion.String(), "RoleBinding"),
+			ObjectMeta: meta,
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: cfg.Name},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: cfg.Name, Namespace: cfg.Namespace}},
+		},
+		deployment(meta, podSpec(cfg, append([]string{OperatorMode, "--storage=" + cfg.Storage}, args...))),
+	}, nil
+}
+
+// serveObjects returns the Deployment serving the KBOM and its Service, with RequireToken also the Secret with the
+// bearer token the KBOM endpoints require
+func serveObjects(cfg installConfig, meta metav1.ObjectMeta, args []string) ([]runtime.Object, error) {
+	var objects []runtime.Object
+	if cfg.RequireToken {
+		token := cfg.Token
+		if token == "" {
+			var err error
+			if token, err = newToken(); err != nil {
+				return nil, err
+			}
+		}
+
+		secretMeta := meta
+		secretMeta.Name = cfg.Name + "-token"
+		objects = append(objects, &corev1.Secret{
+			TypeMeta:   typeMeta(corev1.SchemeGroupVersion.String(), "Secret"),
+			ObjectMeta: secretMeta,
+			Type:       corev1.SecretTypeOpaque,
+			StringData: map[string]string{tokenKey: token},
+		})
+		args = append(args, "--token-file="+path.Join(tokenDir, tokenKey))
+	}
+
+	spec := podSpec(cfg, append([]string{ServeMode, fmt.Sprintf("--address=:%d", servePort)}, args...))
+	container := &spec.Containers[0]
+	container.Ports = []corev1.ContainerPort{{Name: "http", ContainerPort: servePort}}
+	container.LivenessProbe = httpProbe("/healthz")
+	container.ReadinessProbe = httpProbe("/readyz")
+	if cfg.RequireToken {
+		spec.Volumes = []corev1.Volume{{Name: tokenKey, VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: cfg.Name + "-token"},
+		}}}
+		container.VolumeMounts = []corev1.VolumeMount{{Name: tokenKey, MountPath: tokenDir, ReadOnly: true}}
+	}
+
+	return append(objects,
+		deployment(meta, spec),
+		&corev1.Service{
+			TypeMeta:   typeMeta(corev1.SchemeGroupVersion.String(), "Service"),
+			ObjectMeta: meta,
+			Spec: corev1.ServiceSpec{
+				Selector: meta.Labels,
+				Ports:    []corev1.ServicePort{{Name: "http", Port: servePort, TargetPort: intstr.FromString("http")}},
+			},
+		},
+	), nil
+}
+
+// newToken returns a random bearer token
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// cronJobObjects returns the CronJob printing a KBOM to its log on the schedule
+func cronJobObjects(cfg installConfig, meta metav1.ObjectMeta, args []string) []runtime.Object {
+	spec := podSpec(cfg, append([]string{"generate"}, args...))
+	spec.RestartPolicy = corev1.RestartPolicyOnFailure
+
+	return []runtime.Object{
+		&batchv1.CronJob{
+			TypeMeta:   typeMeta(batchv1.SchemeGroupVersion.String(), "CronJob"),
+			ObjectMeta: meta,
+			Spec: batchv1.CronJobSpec{
+				Schedule:          cfg.Schedule,
+				ConcurrencyPolicy: batchv1.ForbidConcurrent,
+				JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: meta.Labels},
+					Spec:       spec,
+				}}},
+			},
+		},
+	}
+}
+
+// renderManifests renders the objects of installObjects as a multi-document YAML
+func renderManifests(cfg installConfig, enabled []collector) ([]byte, error) {
+	objects, err := installObjects(cfg, enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, obj := range objects {
+		manifest, err := manifestYAML(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.WriteString(manifestSplit)
+		buf.Write(manifest)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// installCRDs returns the CRDs the operator stores its reports in and reads its requests from, by their file path
+func installCRDs(cfg installConfig) ([]string, map[string][]byte, error) {
+	if cfg.Mode != OperatorMode || cfg.Storage != operator.CRDStorage {
+		return nil, nil, nil
+	}
+
+	files, err := fs.Glob(operator.CRDs, "crds/*.yaml")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	crds := make(map[string][]byte, len(files))
+	for _, file := range files {
+		if crds[file], err = operator.CRDs.ReadFile(file); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return files, crds, nil
+}
+
+// writeChart writes the manifests as a Helm chart installing to the release namespace, with the image as a value
+func writeChart(dir string, cfg installConfig, enabled []collector) error {
+	image := cfg.Image
+	if image == "" {
+		return errors.New("image is required")
+	}
+
+	values := fmt.Sprintf("image: %s\n", image)
+	if cfg.Mode == ServeMode && cfg.RequireToken {
+		token := cfg.Token
+		if token == "" {
+			var err error
+			if token, err = newToken(); err != nil {
+				return err
+			}
+		}
+		values += fmt.Sprintf("token: %q\n", token)
+		cfg.Token = chartToken
+	}
+
+	cfg.Namespace, cfg.Image = chartNamespace, chartImage
+	manifests, err := renderManifests(cfg, enabled)
+	if err != nil {
+		return err
+	}
+
+	_, files, err := installCRDs(cfg)
+	if err != nil {
+		return err
+	}
+
+	chart := fmt.Sprintf("apiVersion: v2\nname: %s\ndescription: Kubernetes Bill of Materials\ntype: application\n"+
+		"version: %s\nappVersion: %q\n", cfg.Name, chartVersion(), config.AppVersion)
+	if files == nil {
+		files = make(map[string][]byte)
+	}
+	files["Chart.yaml"] = []byte(chart)
+	files["values.yaml"] = []byte(values)
+	files["templates/kbom.yaml"] = manifests
+
+	for name, content := range files {
+		filePath := path.Join(dir, name)
+		if err := os.MkdirAll(path.Dir(filePath), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filePath, content, 0o600); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// chartVersion returns the version of kbom as a chart version, which has to be a semantic version
+func chartVersion() string {
+	version, err := semver.NewVersion(config.AppVersion)
+	if err != nil {
+		return "0.0.0"
+	}
+
+	return version.String()
+}
+
+// sectionArgs returns the --include argument selecting the enabled sections
+func sectionArgs(enabled []collector) []string {
+	if len(enabled) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(enabled))
+	for _, c := range enabled {
+		names = append(names, c.name)
+	}
+
+	return []string{"--include=" + strings.Join(names, ",")}
+}
+
+func deployment(meta metav1.ObjectMeta, spec corev1.PodSpec) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta:   typeMeta(appsv1.SchemeGroupVersion.String(), "Deployment"),
+		ObjectMeta: meta,
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(int32(1)),
+			Selector: &metav1.LabelSelector{MatchLabels: meta.Labels},
+			Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: meta.Labels}, Spec: spec},
+		},
+	}
+}
+
+// podSpec returns the spec of a pod running kbom with the args as a non-root user with a read-only root filesystem
+func podSpec(cfg installConfig, args []string) corev1.PodSpec {
+	return corev1.PodSpec{
+		ServiceAccountName: cfg.Name,
+		SecurityContext: &corev1.PodSecurityContext{
+			RunAsNonRoot:   ptr.To(true),
+			RunAsUser:      ptr.To(int64(nobodyUserID)),
+			SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+		},
+		Containers: []corev1.Container{{
+			Name:  "kbom",
+			Image: cfg.Image,
+			Args:  args,
+			SecurityContext: &corev1.SecurityContext{
+				AllowPrivilegeEscalation: ptr.To(false),
+				ReadOnlyRootFilesystem:   ptr.To(true),
+				Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+			},
+		}},
+	}
+}
+
+func httpProbe(probePath string) *corev1.Probe {
+	return &corev1.Probe{ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: probePath, Port: intstr.FromString("http")}}}
+}
+
+func typeMeta(apiVersion, kind string) metav1.TypeMeta {
+	return metav1.TypeMeta{APIVersion: apiVersion, Kind: kind}
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestInstallObjects(t *testing.T) {
+	enabled, err := enabledCollectors([]string{"all"}, []string{"podsecurity", "admission", "network", "storage", "mesh",
+		"nodepools", "workloads", "pullsecrets"})
+	require.NoError(t, err)
+
+	cfg := installConfig{Mode: ServeMode, Name: "kbom", Namespace: "kbom-system", Image: "kbom:test"}
+	objects, err := installObjects(cfg, enabled)
+	require.NoError(t, err)
+	require.Len(t, objects, 5)
+
+	assert.Equal(t, "kbom-system", objects[0].(*corev1.ServiceAccount).Namespace)
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}, ResourceNames: []string{"kube-root-ca.crt"}},
+		{APIGroups: []string{""}, Resources: []string{"namespaces", "nodes", "pods"}, Verbs: []string{"list"}},
+		{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"clusterrolebindings", "clusterroles", "rolebindings", "roles"},
+			Verbs: []string{"list"}},
+	}, objects[1].(*rbacv1.ClusterRole).Rules)
+	assert.Equal(t, []rbacv1.Subject{{Kind: "ServiceAccount", Name: "kbom", Namespace: "kbom-system"}},
+		objects[2].(*rbacv1.ClusterRoleBinding).Subjects)
+
+	container := objects[3].(*appsv1.Deployment).Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "kbom:test", container.Image)
+	assert.Equal(t, []string{"serve", "--address=:8080", "--include=rbac"}, container.Args)
+	assert.Equal(t, "/readyz", container.ReadinessProbe.HTTPGet.Path)
+	assert.Equal(t, "kbom", objects[4].(*corev1.Service).Spec.Selector[nameLabel])
+}
+
+func TestInstallObjectsServeToken(t *testing.T) {
+	cfg := installConfig{Mode: ServeMode, Name: "kbom", Namespace: "kbom", Image: "kbom:test", RequireToken: true, Token: "s3cret"}
+	objects, err := installObjects(cfg, nil)
+	require.NoError(t, err)
+	require.Len(t, objects, 6)
+
+	secret := objects[3].(*corev1.Secret)
+	assert.Equal(t, "kbom-token", secret.Name)
+	assert.Equal(t, map[string]string{"token": "s3cret"}, secret.StringData)
+
+	spec := objects[4].(*appsv1.Deployment).Spec.Template.Spec
+	assert.Equal(t, "kbom-token", spec.Volumes[0].Secret.SecretName)
+	assert.Equal(t, []corev1.VolumeMount{{Name: "token", MountPath: "/etc/kbom/token", ReadOnly: true}}, spec.Containers[0].VolumeMounts)
+	assert.Equal(t, []string{"serve", "--address=:8080", "--token-file=/etc/kbom/token/token"}, spec.Containers[0].Args)
+
+	cfg.Token = ""
+	objects, err = installObjects(cfg, nil)
+	require.NoError(t, err)
+	assert.Len(t, objects[3].(*corev1.Secret).StringData["token"], 64, "a token is generated when none is given")
+}
+
+func TestInstallObjectsListAllResources(t *testing.T) {
+	enabled, err := enabledCollectors([]string{"workloads"}, nil)
+	require.NoError(t, err)
+
+	cfg := installConfig{Mode: CronJobMode, Name: "kbom", Namespace: "kbom", Image: "kbom:test", Schedule: "@daily", ListAllResources: true}
+	objects, err := installObjects(cfg, enabled)
+	require.NoError(t, err)
+	require.Len(t, objects, 4)
+
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"list"}},
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}, ResourceNames: []string{"kube-root-ca.crt"}},
+	}, objects[1].(*rbacv1.ClusterRole).Rules)
+
+	cronJob := objects[3].(*batchv1.CronJob)
+	assert.Equal(t, "@daily", cronJob.Spec.Schedule)
+	assert.Equal(t, []string{"generate", "--include=workloads"}, cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args)
+}
+
+func TestInstallObjectsOperator(t *testing.T) {
+	cfg := installConfig{Mode: OperatorMode, Name: "kbom", Namespace: "kbom", Image: "kbom:test", Storage: "configmap"}
+	objects, err := installObjects(cfg, nil)
+	require.NoError(t, err)
+	require.Len(t, objects, 6)
+
+	role := objects[3].(*rbacv1.Role)
+	assert.Contains(t, role.Rules, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"create"}})
+	assert.Contains(t, role.Rules, rbacv1.PolicyRule{APIGroups: []string{"coordination.k8s.io"}, Resources: []string{"leases"},
+		Verbs: []string{"update"}, ResourceNames: []string{"kbom-operator"}})
+	assert.Equal(t, "kbom", objects[4].(*rbacv1.RoleBinding).RoleRef.Name)
+	assert.Equal(t, []string{"operator", "--storage=configmap"}, objects[5].(*appsv1.Deployment).Spec.Template.Spec.Containers[0].Args)
+
+	_, err = installObjects(installConfig{Mode: "daemonset", Image: "kbom:test"}, nil)
+	assert.EqualError(t, err, `mode "daemonset" is not supported, use one of: operator, serve, cronjob`)
+
+	_, err = installObjects(installConfig{Mode: OperatorMode}, nil)
+	assert.EqualError(t, err, "image is required")
+}
+
+func TestRenderManifests(t *testing.T) {
+	manifests, err := renderManifests(installConfig{Mode: CronJobMode, Name: "kbom", Namespace: "kbom", Image: "kbom:test"}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, strings.Count(string(manifests), manifestSplit))
+	assert.Contains(t, string(manifests), "kind: CronJob\n")
+	assert.NotContains(t, string(manifests), "creationTimestamp")
+}
+
+func TestWriteChart(t *testing.T) {
+	dir := t.TempDir()
+	cfg := installConfig{Mode: OperatorMode, Name: "kbom", Namespace: "kbom", Image: "kbom:test", Storage: "crd"}
+	require.NoError(t, writeChart(dir, cfg, nil))
+
+	values, err := os.ReadFile(path.Join(dir, "values.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "image: kbom:test\n", string(values))
+
+	templates, err := os.ReadFile(path.Join(dir, "templates", "kbom.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(templates), "namespace: '{{ .Release.Namespace }}'")
+	assert.Contains(t, string(templates), "image: '{{ .Values.image }}'")
+
+	for _, file := range []string{"Chart.yaml", "crds/kbomreports.yaml", "crds/kbomrequests.yaml"} {
+		assert.FileExists(t, path.Join(dir, file))
+	}
+
+	dir = t.TempDir()
+	cfg = installConfig{Mode: ServeMode, Name: "kbom", Namespace: "kbom", Image: "kbom:test", RequireToken: true, Token: "0123"}
+	require.NoError(t, writeChart(dir, cfg, nil))
+
+	values, err = os.ReadFile(path.Join(dir, "values.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "image: kbom:test\ntoken: \"0123\"\n", string(values))
+
+	templates, err = os.ReadFile(path.Join(dir, "templates", "kbom.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(templates), "token: '{{ .Values.token }}'")
+}
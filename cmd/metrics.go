@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+)
+
+var metricsAddress string
+
+// serveMetrics serves the collection metrics on address until ctx is cancelled, nothing is served without an address
+func serveMetrics(ctx context.Context, address string) {
+	if address == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", collectionMetrics.Handler())
+	srv := &http.Server{Addr: address, Handler: mux, ReadHeaderTimeout: readHeaderTimeout}
+
+	go func() {
+		log.Info().Str("address", address).Msg("Serving metrics")
+		if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("Failed to serve metrics")
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+}
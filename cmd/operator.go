@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/rad-security/kbom/internal/kube"
+	"github.com/rad-security/kbom/internal/model"
+	"github.com/rad-security/kbom/internal/operator"
+	"github.com/rad-security/kbom/internal/utils"
+)
+
+const serviceAccountNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+var operatorCfg operator.Config
+
+var OperatorCmd = &cobra.Command{
+	Use:   "operator",
+	Short: "Run in the cluster and keep fresh KBOMs as KBOMReport resources or ConfigMaps",
+	Long: `Run as a controller that generates a KBOM every --interval and for every new KBOMRequest
+in its namespace. Each KBOM is stored gzipped as a KBOMReport custom resource or a ConfigMap,
+keeping the newest --retention reports. With --leader-elect only one replica generates reports.`,
+	RunE: runOperator,
+}
+
+func init() {
+	OperatorCmd.Flags().StringVar(&operatorCfg.Namespace, "namespace", "",
+		"Namespace to store the reports and read the requests in, defaults to the pod namespace")
+	OperatorCmd.Flags().StringVar(&operatorCfg.Storage, "storage", operator.CRDStorage,
+		fmt.Sprintf("Report storage (%s, %s)", operator.CRDStorage, operator.ConfigMapStorage))
+	OperatorCmd.Flags().DurationVar(&operatorCfg.Interval, "interval", 6*time.Hour, "Interval between scheduled reports")
+	OperatorCmd.Flags().DurationVar(&operatorCfg.RequestInterval, "request-interval", 30*time.Second,
+		"Interval between checks for new KBOMRequests")
+	OperatorCmd.Flags().IntVar(&operatorCfg.Retention, "retention", 5, "Number of reports to keep")
+	OperatorCmd.Flags().BoolVar(&operatorCfg.LeaderElect, "leader-elect", true, "Enable leader election")
+	OperatorCmd.Flags().StringVar(&operatorCfg.LeaseName, "lease-name", "kbom-operator", "Name of the leader election lease")
+	OperatorCmd.Flags().StringVar(&metricsAddress, "metrics-address", "", "Address to serve Prometheus metrics on, e.g. :9090")
+	OperatorCmd.Flags().StringSliceVar(&include, "include", nil, includeUsage())
+	OperatorCmd.Flags().StringSliceVar(&exclude, "exclude", nil, excludeUsage)
+	OperatorCmd.Flags().StringVar(&nodePoolLabel, "node-pool-label", "",
+		"Fallback node label to group node pools by (with --include nodepools)")
+	OperatorCmd.Flags().StringVar(&vulnDBPath, "vuln-db", "", "Path to a local directory with OSV advisories to match against")
+	OperatorCmd.Flags().StringSliceVar(&vexPaths, "vex", nil, "Paths to OpenVEX documents to apply to the findings")
+	addRedactFlags(OperatorCmd)
+
+	utils.BindFlags(OperatorCmd)
+}
+
+func runOperator(cmd *cobra.Command, _ []string) error {
+	enabled, err := enabledCollectors(include, exclude)
+	if err != nil {
+		return err
+	}
+
+	if redactor, err = newRedactor(); err != nil {
+		return err
+	}
+
+	cfg, currentK8sContext, err := kube.RestConfig(k8sContext)
+	if err != nil {
+		return err
+	}
+
+	k8sClient, err := kube.NewClientForConfig(cfg, currentK8sContext)
+	if err != nil {
+		return err
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("can not create kubernetes client: %w", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("can not create kubernetes dynamic client: %w", err)
+	}
+
+	if operatorCfg.Namespace == "" {
+		operatorCfg.Namespace = podNamespace()
+	}
+	if operatorCfg.Identity, err = os.Hostname(); err != nil {
+		return err
+	}
+
+	op, err := operator.New(operatorCfg, clientset, dynamicClient, func(ctx context.Context) (*model.KBOM, error) {
+		return buildKBOM(ctx, k8sClient, uuid.New().String(), time.Now(), enabled, FailOnCritical)
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveMetrics(ctx, metricsAddress)
+
+	return op.Run(ctx)
+}
+
+// podNamespace returns the namespace of the service account the pod runs as, default outside of a pod
+func podNamespace() string {
+	namespace, err := os.ReadFile(serviceAccountNamespacePath)
+	if err != nil {
+		return "default"
+	}
+
+	return strings.TrimSpace(string(namespace))
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/rad-security/kbom/internal/kube"
+	"github.com/rad-security/kbom/internal/utils"
+)
+
+var (
+	roleName string
+
+	errOut io.Writer = os.Stderr
+)
+
+var PreflightCmd = &cobra.Command{
+	Use:   "preflight",
+	Short: "Check that the current user has the permissions generate needs",
+	Long: `Check with SelfSubjectAccessReviews that the current user can list nodes, namespaces and pods, get the
+kube-root-ca.crt ConfigMap in kube-system, list every discovered resource and list the resources of the sections
+selected with --include. Missing permissions are printed as a table on stderr, and a minimal ClusterRole granting
+them as YAML on stdout.`,
+	RunE: runPreflight,
+}
+
+func init() {
+	PreflightCmd.Flags().StringSliceVar(&include, "include", nil,
+		fmt.Sprintf("Optional sections to check the permissions of (%s, %s)", allSections, strings.Join(collectorNames(), ", ")))
+	PreflightCmd.Flags().StringSliceVar(&exclude, "exclude", nil, excludeUsage)
+	PreflightCmd.Flags().StringVar(&roleName, "role-name", "kbom", "Name of the ClusterRole granting the missing permissions")
+
+	utils.BindFlags(PreflightCmd)
+}
+
+func runPreflight(cmd *cobra.Command, _ []string) error {
+	enabled, err := enabledCollectors(include, exclude)
+	if err != nil {
+		return err
+	}
+
+	cfg, _, err := kube.RestConfig(k8sContext)
+	if err != nil {
+		return err
+	}
+
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("can not create kubernetes client: %w", err)
+	}
+
+	return preflight(cmd.Context(), kube.NewAccessReviewer(client), enabled)
+}
+
+// preflight reviews the permissions of the core, the resources and the enabled sections, printing the missing ones
+// and a ClusterRole granting them. It fails when any permission is missing.
+func preflight(ctx context.Context, reviewer *kube.AccessReviewer, enabled []collector) error {
+	resourcePermissions, err := reviewer.ResourcePermissions(ctx)
+	if err != nil {
+		return err
+	}
+
+	checks, err := reviewer.Check(ctx, requiredPermissions(resourcePermissions, enabled))
+	if err != nil {
+		return err
+	}
+
+	var missing []kube.Permission
+	for i := range checks {
+		if !checks[i].Allowed {
+			missing = append(missing, checks[i].Permission)
+		}
+	}
+
+	if len(missing) == 0 {
+		fmt.Fprintf(errOut, "All %d permissions are granted\n", len(checks))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(errOut, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SECTION\tVERB\tRESOURCE")
+	for _, p := range missing {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", p.Section, p.Verb, p.Target())
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	role, err := manifestYAML(kube.ClusterRole(roleName, missing))
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(role); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%d of %d permissions are missing", len(missing), len(checks))
+}
+
+// requiredPermissions returns the core permissions, the ones of the enabled sections and the given resource permissions,
+// each permission once under the first section needing it. Resources of the sections that are not served, e.g. Gateway
+// API routes without the CRDs installed, are left out as the sections skip them.
+func requiredPermissions(resourcePermissions []kube.Permission, enabled []collector) []kube.Permission {
+	served := make(map[schema.GroupResource]bool, len(resourcePermissions))
+	for _, p := range resourcePermissions {
+		served[schema.GroupResource{Group: p.Group, Resource: p.Resource}] = true
+	}
+
+	all := append([]kube.Permission{}, kube.CorePermissions...)
+	for i := range enabled {
+		for _, p := range enabled[i].permissions() {
+			if served[schema.GroupResource{Group: p.Group, Resource: p.Resource}] {
+				all = append(all, p)
+			}
+		}
+	}
+	all = append(all, resourcePermissions...)
+
+	return uniquePermissions(all)
+}
+
+// uniquePermissions returns each permission once, under the first section needing it
+func uniquePermissions(all []kube.Permission) []kube.Permission {
+	seen := make(map[kube.Permission]bool, len(all))
+	res := make([]kube.Permission, 0, len(all))
+	for _, p := range all {
+		key := p
+		key.Section = ""
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		res = append(res, p)
+	}
+
+	return res
+}
+
+// manifestYAML encodes a Kubernetes object as YAML, without the empty creation timestamps and status
+func manifestYAML(obj runtime.Object) ([]byte, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	removeEmptyTimestamps(content)
+	unstructured.RemoveNestedField(content, "status")
+
+	return yaml.Marshal(content)
+}
+
+// removeEmptyTimestamps removes the unset creation timestamps of the object and its pod and job templates
+func removeEmptyTimestamps(content map[string]interface{}) {
+	for key, value := range content {
+		switch v := value.(type) {
+		case nil:
+			if key == "creationTimestamp" {
+				delete(content, key)
+			}
+		case map[string]interface{}:
+			removeEmptyTimestamps(v)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/rad-security/kbom/internal/kube"
+)
+
+const expectedPreflightTable = `SECTION  VERB  RESOURCE
+core     get   kube-system/configmaps/kube-root-ca.crt
+rbac     list  clusterroles.rbac.authorization.k8s.io
+`
+
+const expectedPreflightRole = `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: kbom-reader
+rules:
+- apiGroups:
+  - ""
+  resourceNames:
+  - kube-root-ca.crt
+  resources:
+  - configmaps
+  verbs:
+  - get
+- apiGroups:
+  - rbac.authorization.k8s.io
+  resources:
+  - clusterroles
+  verbs:
+  - list
+`
+
+func TestPreflight(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "pods", Verbs: []string{"list"}},
+			{Name: "configmaps", Verbs: []string{"list"}},
+		}},
+		{GroupVersion: "rbac.authorization.k8s.io/v1", APIResources: []metav1.APIResource{
+			{Name: "clusterroles", Verbs: []string{"list"}},
+		}},
+	}
+	denied := map[string]bool{"configmaps/kube-root-ca.crt": true, "clusterroles": true}
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		key := attrs.Resource
+		if attrs.Name != "" {
+			key += "/" + attrs.Name
+		}
+		review.Status.Allowed = !denied[key]
+
+		return true, review, nil
+	})
+
+	stdout := &stdoutMock{buf: bytes.Buffer{}}
+	stderr := &bytes.Buffer{}
+	out, errOut = stdout, stderr
+	defer func() { errOut = os.Stderr }()
+	roleName = "kbom-reader"
+
+	enabled, err := enabledCollectors([]string{"rbac"}, nil)
+	require.NoError(t, err)
+
+	err = preflight(context.Background(), kube.NewAccessReviewer(client), enabled)
+	assert.EqualError(t, err, "2 of 6 permissions are missing")
+	assert.Equal(t, expectedPreflightTable, stderr.String())
+	assert.Equal(t, expectedPreflightRole, stdout.buf.String())
+
+	denied = map[string]bool{}
+	stdout.buf.Reset()
+	stderr.Reset()
+	require.NoError(t, preflight(context.Background(), kube.NewAccessReviewer(client), enabled))
+	assert.Equal(t, "All 6 permissions are granted\n", stderr.String())
+	assert.Empty(t, stdout.buf.String())
+}
+
+func TestRequiredPermissions(t *testing.T) {
+	enabled, err := enabledCollectors([]string{"nodepools", "network"}, nil)
+	require.NoError(t, err)
+
+	resources := kube.ListPermissions(kube.SectionResources, []schema.GroupResource{
+		{Resource: "nodes"},
+		{Resource: "services"},
+		{Resource: "secrets"},
+	}...)
+	permissions := requiredPermissions(resources, enabled)
+
+	assert.Equal(t, append(append([]kube.Permission{}, kube.CorePermissions...),
+		kube.Permission{Section: "network", Verb: "list", Resource: "services"},
+		kube.Permission{Section: kube.SectionResources, Verb: "list", Resource: "secrets"},
+	), permissions, "nodes are core, gateways are not served")
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rad-security/kbom/internal/redact"
+)
+
+var (
+	redactNames    []string
+	redactKeyFile  string
+	redactMetadata []string
+
+	// redactor redacts the KBOMs before they are written, nil unless --redact is set
+	redactor *redact.Redactor
+)
+
+func addRedactFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&redactNames, "redact", nil,
+		fmt.Sprintf("Redaction profiles or fields to hash or drop before output (%s)", strings.Join(redact.Names(), ", ")))
+	cmd.Flags().StringVar(&redactKeyFile, "redact-key-file", "",
+		"Path to the HMAC key redacted values are hashed with, the same key keeps them joinable across KBOMs")
+	cmd.Flags().StringSliceVar(&redactMetadata, "redact-metadata", []string{"*"},
+		"Patterns of the label and annotation keys dropped with --redact metadata")
+}
+
+// newRedactor returns the redactor of the --redact flags, nil without --redact
+func newRedactor() (*redact.Redactor, error) {
+	if len(redactNames) == 0 {
+		return nil, nil
+	}
+
+	opts, err := redact.NewOptions(redactNames, redactMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	var key []byte
+	if redactKeyFile != "" {
+		if key, err = os.ReadFile(redactKeyFile); err != nil {
+			return nil, fmt.Errorf("failed to read the redaction key: %w", err)
+		}
+	}
+
+	return redact.New(opts, bytes.TrimSpace(key))
+}
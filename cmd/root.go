@@ -43,6 +43,14 @@ func Execute() {
 
 func init() {
 	rootCmd.AddCommand(GenerateCmd)
+	rootCmd.AddCommand(EnrichCmd)
+	rootCmd.AddCommand(vexCmd)
+	rootCmd.AddCommand(AggregateCmd)
+	rootCmd.AddCommand(PreflightCmd)
+	rootCmd.AddCommand(InstallManifestsCmd)
+	rootCmd.AddCommand(OperatorCmd)
+	rootCmd.AddCommand(WatchCmd)
+	rootCmd.AddCommand(ServeCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(schemaCmd)
 
@@ -34,292 +34,310 @@ var expectedSchema = `{
   "$id": "https://github.com/rad-security/kbom/internal/model/kbom",
   "$ref": "#/$defs/KBOM",
   "$defs": {
-    "Capacity": {
+    "Admission": {
       "properties": {
-        "cpu": {
-          "type": "string"
+        "validating_webhooks": {
+          "items": {
+            "$ref": "#/$defs/WebhookConfiguration"
+          },
+          "type": "array"
         },
-        "memory": {
-          "type": "string"
+        "mutating_webhooks": {
+          "items": {
+            "$ref": "#/$defs/WebhookConfiguration"
+          },
+          "type": "array"
         },
-        "pods": {
-          "type": "string"
+        "policies": {
+          "items": {
+            "$ref": "#/$defs/AdmissionPolicy"
+          },
+          "type": "array"
         },
-        "ephemeral_storage": {
-          "type": "string"
+        "policy_engines": {
+          "items": {
+            "$ref": "#/$defs/DetectedComponent"
+          },
+          "type": "array"
         }
       },
       "additionalProperties": false,
       "type": "object",
       "required": [
-        "cpu",
-        "memory",
-        "pods",
-        "ephemeral_storage"
+        "validating_webhooks",
+        "mutating_webhooks",
+        "policies",
+        "policy_engines"
       ]
     },
-    "Cluster": {
+    "AdmissionPolicy": {
       "properties": {
         "name": {
           "type": "string"
         },
-        "ca_cert_digest": {
+        "api_version": {
           "type": "string"
         },
-        "k8s_version": {
+        "failure_policy": {
           "type": "string"
         },
-        "cni_version": {
+        "param_kind": {
           "type": "string"
         },
-        "location": {
-          "$ref": "#/$defs/Location"
+        "namespace_selector": {
+          "type": "string"
         },
-        "nodes_count": {
+        "rules": {
+          "items": {
+            "$ref": "#/$defs/AdmissionRule"
+          },
+          "type": "array"
+        },
+        "validations": {
           "type": "integer"
         },
-        "nodes": {
+        "bindings": {
           "items": {
-            "$ref": "#/$defs/Node"
+            "$ref": "#/$defs/AdmissionPolicyBinding"
           },
           "type": "array"
-        },
-        "components": {
-          "$ref": "#/$defs/Components"
         }
       },
       "additionalProperties": false,
       "type": "object",
       "required": [
         "name",
-        "ca_cert_digest",
-        "k8s_version",
-        "location",
-        "nodes_count",
-        "nodes",
-        "components"
+        "api_version",
+        "failure_policy",
+        "rules",
+        "validations",
+        "bindings"
       ]
     },
-    "Components": {
+    "AdmissionPolicyBinding": {
       "properties": {
-        "images": {
+        "name": {
+          "type": "string"
+        },
+        "validation_actions": {
           "items": {
-            "$ref": "#/$defs/Image"
+            "type": "string"
           },
           "type": "array"
         },
-        "resources": {
-          "additionalProperties": {
-            "$ref": "#/$defs/ResourceList"
-          },
-          "type": "object"
+        "namespace_selector": {
+          "type": "string"
         }
       },
       "additionalProperties": false,
       "type": "object",
       "required": [
-        "resources"
+        "name"
       ]
     },
-    "Image": {
+    "AdmissionRule": {
       "properties": {
-        "full_name": {
-          "type": "string"
+        "operations": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
         },
-        "name": {
-          "type": "string"
+        "api_groups": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
         },
-        "version": {
-          "type": "string"
+        "api_versions": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
         },
-        "digest": {
+        "resources": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "scope": {
           "type": "string"
         }
       },
       "additionalProperties": false,
       "type": "object",
       "required": [
-        "full_name",
-        "name",
-        "version",
-        "digest"
+        "api_groups",
+        "api_versions",
+        "resources"
       ]
     },
-    "KBOM": {
+    "Backend": {
       "properties": {
-        "id": {
+        "host": {
           "type": "string"
         },
-        "bom_format": {
+        "path": {
           "type": "string"
         },
-        "spec_version": {
+        "service": {
           "type": "string"
         },
-        "generated_at": {
-          "type": "string",
-          "format": "date-time"
+        "namespace": {
+          "type": "string"
         },
-        "generated_by": {
-          "$ref": "#/$defs/Tool"
+        "port": {
+          "type": "string"
         },
-        "cluster": {
-          "$ref": "#/$defs/Cluster"
+        "workloads": {
+          "items": {
+            "$ref": "#/$defs/WorkloadRef"
+          },
+          "type": "array"
         }
       },
       "additionalProperties": false,
       "type": "object",
       "required": [
-        "id",
-        "bom_format",
-        "spec_version",
-        "generated_at",
-        "generated_by",
-        "cluster"
+        "service",
+        "namespace",
+        "workloads"
       ]
     },
-    "Location": {
+    "CSIContainer": {
       "properties": {
         "name": {
           "type": "string"
         },
-        "region": {
+        "image": {
           "type": "string"
         },
-        "zone": {
+        "version": {
           "type": "string"
+        },
+        "sidecar": {
+          "type": "boolean"
         }
       },
       "additionalProperties": false,
       "type": "object",
       "required": [
         "name",
-        "region",
-        "zone"
+        "image",
+        "sidecar"
       ]
     },
-    "Node": {
+    "CSIDriver": {
       "properties": {
         "name": {
           "type": "string"
         },
-        "type": {
-          "type": "string"
-        },
-        "hostname": {
+        "version": {
           "type": "string"
         },
-        "capacity": {
-          "$ref": "#/$defs/Capacity"
+        "attach_required": {
+          "type": "boolean"
         },
-        "allocatable": {
-          "$ref": "#/$defs/Capacity"
+        "pod_info_on_mount": {
+          "type": "boolean"
         },
-        "labels": {
-          "additionalProperties": {
+        "lifecycle_modes": {
+          "items": {
             "type": "string"
           },
-          "type": "object"
+          "type": "array"
         },
-        "annotations": {
-          "additionalProperties": {
-            "type": "string"
+        "plugins": {
+          "items": {
+            "$ref": "#/$defs/CSIPlugin"
           },
-          "type": "object"
-        },
-        "machine_id": {
-          "type": "string"
-        },
-        "architecture": {
-          "type": "string"
-        },
-        "container_runtime_version": {
-          "type": "string"
-        },
-        "boot_id": {
-          "type": "string"
-        },
-        "kernel_version": {
-          "type": "string"
-        },
-        "kube_proxy_version": {
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name",
+        "attach_required",
+        "pod_info_on_mount",
+        "plugins"
+      ]
+    },
+    "CSIPlugin": {
+      "properties": {
+        "component": {
           "type": "string"
         },
-        "kubelet_version": {
+        "namespace": {
           "type": "string"
         },
-        "operating_system": {
+        "workload": {
           "type": "string"
         },
-        "os_image": {
-          "type": "string"
+        "containers": {
+          "items": {
+            "$ref": "#/$defs/CSIContainer"
+          },
+          "type": "array"
         }
       },
       "additionalProperties": false,
       "type": "object",
       "required": [
-        "name",
-        "type",
-        "hostname",
-        "capacity",
-        "allocatable",
-        "labels",
-        "annotations",
-        "machine_id",
-        "architecture",
-        "container_runtime_version",
-        "boot_id",
-        "kernel_version",
-        "kube_proxy_version",
-        "kubelet_version",
-        "operating_system",
-        "os_image"
+        "component",
+        "namespace",
+        "workload",
+        "containers"
       ]
     },
-    "Resource": {
+    "Capacity": {
       "properties": {
-        "kind": {
+        "cpu": {
           "type": "string"
         },
-        "api_version": {
+        "memory": {
           "type": "string"
         },
-        "name": {
+        "pods": {
           "type": "string"
         },
-        "namespace": {
+        "ephemeral_storage": {
           "type": "string"
-        },
-        "additional_properties": {
-          "additionalProperties": {
-            "type": "string"
-          },
-          "type": "object"
         }
       },
       "additionalProperties": false,
       "type": "object",
       "required": [
-        "name"
+        "cpu",
+        "memory",
+        "pods",
+        "ephemeral_storage"
       ]
     },
-    "ResourceList": {
+    "ClaimSummary": {
       "properties": {
-        "kind": {
+        "namespace": {
           "type": "string"
         },
-        "api_version": {
-          "type": "string"
+        "total": {
+          "type": "integer"
         },
-        "namespaced": {
-          "type": "boolean"
+        "bound": {
+          "type": "integer"
         },
-        "count": {
+        "pending": {
           "type": "integer"
         },
-        "resources": {
+        "lost": {
+          "type": "integer"
+        },
+        "requested": {
+          "type": "string"
+        },
+        "storage_classes": {
           "items": {
-            "$ref": "#/$defs/Resource"
+            "type": "string"
           },
           "type": "array"
         }
@@ -327,33 +345,1608 @@ var expectedSchema = `{
       "additionalProperties": false,
       "type": "object",
       "required": [
-        "kind",
-        "api_version",
-        "namespaced",
-        "count"
+        "namespace",
+        "total",
+        "bound",
+        "pending",
+        "lost",
+        "requested",
+        "storage_classes"
       ]
     },
-    "Tool": {
+    "Cluster": {
       "properties": {
-        "vendor": {
-          "type": "string"
-        },
         "name": {
           "type": "string"
         },
-        "build_time": {
+        "ca_cert_digest": {
           "type": "string"
         },
-        "version": {
+        "k8s_version": {
           "type": "string"
         },
-        "commit": {
+        "cni_version": {
           "type": "string"
         },
-        "commit_time": {
-          "type": "string"
-        }
-      },
+        "location": {
+          "$ref": "#/$defs/Location"
+        },
+        "nodes_count": {
+          "type": "integer"
+        },
+        "nodes": {
+          "items": {
+            "$ref": "#/$defs/Node"
+          },
+          "type": "array"
+        },
+        "node_pools": {
+          "items": {
+            "$ref": "#/$defs/NodePool"
+          },
+          "type": "array"
+        },
+        "components": {
+          "$ref": "#/$defs/Components"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name",
+        "ca_cert_digest",
+        "k8s_version",
+        "location",
+        "nodes_count",
+        "nodes",
+        "components"
+      ]
+    },
+    "CollectionError": {
+      "properties": {
+        "phase": {
+          "type": "string"
+        },
+        "resource": {
+          "type": "string"
+        },
+        "class": {
+          "type": "string"
+        },
+        "critical": {
+          "type": "boolean"
+        },
+        "message": {
+          "type": "string"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "phase",
+        "class",
+        "message"
+      ]
+    },
+    "Components": {
+      "properties": {
+        "images": {
+          "items": {
+            "$ref": "#/$defs/Image"
+          },
+          "type": "array"
+        },
+        "registries": {
+          "items": {
+            "$ref": "#/$defs/RegistrySummary"
+          },
+          "type": "array"
+        },
+        "resources": {
+          "additionalProperties": {
+            "$ref": "#/$defs/ResourceList"
+          },
+          "type": "object"
+        },
+        "rbac": {
+          "$ref": "#/$defs/RBAC"
+        },
+        "pod_security": {
+          "$ref": "#/$defs/PodSecurity"
+        },
+        "admission": {
+          "$ref": "#/$defs/Admission"
+        },
+        "network": {
+          "$ref": "#/$defs/Network"
+        },
+        "storage": {
+          "$ref": "#/$defs/Storage"
+        },
+        "service_mesh": {
+          "$ref": "#/$defs/ServiceMesh"
+        },
+        "workloads": {
+          "$ref": "#/$defs/Workloads"
+        },
+        "pull_secrets": {
+          "$ref": "#/$defs/PullSecrets"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "resources"
+      ]
+    },
+    "DetectedComponent": {
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "version": {
+          "type": "string"
+        },
+        "namespace": {
+          "type": "string"
+        },
+        "workload": {
+          "type": "string"
+        },
+        "image": {
+          "type": "string"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name",
+        "namespace",
+        "workload",
+        "image"
+      ]
+    },
+    "ExposedService": {
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "namespace": {
+          "type": "string"
+        },
+        "type": {
+          "type": "string"
+        },
+        "external_ips": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "ports": {
+          "items": {
+            "$ref": "#/$defs/ServicePort"
+          },
+          "type": "array"
+        },
+        "workloads": {
+          "items": {
+            "$ref": "#/$defs/WorkloadRef"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name",
+        "namespace",
+        "type",
+        "ports",
+        "workloads"
+      ]
+    },
+    "Finding": {
+      "properties": {
+        "id": {
+          "type": "string"
+        },
+        "aliases": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "summary": {
+          "type": "string"
+        },
+        "severity": {
+          "type": "string"
+        },
+        "cvss": {
+          "type": "string"
+        },
+        "source": {
+          "type": "string"
+        },
+        "package": {
+          "type": "string"
+        },
+        "version": {
+          "type": "string"
+        },
+        "fixed": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "target": {
+          "$ref": "#/$defs/FindingTarget"
+        },
+        "status": {
+          "type": "string"
+        },
+        "justification": {
+          "type": "string"
+        },
+        "impact_statement": {
+          "type": "string"
+        },
+        "action_statement": {
+          "type": "string"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "id",
+        "source",
+        "package",
+        "version",
+        "target"
+      ]
+    },
+    "FindingTarget": {
+      "properties": {
+        "type": {
+          "type": "string"
+        },
+        "kind": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        },
+        "namespace": {
+          "type": "string"
+        },
+        "ref": {
+          "type": "string"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "type",
+        "name"
+      ]
+    },
+    "Gateway": {
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "namespace": {
+          "type": "string"
+        },
+        "class_name": {
+          "type": "string"
+        },
+        "addresses": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "listeners": {
+          "items": {
+            "$ref": "#/$defs/Listener"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name",
+        "namespace",
+        "class_name",
+        "listeners"
+      ]
+    },
+    "Grant": {
+      "properties": {
+        "role": {
+          "$ref": "#/$defs/RoleRef"
+        },
+        "binding": {
+          "type": "string"
+        },
+        "namespace": {
+          "type": "string"
+        },
+        "risks": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "role",
+        "binding"
+      ]
+    },
+    "Image": {
+      "properties": {
+        "full_name": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        },
+        "version": {
+          "type": "string"
+        },
+        "digest": {
+          "type": "string"
+        },
+        "registry": {
+          "type": "string"
+        },
+        "repository": {
+          "type": "string"
+        },
+        "tag": {
+          "type": "string"
+        },
+        "normalized": {
+          "type": "string"
+        },
+        "spec_digest": {
+          "type": "string"
+        },
+        "runtime_digests": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "mutable_tag": {
+          "type": "boolean"
+        },
+        "latest_tag": {
+          "type": "boolean"
+        },
+        "digest_drift": {
+          "type": "boolean"
+        },
+        "platforms": {
+          "items": {
+            "$ref": "#/$defs/ImagePlatform"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "full_name",
+        "name",
+        "version",
+        "digest",
+        "registry",
+        "repository",
+        "normalized"
+      ]
+    },
+    "ImagePlatform": {
+      "properties": {
+        "platform": {
+          "type": "string"
+        },
+        "digest": {
+          "type": "string"
+        },
+        "digest_kind": {
+          "type": "string"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "platform"
+      ]
+    },
+    "Ingress": {
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "namespace": {
+          "type": "string"
+        },
+        "class_name": {
+          "type": "string"
+        },
+        "hosts": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "tls": {
+          "items": {
+            "$ref": "#/$defs/TLSConfig"
+          },
+          "type": "array"
+        },
+        "external_addresses": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "backends": {
+          "items": {
+            "$ref": "#/$defs/Backend"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name",
+        "namespace",
+        "hosts",
+        "backends"
+      ]
+    },
+    "KBOM": {
+      "properties": {
+        "id": {
+          "type": "string"
+        },
+        "bom_format": {
+          "type": "string"
+        },
+        "spec_version": {
+          "type": "string"
+        },
+        "generated_at": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "generated_by": {
+          "$ref": "#/$defs/Tool"
+        },
+        "cluster": {
+          "$ref": "#/$defs/Cluster"
+        },
+        "findings": {
+          "items": {
+            "$ref": "#/$defs/Finding"
+          },
+          "type": "array"
+        },
+        "collection_errors": {
+          "items": {
+            "$ref": "#/$defs/CollectionError"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "id",
+        "bom_format",
+        "spec_version",
+        "generated_at",
+        "generated_by",
+        "cluster"
+      ]
+    },
+    "Listener": {
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "hostname": {
+          "type": "string"
+        },
+        "port": {
+          "type": "integer"
+        },
+        "protocol": {
+          "type": "string"
+        },
+        "tls_secrets": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name",
+        "port",
+        "protocol"
+      ]
+    },
+    "Location": {
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "region": {
+          "type": "string"
+        },
+        "zone": {
+          "type": "string"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name",
+        "region",
+        "zone"
+      ]
+    },
+    "Mesh": {
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "version": {
+          "type": "string"
+        },
+        "control_plane": {
+          "items": {
+            "$ref": "#/$defs/DetectedComponent"
+          },
+          "type": "array"
+        },
+        "crds": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "namespaces": {
+          "items": {
+            "$ref": "#/$defs/MeshNamespace"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name",
+        "control_plane",
+        "crds",
+        "namespaces"
+      ]
+    },
+    "MeshNamespace": {
+      "properties": {
+        "namespace": {
+          "type": "string"
+        },
+        "sidecars": {
+          "items": {
+            "$ref": "#/$defs/SidecarProxy"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "namespace",
+        "sidecars"
+      ]
+    },
+    "NamespaceSecurity": {
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "pod_security_admission": {
+          "$ref": "#/$defs/PodSecurityAdmission"
+        },
+        "summary": {
+          "$ref": "#/$defs/SecuritySummary"
+        },
+        "workloads": {
+          "items": {
+            "$ref": "#/$defs/WorkloadSecurity"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name",
+        "pod_security_admission",
+        "summary",
+        "workloads"
+      ]
+    },
+    "Network": {
+      "properties": {
+        "exposed_services": {
+          "items": {
+            "$ref": "#/$defs/ExposedService"
+          },
+          "type": "array"
+        },
+        "ingresses": {
+          "items": {
+            "$ref": "#/$defs/Ingress"
+          },
+          "type": "array"
+        },
+        "gateways": {
+          "items": {
+            "$ref": "#/$defs/Gateway"
+          },
+          "type": "array"
+        },
+        "routes": {
+          "items": {
+            "$ref": "#/$defs/Route"
+          },
+          "type": "array"
+        },
+        "controllers": {
+          "items": {
+            "$ref": "#/$defs/DetectedComponent"
+          },
+          "type": "array"
+        },
+        "namespaces_without_network_policy": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "exposed_services",
+        "ingresses",
+        "gateways",
+        "routes",
+        "controllers",
+        "namespaces_without_network_policy"
+      ]
+    },
+    "Node": {
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "type": {
+          "type": "string"
+        },
+        "hostname": {
+          "type": "string"
+        },
+        "capacity": {
+          "$ref": "#/$defs/Capacity"
+        },
+        "allocatable": {
+          "$ref": "#/$defs/Capacity"
+        },
+        "labels": {
+          "additionalProperties": {
+            "type": "string"
+          },
+          "type": "object"
+        },
+        "annotations": {
+          "additionalProperties": {
+            "type": "string"
+          },
+          "type": "object"
+        },
+        "machine_id": {
+          "type": "string"
+        },
+        "architecture": {
+          "type": "string"
+        },
+        "container_runtime_version": {
+          "type": "string"
+        },
+        "boot_id": {
+          "type": "string"
+        },
+        "kernel_version": {
+          "type": "string"
+        },
+        "kube_proxy_version": {
+          "type": "string"
+        },
+        "kubelet_version": {
+          "type": "string"
+        },
+        "operating_system": {
+          "type": "string"
+        },
+        "os_image": {
+          "type": "string"
+        },
+        "runtime": {
+          "$ref": "#/$defs/Software"
+        },
+        "os": {
+          "$ref": "#/$defs/Software"
+        },
+        "kernel": {
+          "$ref": "#/$defs/Software"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name",
+        "type",
+        "hostname",
+        "capacity",
+        "allocatable",
+        "labels",
+        "annotations",
+        "machine_id",
+        "architecture",
+        "container_runtime_version",
+        "boot_id",
+        "kernel_version",
+        "kube_proxy_version",
+        "kubelet_version",
+        "operating_system",
+        "os_image"
+      ]
+    },
+    "NodePool": {
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "label": {
+          "type": "string"
+        },
+        "nodes": {
+          "type": "integer"
+        },
+        "instance_types": {
+          "items": {
+            "$ref": "#/$defs/ValueCount"
+          },
+          "type": "array"
+        },
+        "os_images": {
+          "items": {
+            "$ref": "#/$defs/ValueCount"
+          },
+          "type": "array"
+        },
+        "kernel_versions": {
+          "items": {
+            "$ref": "#/$defs/ValueCount"
+          },
+          "type": "array"
+        },
+        "kubelet_versions": {
+          "items": {
+            "$ref": "#/$defs/ValueCount"
+          },
+          "type": "array"
+        },
+        "runtime_versions": {
+          "items": {
+            "$ref": "#/$defs/ValueCount"
+          },
+          "type": "array"
+        },
+        "capacity": {
+          "$ref": "#/$defs/Capacity"
+        },
+        "allocatable": {
+          "$ref": "#/$defs/Capacity"
+        },
+        "version_skew": {
+          "type": "boolean"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name",
+        "nodes",
+        "instance_types",
+        "os_images",
+        "kernel_versions",
+        "kubelet_versions",
+        "runtime_versions",
+        "capacity",
+        "allocatable",
+        "version_skew"
+      ]
+    },
+    "Owner": {
+      "properties": {
+        "api_version": {
+          "type": "string"
+        },
+        "kind": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "api_version",
+        "kind",
+        "name"
+      ]
+    },
+    "PersistentVolumeSummary": {
+      "properties": {
+        "total": {
+          "type": "integer"
+        },
+        "available": {
+          "type": "integer"
+        },
+        "bound": {
+          "type": "integer"
+        },
+        "released": {
+          "type": "integer"
+        },
+        "failed": {
+          "type": "integer"
+        },
+        "capacity": {
+          "type": "string"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "total",
+        "available",
+        "bound",
+        "released",
+        "failed",
+        "capacity"
+      ]
+    },
+    "PodSecurity": {
+      "properties": {
+        "namespaces": {
+          "items": {
+            "$ref": "#/$defs/NamespaceSecurity"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "namespaces"
+      ]
+    },
+    "PodSecurityAdmission": {
+      "properties": {
+        "enforce": {
+          "type": "string"
+        },
+        "enforce_version": {
+          "type": "string"
+        },
+        "audit": {
+          "type": "string"
+        },
+        "audit_version": {
+          "type": "string"
+        },
+        "warn": {
+          "type": "string"
+        },
+        "warn_version": {
+          "type": "string"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object"
+    },
+    "PolicyRule": {
+      "properties": {
+        "api_groups": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "resources": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "resource_names": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "non_resource_urls": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "verbs": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "verbs"
+      ]
+    },
+    "PullSecret": {
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "namespace": {
+          "type": "string"
+        },
+        "type": {
+          "type": "string"
+        },
+        "registries": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "missing": {
+          "type": "boolean"
+        },
+        "service_accounts": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "workloads": {
+          "items": {
+            "$ref": "#/$defs/PullSecretUser"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name",
+        "namespace"
+      ]
+    },
+    "PullSecretUser": {
+      "properties": {
+        "kind": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        },
+        "namespace": {
+          "type": "string"
+        },
+        "via": {
+          "type": "string"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "kind",
+        "name",
+        "namespace",
+        "via"
+      ]
+    },
+    "PullSecrets": {
+      "properties": {
+        "secrets": {
+          "items": {
+            "$ref": "#/$defs/PullSecret"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "secrets"
+      ]
+    },
+    "RBAC": {
+      "properties": {
+        "cluster_roles": {
+          "items": {
+            "$ref": "#/$defs/Role"
+          },
+          "type": "array"
+        },
+        "roles": {
+          "items": {
+            "$ref": "#/$defs/Role"
+          },
+          "type": "array"
+        },
+        "bindings": {
+          "items": {
+            "$ref": "#/$defs/RoleBinding"
+          },
+          "type": "array"
+        },
+        "subjects": {
+          "items": {
+            "$ref": "#/$defs/SubjectAccess"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "cluster_roles",
+        "roles",
+        "bindings",
+        "subjects"
+      ]
+    },
+    "RegistrySummary": {
+      "properties": {
+        "registry": {
+          "type": "string"
+        },
+        "images": {
+          "type": "integer"
+        },
+        "repositories": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "registry",
+        "images",
+        "repositories"
+      ]
+    },
+    "Resource": {
+      "properties": {
+        "kind": {
+          "type": "string"
+        },
+        "api_version": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        },
+        "namespace": {
+          "type": "string"
+        },
+        "additional_properties": {
+          "additionalProperties": {
+            "type": "string"
+          },
+          "type": "object"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name"
+      ]
+    },
+    "ResourceList": {
+      "properties": {
+        "kind": {
+          "type": "string"
+        },
+        "api_version": {
+          "type": "string"
+        },
+        "namespaced": {
+          "type": "boolean"
+        },
+        "count": {
+          "type": "integer"
+        },
+        "resources": {
+          "items": {
+            "$ref": "#/$defs/Resource"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "kind",
+        "api_version",
+        "namespaced",
+        "count"
+      ]
+    },
+    "Role": {
+      "properties": {
+        "kind": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        },
+        "namespace": {
+          "type": "string"
+        },
+        "rules": {
+          "items": {
+            "$ref": "#/$defs/PolicyRule"
+          },
+          "type": "array"
+        },
+        "risks": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "kind",
+        "name",
+        "rules"
+      ]
+    },
+    "RoleBinding": {
+      "properties": {
+        "kind": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        },
+        "namespace": {
+          "type": "string"
+        },
+        "role_ref": {
+          "$ref": "#/$defs/RoleRef"
+        },
+        "subjects": {
+          "items": {
+            "$ref": "#/$defs/Subject"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "kind",
+        "name",
+        "role_ref",
+        "subjects"
+      ]
+    },
+    "RoleRef": {
+      "properties": {
+        "kind": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "kind",
+        "name"
+      ]
+    },
+    "Route": {
+      "properties": {
+        "kind": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        },
+        "namespace": {
+          "type": "string"
+        },
+        "hostnames": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "gateways": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "backends": {
+          "items": {
+            "$ref": "#/$defs/Backend"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "kind",
+        "name",
+        "namespace",
+        "gateways",
+        "backends"
+      ]
+    },
+    "SecuritySummary": {
+      "properties": {
+        "workloads": {
+          "type": "integer"
+        },
+        "privileged": {
+          "type": "integer"
+        },
+        "host_namespaces": {
+          "type": "integer"
+        },
+        "host_path": {
+          "type": "integer"
+        },
+        "added_capabilities": {
+          "type": "integer"
+        },
+        "run_as_root": {
+          "type": "integer"
+        },
+        "writable_root_filesystem": {
+          "type": "integer"
+        },
+        "seccomp_unconfined": {
+          "type": "integer"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "workloads",
+        "privileged",
+        "host_namespaces",
+        "host_path",
+        "added_capabilities",
+        "run_as_root",
+        "writable_root_filesystem",
+        "seccomp_unconfined"
+      ]
+    },
+    "ServiceMesh": {
+      "properties": {
+        "meshes": {
+          "items": {
+            "$ref": "#/$defs/Mesh"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "meshes"
+      ]
+    },
+    "ServicePort": {
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "protocol": {
+          "type": "string"
+        },
+        "port": {
+          "type": "integer"
+        },
+        "node_port": {
+          "type": "integer"
+        },
+        "target_port": {
+          "type": "string"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "protocol",
+        "port"
+      ]
+    },
+    "ServiceReference": {
+      "properties": {
+        "namespace": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        },
+        "path": {
+          "type": "string"
+        },
+        "port": {
+          "type": "integer"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "namespace",
+        "name"
+      ]
+    },
+    "SidecarProxy": {
+      "properties": {
+        "version": {
+          "type": "string"
+        },
+        "image": {
+          "type": "string"
+        },
+        "pods": {
+          "type": "integer"
+        },
+        "workloads": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "outdated": {
+          "type": "boolean"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "version",
+        "image",
+        "pods",
+        "workloads",
+        "outdated"
+      ]
+    },
+    "Software": {
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "version": {
+          "type": "string"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name"
+      ]
+    },
+    "Storage": {
+      "properties": {
+        "csi_drivers": {
+          "items": {
+            "$ref": "#/$defs/CSIDriver"
+          },
+          "type": "array"
+        },
+        "storage_classes": {
+          "items": {
+            "$ref": "#/$defs/StorageClass"
+          },
+          "type": "array"
+        },
+        "persistent_volumes": {
+          "$ref": "#/$defs/PersistentVolumeSummary"
+        },
+        "claims": {
+          "items": {
+            "$ref": "#/$defs/ClaimSummary"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "csi_drivers",
+        "storage_classes",
+        "persistent_volumes",
+        "claims"
+      ]
+    },
+    "StorageClass": {
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "provisioner": {
+          "type": "string"
+        },
+        "default": {
+          "type": "boolean"
+        },
+        "reclaim_policy": {
+          "type": "string"
+        },
+        "volume_binding_mode": {
+          "type": "string"
+        },
+        "allow_volume_expansion": {
+          "type": "boolean"
+        },
+        "parameters": {
+          "additionalProperties": {
+            "type": "string"
+          },
+          "type": "object"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name",
+        "provisioner",
+        "default",
+        "reclaim_policy",
+        "volume_binding_mode",
+        "allow_volume_expansion"
+      ]
+    },
+    "Subject": {
+      "properties": {
+        "kind": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        },
+        "namespace": {
+          "type": "string"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "kind",
+        "name"
+      ]
+    },
+    "SubjectAccess": {
+      "properties": {
+        "kind": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        },
+        "namespace": {
+          "type": "string"
+        },
+        "grants": {
+          "items": {
+            "$ref": "#/$defs/Grant"
+          },
+          "type": "array"
+        },
+        "risks": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "kind",
+        "name",
+        "grants"
+      ]
+    },
+    "TLSConfig": {
+      "properties": {
+        "hosts": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "secret_name": {
+          "type": "string"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "secret_name"
+      ]
+    },
+    "Tool": {
+      "properties": {
+        "vendor": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        },
+        "build_time": {
+          "type": "string"
+        },
+        "version": {
+          "type": "string"
+        },
+        "commit": {
+          "type": "string"
+        },
+        "commit_time": {
+          "type": "string"
+        }
+      },
       "additionalProperties": false,
       "type": "object",
       "required": [
@@ -364,6 +1957,258 @@ var expectedSchema = `{
         "commit",
         "commit_time"
       ]
+    },
+    "ValueCount": {
+      "properties": {
+        "value": {
+          "type": "string"
+        },
+        "count": {
+          "type": "integer"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "value",
+        "count"
+      ]
+    },
+    "Webhook": {
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "service": {
+          "$ref": "#/$defs/ServiceReference"
+        },
+        "url": {
+          "type": "string"
+        },
+        "failure_policy": {
+          "type": "string"
+        },
+        "timeout_seconds": {
+          "type": "integer"
+        },
+        "side_effects": {
+          "type": "string"
+        },
+        "namespace_selector": {
+          "type": "string"
+        },
+        "object_selector": {
+          "type": "string"
+        },
+        "rules": {
+          "items": {
+            "$ref": "#/$defs/AdmissionRule"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name",
+        "failure_policy",
+        "timeout_seconds",
+        "rules"
+      ]
+    },
+    "WebhookConfiguration": {
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "webhooks": {
+          "items": {
+            "$ref": "#/$defs/Webhook"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name",
+        "webhooks"
+      ]
+    },
+    "Workload": {
+      "properties": {
+        "kind": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        },
+        "namespace": {
+          "type": "string"
+        },
+        "replicas": {
+          "type": "integer"
+        },
+        "selector": {
+          "type": "string"
+        },
+        "schedule": {
+          "type": "string"
+        },
+        "service_account": {
+          "type": "string"
+        },
+        "containers": {
+          "items": {
+            "$ref": "#/$defs/WorkloadContainer"
+          },
+          "type": "array"
+        },
+        "owners": {
+          "items": {
+            "$ref": "#/$defs/Owner"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "kind",
+        "name",
+        "namespace",
+        "service_account",
+        "containers"
+      ]
+    },
+    "WorkloadContainer": {
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "image": {
+          "type": "string"
+        },
+        "init": {
+          "type": "boolean"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "name",
+        "image"
+      ]
+    },
+    "WorkloadRef": {
+      "properties": {
+        "kind": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        },
+        "namespace": {
+          "type": "string"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "kind",
+        "name",
+        "namespace"
+      ]
+    },
+    "WorkloadSecurity": {
+      "properties": {
+        "kind": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        },
+        "namespace": {
+          "type": "string"
+        },
+        "privileged": {
+          "type": "boolean"
+        },
+        "host_network": {
+          "type": "boolean"
+        },
+        "host_pid": {
+          "type": "boolean"
+        },
+        "host_ipc": {
+          "type": "boolean"
+        },
+        "host_path_mounts": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "added_capabilities": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "run_as_non_root": {
+          "type": "boolean"
+        },
+        "read_only_root_filesystem": {
+          "type": "boolean"
+        },
+        "seccomp_profiles": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        },
+        "apparmor_profiles": {
+          "items": {
+            "type": "string"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "kind",
+        "name",
+        "namespace",
+        "privileged",
+        "host_network",
+        "host_pid",
+        "host_ipc",
+        "run_as_non_root",
+        "read_only_root_filesystem"
+      ]
+    },
+    "Workloads": {
+      "properties": {
+        "counts": {
+          "additionalProperties": {
+            "type": "integer"
+          },
+          "type": "object"
+        },
+        "items": {
+          "items": {
+            "$ref": "#/$defs/Workload"
+          },
+          "type": "array"
+        }
+      },
+      "additionalProperties": false,
+      "type": "object",
+      "required": [
+        "counts",
+        "items"
+      ]
     }
   }
 }
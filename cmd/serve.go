@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/rad-security/kbom/internal/kube"
+	"github.com/rad-security/kbom/internal/model"
+	"github.com/rad-security/kbom/internal/utils"
+)
+
+const (
+	shutdownTimeout   = 10 * time.Second
+	readHeaderTimeout = 10 * time.Second
+)
+
+var (
+	listenAddress string
+	cacheTTL      time.Duration
+	tokenFile     string
+	tlsCertFile   string
+	tlsKeyFile    string
+)
+
+var ServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the KBOM of the cluster over HTTP",
+	Long: `Serve the KBOM on GET /kbom (with ?format= any of the generate formats) and a short
+summary on GET /kbom/summary, collection metrics on GET /metrics, plus /healthz and /readyz probes. KBOMs are generated on request
+and cached for --cache-ttl. With --token-file the KBOM endpoints require that bearer token,
+with --tls-cert-file and --tls-key-file the server uses TLS.`,
+	RunE: runServe,
+}
+
+func init() {
+	ServeCmd.Flags().StringVar(&listenAddress, "address", ":8080", "Address to listen on")
+	ServeCmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 5*time.Minute,
+		"How long a generated KBOM is served from the cache, 0 disables caching")
+	ServeCmd.Flags().StringVar(&tokenFile, "token-file", "", "Path to a file with the bearer token required by the KBOM endpoints")
+	ServeCmd.Flags().StringVar(&tlsCertFile, "tls-cert-file", "", "Path to the TLS certificate")
+	ServeCmd.Flags().StringVar(&tlsKeyFile, "tls-key-file", "", "Path to the TLS private key")
+	ServeCmd.Flags().BoolVar(&short, "short", false, "Short - only include metadata, nodes, images and resources counters")
+	ServeCmd.Flags().StringSliceVar(&include, "include", nil, includeUsage())
+	ServeCmd.Flags().StringSliceVar(&exclude, "exclude", nil, excludeUsage)
+	ServeCmd.Flags().StringVar(&nodePoolLabel, "node-pool-label", "",
+		"Fallback node label to group node pools by (with --include nodepools)")
+	ServeCmd.Flags().StringVar(&vulnDBPath, "vuln-db", "", "Path to a local directory with OSV advisories to match against")
+	ServeCmd.Flags().StringSliceVar(&vexPaths, "vex", nil, "Paths to OpenVEX documents to apply to the findings")
+	addRedactFlags(ServeCmd)
+
+	utils.BindFlags(ServeCmd)
+}
+
+func runServe(cmd *cobra.Command, _ []string) error {
+	if (tlsCertFile == "") != (tlsKeyFile == "") {
+		return errors.New("both --tls-cert-file and --tls-key-file are required for TLS")
+	}
+
+	enabled, err := enabledCollectors(include, exclude)
+	if err != nil {
+		return err
+	}
+
+	if redactor, err = newRedactor(); err != nil {
+		return err
+	}
+
+	var token string
+	if tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read token file: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+		if token == "" {
+			return fmt.Errorf("token file %s is empty", tokenFile)
+		}
+	}
+
+	k8sClient, err := kube.NewClient(k8sContext)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:              listenAddress,
+		Handler:           newKBOMServer(k8sClient, enabled, cacheTTL, token).routes(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Info().Str("address", listenAddress).Bool("tls", tlsCertFile != "").Bool("auth", token != "").Msg("Serving KBOM")
+		if tlsCertFile != "" {
+			errs <- srv.ListenAndServeTLS(tlsCertFile, tlsKeyFile)
+		} else {
+			errs <- srv.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return srv.Shutdown(shutdownCtx)
+}
+
+// kbomServer generates the KBOM on request, serving it from the cache while it is younger than ttl
+type kbomServer struct {
+	k8sClient kube.K8sClient
+	enabled   []collector
+	ttl       time.Duration
+	token     string
+
+	mu     sync.Mutex
+	cached *model.KBOM
+}
+
+// kbomSummary is the response of /kbom/summary
+type kbomSummary struct {
+	ID          string          `json:"id"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	Cluster     string          `json:"cluster"`
+	K8sVersion  string          `json:"k8s_version"`
+	Location    *model.Location `json:"location,omitempty"`
+	Nodes       int             `json:"nodes"`
+	Images      int             `json:"images"`
+	Resources   int             `json:"resources"`
+	Findings    int             `json:"findings"`
+}
+
+func newKBOMServer(k8sClient kube.K8sClient, enabled []collector, ttl time.Duration, token string) *kbomServer {
+	return &kbomServer{k8sClient: k8sClient, enabled: enabled, ttl: ttl, token: token}
+}
+
+func (s *kbomServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /kbom", s.authenticated(s.handleKBOM))
+	mux.HandleFunc("GET /kbom/summary", s.authenticated(s.handleSummary))
+	mux.HandleFunc("GET /metrics", s.authenticated(collectionMetrics.Handler().ServeHTTP))
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("GET /readyz", s.handleReady)
+
+	return mux
+}
+
+func (s *kbomServer) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	if s.token == "" {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// kbom returns the cached KBOM or generates a new one. Concurrent requests wait for the same generation.
+func (s *kbomServer) kbom(ctx context.Context) (*model.KBOM, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cached != nil && s.ttl > 0 && time.Since(s.cached.GeneratedAt) < s.ttl {
+		return s.cached, nil
+	}
+
+	kbom, err := buildKBOM(ctx, s.k8sClient, uuid.New().String(), time.Now(), s.enabled, FailOnCritical)
+	if err != nil {
+		return nil, err
+	}
+	s.cached = kbom
+
+	return kbom, nil
+}
+
+func (s *kbomServer) handleKBOM(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name = JSONFormat.Name
+	}
+
+	f, err := formatFromName(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	kbom, err := s.kbom(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to generate KBOM")
+		http.Error(w, "failed to generate KBOM", http.StatusInternalServerError)
+		return
+	}
+
+	// encode first, so an encoding error can still be reported with a proper status
+	var buf bytes.Buffer
+	if err := printKBOM(&buf, kbom, f); err != nil {
+		log.Error().Err(err).Msg("Failed to encode KBOM")
+		http.Error(w, "failed to encode KBOM", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", f.ContentType)
+	_, _ = w.Write(buf.Bytes())
+}
+
+func (s *kbomServer) handleSummary(w http.ResponseWriter, r *http.Request) {
+	kbom, err := s.kbom(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to generate KBOM")
+		http.Error(w, "failed to generate KBOM", http.StatusInternalServerError)
+		return
+	}
+
+	summary := kbomSummary{
+		ID:          kbom.ID,
+		GeneratedAt: kbom.GeneratedAt,
+		Cluster:     kbom.Cluster.Name,
+		K8sVersion:  kbom.Cluster.K8sVersion,
+		Location:    kbom.Cluster.Location,
+		Nodes:       kbom.Cluster.NodesCount,
+		Images:      len(kbom.Cluster.Components.Images),
+		Findings:    len(kbom.Findings),
+	}
+	for _, r := range kbom.Cluster.Components.Resources {
+		summary.Resources += r.ResourcesCount
+	}
+
+	w.Header().Set("Content-Type", JSONFormat.ContentType)
+	_ = json.NewEncoder(w).Encode(summary)
+}
+
+// handleReady reports ready when the API server can be reached
+func (s *kbomServer) handleReady(w http.ResponseWriter, r *http.Request) {
+	if _, _, err := s.k8sClient.Metadata(r.Context()); err != nil {
+		log.Debug().Err(err).Msg("API server is not reachable")
+		http.Error(w, "kubernetes API is not reachable", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+func TestServe(t *testing.T) {
+	builds := 0
+	client := &mockedK8sClient{
+		allImages: func(context.Context) ([]model.Image, error) {
+			builds++
+			return []model.Image{{FullName: "nginx:1.25", Name: "docker.io/library/nginx", Version: "1.25", Registry: "docker.io"}}, nil
+		},
+		allResources: func(context.Context, bool) (map[string]model.ResourceList, error) {
+			return map[string]model.ResourceList{"v1, Resource=pods": {Kind: "Pod", ResourcesCount: 3}}, nil
+		},
+	}
+
+	srv := httptest.NewServer(newKBOMServer(client, nil, time.Minute, "s3cret").routes())
+	defer srv.Close()
+
+	get := func(path, token string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	assert.Equal(t, http.StatusOK, get("/healthz", "").StatusCode)
+	assert.Equal(t, http.StatusOK, get("/readyz", "").StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, get("/kbom", "").StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, get("/kbom", "wrong").StatusCode)
+	assert.Equal(t, http.StatusBadRequest, get("/kbom?format=pdf", "s3cret").StatusCode)
+
+	resp := get("/kbom?format=cyclonedx-json", "s3cret")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, CycloneDXJsonFormat.ContentType, resp.Header.Get("Content-Type"))
+	bom := map[string]interface{}{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&bom))
+	assert.Equal(t, "CycloneDX", bom["bomFormat"])
+
+	resp = get("/kbom/summary", "s3cret")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	summary := kbomSummary{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&summary))
+	assert.Equal(t, "test-cluster", summary.Cluster)
+	assert.Equal(t, "1.25.1", summary.K8sVersion)
+	assert.Equal(t, 1, summary.Images)
+	assert.Equal(t, 3, summary.Resources)
+
+	assert.Equal(t, 1, builds, "the second request is served from the cache")
+
+	assert.Equal(t, http.StatusUnauthorized, get("/metrics", "").StatusCode)
+	metrics, err := io.ReadAll(get("/metrics", "s3cret").Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(metrics), `kbom_images{registry="docker.io"} 1`)
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/kbom", strings.NewReader(""))
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestServeNoCache(t *testing.T) {
+	builds := 0
+	apiDown := false
+	client := &mockedK8sClient{
+		allImages: func(context.Context) ([]model.Image, error) {
+			builds++
+			return nil, nil
+		},
+		metadata: func(context.Context) (string, string, error) {
+			if apiDown {
+				return "", "", errors.New("api unavailable")
+			}
+			return "1.25.1", mockCACert, nil
+		},
+	}
+
+	handler := newKBOMServer(client, nil, 0, "").routes()
+	serve := func(path string) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/kbom"))
+	assert.Equal(t, http.StatusOK, serve("/kbom?format=yaml"))
+	assert.Equal(t, 2, builds, "without a TTL every request generates a new KBOM")
+
+	apiDown = true
+	assert.Equal(t, http.StatusServiceUnavailable, serve("/readyz"))
+	assert.Equal(t, http.StatusInternalServerError, serve("/kbom"))
+}
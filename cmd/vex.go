@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/rad-security/kbom/internal/config"
+	"github.com/rad-security/kbom/internal/utils"
+	"github.com/rad-security/kbom/internal/vex"
+)
+
+var vexAuthor string
+
+var vexCmd = &cobra.Command{
+	Use:   "vex <kbom.json>",
+	Short: "Print an OpenVEX document skeleton for the KBOM components",
+	Long: `Print an OpenVEX document with products keyed by the cluster and image package URLs of the KBOM.
+Findings already attached to the KBOM become statements, so triage decisions can be recorded
+and applied back with --vex. Use "-" to read the KBOM from stdin.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runVEX,
+}
+
+func init() {
+	vexCmd.Flags().StringVar(&vexAuthor, "author", Company, "Author of the OpenVEX document")
+
+	utils.BindFlags(vexCmd)
+}
+
+func runVEX(cmd *cobra.Command, args []string) error {
+	kbom, err := readKBOM(args[0])
+	if err != nil {
+		return err
+	}
+
+	doc := vex.Skeleton(kbom, uuid.New().URN(), vexAuthor, config.AppName+"/"+config.AppVersion, generatedAt)
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(doc)
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rad-security/kbom/internal/vex"
+)
+
+func TestRunVEX(t *testing.T) {
+	in = strings.NewReader(`{"id": "00000001", "cluster": {"name": "test-cluster", "k8s_version": "1.25.1",
+		"components": {"images": [{"full_name": "nginx:1.25", "name": "docker.io/library/nginx", "version": "1.25"}]}}}`)
+	defer func() { in = os.Stdin }()
+
+	mock := &stdoutMock{buf: bytes.Buffer{}}
+	out = mock
+
+	require.NoError(t, runVEX(nil, []string{stdinPath}))
+
+	doc := &vex.Document{}
+	require.NoError(t, json.Unmarshal(mock.buf.Bytes(), doc))
+	assert.Equal(t, vex.Context, doc.Context)
+	assert.Equal(t, Company, doc.Author)
+	require.Len(t, doc.Statements, 1)
+	assert.Equal(t, []vex.Product{
+		{ID: "pkg:k8s/k8s.io%2Fkubernetes@1.25.1"},
+		{ID: "pkg:oci/nginx?repository_url=docker.io%2Flibrary%2Fnginx&tag=1.25"},
+	}, doc.Statements[0].Products)
+}
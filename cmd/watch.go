@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/rad-security/kbom/internal/jsonpatch"
+	"github.com/rad-security/kbom/internal/kube"
+	"github.com/rad-security/kbom/internal/utils"
+)
+
+const (
+	emitDocument = "document"
+	emitPatch    = "patch"
+)
+
+var (
+	watchInterval time.Duration
+	emit          string
+)
+
+var WatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Keep the KBOM up to date from informer caches and print it on every change",
+	Long: `Keep nodes, pods and all listable resources in shared informer caches instead of listing the
+cluster on every run. After a change, and at most once every --interval, the KBOM is rebuilt and
+printed as one JSON line: the whole document, or with --emit patch an RFC 6902 JSON patch against
+the previous one. The first line is always the whole document. Optional --include sections are
+collected again on every rebuild.`,
+	RunE: runWatch,
+}
+
+func init() {
+	WatchCmd.Flags().DurationVar(&watchInterval, "interval", 30*time.Second, "Minimum interval between two emitted updates")
+	WatchCmd.Flags().StringVar(&emit, "emit", emitDocument, fmt.Sprintf("What to print on changes (%s, %s)", emitDocument, emitPatch))
+	WatchCmd.Flags().StringVar(&metricsAddress, "metrics-address", "", "Address to serve Prometheus metrics on, e.g. :9090")
+	WatchCmd.Flags().BoolVar(&short, "short", false, "Short - only include metadata, nodes, images and resources counters")
+	WatchCmd.Flags().StringSliceVar(&include, "include", nil, includeUsage())
+	WatchCmd.Flags().StringSliceVar(&exclude, "exclude", nil, excludeUsage)
+	WatchCmd.Flags().StringVar(&nodePoolLabel, "node-pool-label", "",
+		"Fallback node label to group node pools by (with --include nodepools)")
+	addRedactFlags(WatchCmd)
+
+	utils.BindFlags(WatchCmd)
+}
+
+func runWatch(cmd *cobra.Command, _ []string) error {
+	if emit != emitDocument && emit != emitPatch {
+		return fmt.Errorf("emit %q is not supported, use one of: %s, %s", emit, emitDocument, emitPatch)
+	}
+
+	enabled, err := enabledCollectors(include, exclude)
+	if err != nil {
+		return err
+	}
+
+	if redactor, err = newRedactor(); err != nil {
+		return err
+	}
+
+	cfg, currentK8sContext, err := kube.RestConfig(k8sContext)
+	if err != nil {
+		return err
+	}
+
+	k8sClient, err := kube.NewClientForConfig(cfg, currentK8sContext)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := kube.NewWatcher(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveMetrics(ctx, metricsAddress)
+
+	if err := watcher.Start(ctx); err != nil {
+		return err
+	}
+
+	return watch(ctx, watcher.Client(k8sClient), watcher.Changes(), enabled)
+}
+
+// watch prints the KBOM and then an update after every change, until ctx is cancelled
+func watch(ctx context.Context, k8sClient kube.K8sClient, changes <-chan struct{}, enabled []collector) error {
+	enc := json.NewEncoder(out)
+
+	previous, err := buildDocument(ctx, k8sClient, enabled)
+	if err != nil {
+		return err
+	}
+	if err := enc.Encode(json.RawMessage(previous)); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changes:
+		}
+
+		// debounce, every change until the interval passes is part of this update
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(watchInterval):
+		}
+
+		current, err := buildDocument(ctx, k8sClient, enabled)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to rebuild KBOM")
+			continue
+		}
+
+		ops, err := jsonpatch.Create(previous, current)
+		if err != nil {
+			return err
+		}
+		if !inventoryChanged(ops) {
+			continue
+		}
+
+		if emit == emitPatch {
+			err = enc.Encode(ops)
+		} else {
+			err = enc.Encode(json.RawMessage(current))
+		}
+		if err != nil {
+			return err
+		}
+
+		previous = current
+	}
+}
+
+func buildDocument(ctx context.Context, k8sClient kube.K8sClient, enabled []collector) ([]byte, error) {
+	kbom, err := buildKBOM(ctx, k8sClient, kbomID, time.Now(), enabled, FailOnCritical)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(kbom)
+}
+
+// inventoryChanged reports whether the patch changes more than the generation time
+func inventoryChanged(ops []jsonpatch.Operation) bool {
+	for _, op := range ops {
+		if op.Path != "/generated_at" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rad-security/kbom/internal/jsonpatch"
+	"github.com/rad-security/kbom/internal/model"
+)
+
+func TestWatch(t *testing.T) {
+	mock := &stdoutMock{buf: bytes.Buffer{}}
+	out = mock
+	emit = emitPatch
+	watchInterval = time.Millisecond
+	defer func() { emit = emitDocument }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the first change updates the image, the second one only touches the generation time
+	builds := 0
+	client := &mockedK8sClient{
+		allImages: func(context.Context) ([]model.Image, error) {
+			builds++
+			if builds == 1 {
+				return []model.Image{{FullName: "nginx:1.24", Name: "docker.io/library/nginx", Version: "1.24"}}, nil
+			}
+			if builds == 3 {
+				cancel()
+			}
+			return []model.Image{{FullName: "nginx:1.25", Name: "docker.io/library/nginx", Version: "1.25"}}, nil
+		},
+	}
+
+	changes := make(chan struct{}, 2)
+	changes <- struct{}{}
+	changes <- struct{}{}
+
+	require.NoError(t, watch(ctx, client, changes, nil))
+	assert.Equal(t, 3, builds)
+
+	lines := strings.Split(strings.TrimSpace(mock.buf.String()), "\n")
+	require.Len(t, lines, 2, "an update without inventory changes is not emitted")
+
+	kbom := &model.KBOM{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), kbom))
+	assert.Equal(t, "nginx:1.24", kbom.Cluster.Components.Images[0].FullName)
+
+	var ops []jsonpatch.Operation
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &ops))
+	assert.Contains(t, ops, jsonpatch.Operation{Op: jsonpatch.OpReplace, Path: "/cluster/components/images/0/full_name", Value: "nginx:1.25"})
+	assert.Contains(t, ops, jsonpatch.Operation{Op: jsonpatch.OpReplace, Path: "/cluster/components/images/0/version", Value: "1.25"})
+}
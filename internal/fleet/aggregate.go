@@ -0,0 +1,188 @@
+package fleet
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+const (
+	crdKind   = "CustomResourceDefinition"
+	chartProp = "chart"
+)
+
+// Aggregate merges per-cluster KBOMs into a fleet document listing, for each Kubernetes version,
+// node OS distribution, image digest, CRD and Helm chart, the clusters it was found in.
+// ID, GeneratedAt and GeneratedBy are left to the caller.
+func Aggregate(kboms []*model.KBOM) *model.Fleet {
+	names := clusterNames(kboms)
+
+	versions := presence{}
+	charts := presence{}
+	crds := presence{}
+	nodeOS := make(map[[2]string]*model.FleetNodeOS)
+	images := make(map[[2]string]*model.FleetImage)
+	fleet := &model.Fleet{Clusters: make([]model.FleetMember, 0, len(kboms))}
+	for i, kbom := range kboms {
+		name := names[i]
+		fleet.Clusters = append(fleet.Clusters, model.FleetMember{
+			Name:        name,
+			KBOMID:      kbom.ID,
+			GeneratedAt: kbom.GeneratedAt,
+			K8sVersion:  kbom.Cluster.K8sVersion,
+			Location:    kbom.Cluster.Location,
+			NodesCount:  kbom.Cluster.NodesCount,
+			ImagesCount: len(kbom.Cluster.Components.Images),
+		})
+
+		versions.add(kbom.Cluster.K8sVersion, name)
+
+		for j := range kbom.Cluster.Nodes {
+			osName, osVersion := nodeOSName(&kbom.Cluster.Nodes[j])
+			key := [2]string{osName, osVersion}
+			if nodeOS[key] == nil {
+				nodeOS[key] = &model.FleetNodeOS{Name: osName, Version: osVersion}
+			}
+			nodeOS[key].NodesCount++
+			nodeOS[key].Clusters = appendUnique(nodeOS[key].Clusters, name)
+		}
+
+		for j := range kbom.Cluster.Components.Images {
+			img := &kbom.Cluster.Components.Images[j]
+			for _, digest := range imageDigests(img) {
+				// images without a known digest are told apart by their tag
+				key := [2]string{img.Name, digest}
+				if digest == "" {
+					key[1] = ":" + img.Version
+				}
+				if images[key] == nil {
+					images[key] = &model.FleetImage{Name: img.Name, Digest: digest}
+				}
+				if img.Version != "" {
+					images[key].Tags = appendUnique(images[key].Tags, img.Version)
+				}
+				images[key].Clusters = appendUnique(images[key].Clusters, name)
+			}
+		}
+
+		for _, list := range kbom.Cluster.Components.Resources {
+			for _, res := range list.Resources {
+				if list.Kind == crdKind {
+					crds.add(res.Name, name)
+				}
+				if chart, ok := res.AdditionalProperties[chartProp]; ok {
+					charts.add(chart, name)
+				}
+			}
+		}
+	}
+
+	for _, v := range versions.list() {
+		fleet.K8sVersions = append(fleet.K8sVersions, model.FleetVersion{Version: v.Name, Clusters: v.Clusters})
+	}
+	fleet.NodeOS = sortedValues(nodeOS, func(a, b *model.FleetNodeOS) bool {
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+	fleet.Images = sortedValues(images, func(a, b *model.FleetImage) bool {
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Digest != b.Digest {
+			return a.Digest < b.Digest
+		}
+		return slices.Compare(a.Tags, b.Tags) < 0
+	})
+	fleet.CRDs = crds.list()
+	fleet.HelmCharts = charts.list()
+
+	return fleet
+}
+
+// clusterNames returns the name each KBOM is referred to by in the fleet document,
+// adding the KBOM ID to cluster names that are not unique, e.g. several "kind" clusters
+func clusterNames(kboms []*model.KBOM) []string {
+	count := make(map[string]int, len(kboms))
+	for _, kbom := range kboms {
+		count[kbom.Cluster.Name]++
+	}
+
+	names := make([]string, len(kboms))
+	for i, kbom := range kboms {
+		names[i] = kbom.Cluster.Name
+		if count[kbom.Cluster.Name] > 1 || names[i] == "" {
+			names[i] = fmt.Sprintf("%s (%s)", kbom.Cluster.Name, kbom.ID)
+		}
+	}
+
+	return names
+}
+
+// imageDigests returns the digests the containers of the image run, several when they drifted or run on different
+// platforms, falling back to the image digest
+func imageDigests(img *model.Image) []string {
+	if len(img.RuntimeDigests) > 0 {
+		return img.RuntimeDigests
+	}
+
+	return []string{img.Digest}
+}
+
+// nodeOSName returns the parsed OS distribution of the node, falling back to the raw OS image
+func nodeOSName(n *model.Node) (name, version string) {
+	if n.OS != nil {
+		return n.OS.Name, n.OS.Version
+	}
+
+	return n.OsImage, ""
+}
+
+// presence records the clusters each named item is found in
+type presence map[string][]string
+
+func (p presence) add(item, cluster string) {
+	if item == "" {
+		return
+	}
+
+	p[item] = appendUnique(p[item], cluster)
+}
+
+func (p presence) list() []model.FleetPresence {
+	items := make([]model.FleetPresence, 0, len(p))
+	for name, clusters := range p {
+		items = append(items, model.FleetPresence{Name: name, Clusters: clusters})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+
+	return items
+}
+
+func sortedValues[K comparable, V any](m map[K]*V, less func(a, b *V) bool) []V {
+	values := make([]V, 0, len(m))
+	for _, v := range m {
+		values = append(values, *v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return less(&values[i], &values[j])
+	})
+
+	return values
+}
+
+func appendUnique(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+
+	values = append(values, value)
+	slices.Sort(values)
+
+	return values
+}
@@ -0,0 +1,96 @@
+package fleet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+func testKBOM(id, name, version string, images []model.Image, nodes []model.Node, resources map[string]model.ResourceList) *model.KBOM {
+	return &model.KBOM{
+		ID: id,
+		Cluster: model.Cluster{
+			Name:       name,
+			K8sVersion: version,
+			NodesCount: len(nodes),
+			Nodes:      nodes,
+			Components: model.Components{Images: images, Resources: resources},
+		},
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	crds := func(names ...string) model.ResourceList {
+		list := model.ResourceList{Kind: crdKind, APIVersion: "apiextensions.k8s.io/v1"}
+		for _, n := range names {
+			list.Resources = append(list.Resources, model.Resource{Name: n})
+		}
+		return list
+	}
+	deployments := model.ResourceList{Kind: "Deployment", APIVersion: "apps/v1", Namespaced: true, Resources: []model.Resource{
+		{Name: "ingress-nginx-controller", Namespace: "ingress-nginx", AdditionalProperties: map[string]string{"chart": "ingress-nginx-4.7.1"}},
+	}}
+	ubuntu := model.Node{Name: "n1", OsImage: "Ubuntu 22.04.3 LTS", OS: &model.Software{Name: "ubuntu", Version: "22.04"}}
+	custom := model.Node{Name: "n2", OsImage: "Custom OS"}
+	nginx := model.Image{Name: "docker.io/library/nginx", Version: "1.25", Digest: "sha256:aaa"}
+	nginxLatest := model.Image{Name: "docker.io/library/nginx", Version: "latest", Digest: "sha256:aaa"}
+	unpinned := model.Image{Name: "ghcr.io/org/app", Version: "v1"}
+
+	fleet := Aggregate([]*model.KBOM{
+		testKBOM("id-1", "prod", "1.28.3", []model.Image{nginx, unpinned}, []model.Node{ubuntu, ubuntu},
+			map[string]model.ResourceList{"crds": crds("certificates.cert-manager.io"), "deployments": deployments}),
+		testKBOM("id-2", "kind", "1.29.0", []model.Image{nginxLatest}, []model.Node{custom},
+			map[string]model.ResourceList{"crds": crds("certificates.cert-manager.io", "widgets.example.com")}),
+		testKBOM("id-3", "kind", "1.29.0", nil, []model.Node{ubuntu}, nil),
+	})
+
+	assert.Equal(t, []string{"prod", "kind (id-2)", "kind (id-3)"},
+		[]string{fleet.Clusters[0].Name, fleet.Clusters[1].Name, fleet.Clusters[2].Name})
+	assert.Equal(t, 2, fleet.Clusters[0].ImagesCount)
+	assert.Equal(t, []model.FleetVersion{
+		{Version: "1.28.3", Clusters: []string{"prod"}},
+		{Version: "1.29.0", Clusters: []string{"kind (id-2)", "kind (id-3)"}},
+	}, fleet.K8sVersions)
+	assert.Equal(t, []model.FleetNodeOS{
+		{Name: "Custom OS", NodesCount: 1, Clusters: []string{"kind (id-2)"}},
+		{Name: "ubuntu", Version: "22.04", NodesCount: 3, Clusters: []string{"kind (id-3)", "prod"}},
+	}, fleet.NodeOS)
+	assert.Equal(t, []model.FleetImage{
+		{Name: "docker.io/library/nginx", Digest: "sha256:aaa", Tags: []string{"1.25", "latest"}, Clusters: []string{"kind (id-2)", "prod"}},
+		{Name: "ghcr.io/org/app", Tags: []string{"v1"}, Clusters: []string{"prod"}},
+	}, fleet.Images)
+	assert.Equal(t, []model.FleetPresence{
+		{Name: "certificates.cert-manager.io", Clusters: []string{"kind (id-2)", "prod"}},
+		{Name: "widgets.example.com", Clusters: []string{"kind (id-2)"}},
+	}, fleet.CRDs)
+	assert.Equal(t, []model.FleetPresence{{Name: "ingress-nginx-4.7.1", Clusters: []string{"prod"}}}, fleet.HelmCharts)
+}
+
+func TestAggregateImagesWithoutDigest(t *testing.T) {
+	fleet := Aggregate([]*model.KBOM{
+		testKBOM("id-1", "a", "1.29.0", []model.Image{{Name: "app", Version: "v1"}, {Name: "app", Version: "v2"}}, nil, nil),
+		testKBOM("id-2", "b", "1.29.0", []model.Image{{Name: "app", Version: "v1"}}, nil, nil),
+	})
+
+	assert.Equal(t, []model.FleetImage{
+		{Name: "app", Tags: []string{"v1"}, Clusters: []string{"a", "b"}},
+		{Name: "app", Tags: []string{"v2"}, Clusters: []string{"a"}},
+	}, fleet.Images)
+}
+
+func TestAggregateImageRuntimeDigests(t *testing.T) {
+	drifted := model.Image{
+		Name: "app", Version: "v1", Digest: "sha256:bbb", RuntimeDigests: []string{"sha256:aaa", "sha256:bbb"}, DigestDrift: true,
+	}
+	fleet := Aggregate([]*model.KBOM{
+		testKBOM("id-1", "a", "1.29.0", []model.Image{drifted}, nil, nil),
+		testKBOM("id-2", "b", "1.29.0", []model.Image{{Name: "app", Version: "v1", Digest: "sha256:aaa"}}, nil, nil),
+	})
+
+	assert.Equal(t, []model.FleetImage{
+		{Name: "app", Digest: "sha256:aaa", Tags: []string{"v1"}, Clusters: []string{"a", "b"}},
+		{Name: "app", Digest: "sha256:bbb", Tags: []string{"v1"}, Clusters: []string{"a"}},
+	}, fleet.Images, "every digest the containers run is listed")
+}
@@ -0,0 +1,109 @@
+// Package jsonpatch creates RFC 6902 JSON patches between two JSON documents
+package jsonpatch
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+type Operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON leaves out the value of remove operations, the value of the others is kept even when null
+func (o Operation) MarshalJSON() ([]byte, error) {
+	if o.Op == OpRemove {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{o.Op, o.Path})
+	}
+
+	type operation Operation
+	return json.Marshal(operation(o))
+}
+
+// Create returns the operations turning the original document into the modified one.
+// Objects are compared key by key and arrays of the same length element by element,
+// arrays whose length changed are replaced as a whole.
+func Create(original, modified []byte) ([]Operation, error) {
+	var a, b interface{}
+	if err := json.Unmarshal(original, &a); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(modified, &b); err != nil {
+		return nil, err
+	}
+
+	return diff("", a, b, nil), nil
+}
+
+func diff(path string, a, b interface{}, ops []Operation) []Operation {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		keys := make([]string, 0, len(av)+len(bv))
+		for k := range av {
+			keys = append(keys, k)
+		}
+		for k := range bv {
+			if _, ok := av[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			p := path + "/" + escape(k)
+			aValue, inA := av[k]
+			bValue, inB := bv[k]
+			switch {
+			case !inB:
+				ops = append(ops, Operation{Op: OpRemove, Path: p})
+			case !inA:
+				ops = append(ops, Operation{Op: OpAdd, Path: p, Value: bValue})
+			default:
+				ops = diff(p, aValue, bValue, ops)
+			}
+		}
+
+		return ops
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			break
+		}
+
+		for i := range av {
+			ops = diff(path+"/"+strconv.Itoa(i), av[i], bv[i], ops)
+		}
+
+		return ops
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return ops
+	}
+
+	return append(ops, Operation{Op: OpReplace, Path: path, Value: b})
+}
+
+// escape escapes a key for use in a JSON pointer (RFC 6901)
+func escape(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
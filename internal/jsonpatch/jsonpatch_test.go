@@ -0,0 +1,50 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreate(t *testing.T) {
+	original := `{
+		"generated_at": "2024-01-01T00:00:00Z",
+		"nodes": [{"name": "a", "version": "1.28"}, {"name": "b", "version": "1.28"}],
+		"images": ["nginx:1.24"],
+		"resources": {"apps/v1, Resource=deployments": {"count": 2}, "v1, Resource=pods": {"count": 5}},
+		"removed": true
+	}`
+	modified := `{
+		"generated_at": "2024-01-01T00:01:00Z",
+		"nodes": [{"name": "a", "version": "1.29"}, {"name": "b", "version": "1.28"}],
+		"images": ["nginx:1.24", "nginx:1.25"],
+		"resources": {"apps/v1, Resource=deployments": {"count": 3}, "v1, Resource=pods": {"count": 5}},
+		"location": null
+	}`
+
+	ops, err := Create([]byte(original), []byte(modified))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Operation{
+		{Op: OpReplace, Path: "/generated_at", Value: "2024-01-01T00:01:00Z"},
+		{Op: OpReplace, Path: "/images", Value: []interface{}{"nginx:1.24", "nginx:1.25"}},
+		{Op: OpAdd, Path: "/location", Value: nil},
+		{Op: OpReplace, Path: "/nodes/0/version", Value: "1.29"},
+		{Op: OpRemove, Path: "/removed"},
+		{Op: OpReplace, Path: "/resources/apps~1v1, Resource=deployments/count", Value: float64(3)},
+	}, ops)
+
+	data, err := json.Marshal(ops[2:5])
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"op": "add", "path": "/location", "value": null},
+		{"op": "replace", "path": "/nodes/0/version", "value": "1.29"},
+		{"op": "remove", "path": "/removed"}
+	]`, string(data))
+
+	ops, err = Create([]byte(original), []byte(original))
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+}
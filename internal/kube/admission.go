@@ -0,0 +1,240 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	admissionv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+var policyEngineSignatures = []signature{
+	{name: "kyverno", images: []string{"kyverno/kyverno"}},
+	{name: "gatekeeper", images: []string{"openpolicyagent/gatekeeper"}},
+	{name: "kubewarden", images: []string{"kubewarden/kubewarden-controller"}},
+}
+
+// Admission returns the admission webhooks, ValidatingAdmissionPolicies and detected policy engines
+func (k *k8sDB) Admission(ctx context.Context, podTemplates *PodTemplates) (*model.Admission, error) {
+	validating, err := k.client.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list validating webhook configurations: %w", err)
+	}
+
+	mutating, err := k.client.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mutating webhook configurations: %w", err)
+	}
+
+	policies, err := k.admissionPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := podTemplates.items
+
+	res := &model.Admission{
+		ValidatingWebhooks: make([]model.WebhookConfiguration, 0, len(validating.Items)),
+		MutatingWebhooks:   make([]model.WebhookConfiguration, 0, len(mutating.Items)),
+		Policies:           policies,
+		PolicyEngines:      detectComponents(templates, policyEngineSignatures),
+	}
+
+	for i := range validating.Items {
+		cfg := model.WebhookConfiguration{Name: validating.Items[i].Name, Webhooks: make([]model.Webhook, 0)}
+		for j := range validating.Items[i].Webhooks {
+			w := validating.Items[i].Webhooks[j]
+			cfg.Webhooks = append(cfg.Webhooks, toWebhook(w.Name, &w.ClientConfig, w.FailurePolicy, w.TimeoutSeconds,
+				w.SideEffects, w.NamespaceSelector, w.ObjectSelector, w.Rules))
+		}
+		res.ValidatingWebhooks = append(res.ValidatingWebhooks, cfg)
+	}
+
+	for i := range mutating.Items {
+		cfg := model.WebhookConfiguration{Name: mutating.Items[i].Name, Webhooks: make([]model.Webhook, 0)}
+		for j := range mutating.Items[i].Webhooks {
+			w := mutating.Items[i].Webhooks[j]
+			cfg.Webhooks = append(cfg.Webhooks, toWebhook(w.Name, &w.ClientConfig, w.FailurePolicy, w.TimeoutSeconds,
+				w.SideEffects, w.NamespaceSelector, w.ObjectSelector, w.Rules))
+		}
+		res.MutatingWebhooks = append(res.MutatingWebhooks, cfg)
+	}
+
+	return res, nil
+}
+
+// admissionPolicyVersions are the versions ValidatingAdmissionPolicies are listed in, the GA version first
+var admissionPolicyVersions = []string{"v1", "v1beta1"}
+
+// admissionPolicies returns ValidatingAdmissionPolicies with their bindings from the first served version, or an empty
+// list when no version is served
+func (k *k8sDB) admissionPolicies(ctx context.Context) ([]model.AdmissionPolicy, error) {
+	for _, version := range admissionPolicyVersions {
+		gv := schema.GroupVersion{Group: admissionv1.GroupName, Version: version}
+		policies, err := k.dynamicClient.Resource(gv.WithResource("validatingadmissionpolicies")).List(ctx, metav1.ListOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list validating admission policies: %w", err)
+		}
+
+		bindings, err := k.dynamicClient.Resource(gv.WithResource("validatingadmissionpolicybindings")).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list validating admission policy bindings: %w", err)
+		}
+
+		return toAdmissionPolicies(gv.String(), policies.Items, bindings.Items)
+	}
+
+	return make([]model.AdmissionPolicy, 0), nil
+}
+
+// toAdmissionPolicies converts the policies and bindings of the given API version, the fields read are the same in
+// v1beta1 and v1
+func toAdmissionPolicies(apiVersion string, items, bindingItems []unstructured.Unstructured) ([]model.AdmissionPolicy, error) {
+	policies := make([]admissionv1beta1.ValidatingAdmissionPolicy, len(items))
+	for i := range items {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(items[i].Object, &policies[i]); err != nil {
+			return nil, fmt.Errorf("failed to convert validating admission policy: %w", err)
+		}
+	}
+
+	bindings := make([]admissionv1beta1.ValidatingAdmissionPolicyBinding, len(bindingItems))
+	for i := range bindingItems {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(bindingItems[i].Object, &bindings[i]); err != nil {
+			return nil, fmt.Errorf("failed to convert validating admission policy binding: %w", err)
+		}
+	}
+
+	res := make([]model.AdmissionPolicy, 0, len(policies))
+	for i := range policies {
+		p := policies[i]
+		policy := model.AdmissionPolicy{
+			Name:          p.Name,
+			APIVersion:    apiVersion,
+			FailurePolicy: string(admissionv1beta1.Fail),
+			Validations:   len(p.Spec.Validations),
+			Rules:         make([]model.AdmissionRule, 0),
+			Bindings:      make([]model.AdmissionPolicyBinding, 0),
+		}
+
+		if p.Spec.FailurePolicy != nil {
+			policy.FailurePolicy = string(*p.Spec.FailurePolicy)
+		}
+
+		if p.Spec.ParamKind != nil {
+			policy.ParamKind = fmt.Sprintf("%s/%s", p.Spec.ParamKind.APIVersion, p.Spec.ParamKind.Kind)
+		}
+
+		if m := p.Spec.MatchConstraints; m != nil {
+			policy.NamespaceSelector = formatSelector(m.NamespaceSelector)
+			for _, r := range m.ResourceRules {
+				policy.Rules = append(policy.Rules, toAdmissionRule(r.RuleWithOperations))
+			}
+		}
+
+		for j := range bindings {
+			b := bindings[j]
+			if b.Spec.PolicyName != p.Name {
+				continue
+			}
+
+			binding := model.AdmissionPolicyBinding{Name: b.Name}
+			for _, action := range b.Spec.ValidationActions {
+				binding.ValidationActions = append(binding.ValidationActions, string(action))
+			}
+			if b.Spec.MatchResources != nil {
+				binding.NamespaceSelector = formatSelector(b.Spec.MatchResources.NamespaceSelector)
+			}
+			policy.Bindings = append(policy.Bindings, binding)
+		}
+
+		res = append(res, policy)
+	}
+
+	return res, nil
+}
+
+func toWebhook(name string, clientConfig *admissionv1.WebhookClientConfig, failurePolicy *admissionv1.FailurePolicyType,
+	timeoutSeconds *int32, sideEffects *admissionv1.SideEffectClass, namespaceSelector, objectSelector *metav1.LabelSelector,
+	rules []admissionv1.RuleWithOperations) model.Webhook {
+	webhook := model.Webhook{
+		Name:              name,
+		FailurePolicy:     string(admissionv1.Fail),
+		TimeoutSeconds:    10,
+		NamespaceSelector: formatSelector(namespaceSelector),
+		ObjectSelector:    formatSelector(objectSelector),
+		Rules:             make([]model.AdmissionRule, 0, len(rules)),
+	}
+
+	if clientConfig.Service != nil {
+		webhook.Service = &model.ServiceReference{
+			Namespace: clientConfig.Service.Namespace,
+			Name:      clientConfig.Service.Name,
+		}
+		if clientConfig.Service.Path != nil {
+			webhook.Service.Path = *clientConfig.Service.Path
+		}
+		if clientConfig.Service.Port != nil {
+			webhook.Service.Port = *clientConfig.Service.Port
+		}
+	}
+
+	if clientConfig.URL != nil {
+		webhook.URL = *clientConfig.URL
+	}
+
+	if failurePolicy != nil {
+		webhook.FailurePolicy = string(*failurePolicy)
+	}
+
+	if timeoutSeconds != nil {
+		webhook.TimeoutSeconds = *timeoutSeconds
+	}
+
+	if sideEffects != nil {
+		webhook.SideEffects = string(*sideEffects)
+	}
+
+	for _, r := range rules {
+		webhook.Rules = append(webhook.Rules, toAdmissionRule(r))
+	}
+
+	return webhook
+}
+
+func toAdmissionRule(r admissionv1.RuleWithOperations) model.AdmissionRule {
+	rule := model.AdmissionRule{
+		APIGroups:   r.APIGroups,
+		APIVersions: r.APIVersions,
+		Resources:   r.Resources,
+	}
+
+	for _, op := range r.Operations {
+		rule.Operations = append(rule.Operations, string(op))
+	}
+
+	if r.Scope != nil {
+		rule.Scope = string(*r.Scope)
+	}
+
+	return rule
+}
+
+// formatSelector returns the selector in the kubectl form, empty when it selects everything
+func formatSelector(selector *metav1.LabelSelector) string {
+	s := metav1.FormatLabelSelector(selector)
+	if s == "<none>" {
+		return ""
+	}
+
+	return s
+}
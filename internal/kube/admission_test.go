@@ -0,0 +1,198 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	admissionv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+func TestAdmission(t *testing.T) {
+	ignore := admissionv1.Ignore
+	timeout := int32(5)
+	path := "/validate"
+
+	client := fake.NewSimpleClientset(
+		&admissionv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "kyverno-resource-validating-webhook-cfg"},
+			Webhooks: []admissionv1.ValidatingWebhook{
+				{
+					Name: "validate.kyverno.svc",
+					ClientConfig: admissionv1.WebhookClientConfig{
+						Service: &admissionv1.ServiceReference{Namespace: "kyverno", Name: "kyverno-svc", Path: &path},
+					},
+					FailurePolicy:  &ignore,
+					TimeoutSeconds: &timeout,
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchExpressions: []metav1.LabelSelectorRequirement{
+							{Key: "kubernetes.io/metadata.name", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"kyverno"}},
+						},
+					},
+					Rules: []admissionv1.RuleWithOperations{
+						{
+							Operations: []admissionv1.OperationType{admissionv1.Create, admissionv1.Update},
+							Rule:       admissionv1.Rule{APIGroups: []string{"*"}, APIVersions: []string{"*"}, Resources: []string{"pods"}},
+						},
+					},
+				},
+			},
+		},
+		&admissionv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "external"},
+			Webhooks: []admissionv1.MutatingWebhook{
+				{Name: "mutate.example.com", ClientConfig: admissionv1.WebhookClientConfig{URL: ptr.To("https://example.com")}},
+			},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "kyverno-admission-controller", Namespace: "kyverno"},
+			Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: "kyverno", Image: "ghcr.io/kyverno/kyverno:v1.11.4"}},
+			}}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "gatekeeper-controller-manager",
+				Namespace: "gatekeeper-system",
+				Labels:    map[string]string{"app.kubernetes.io/version": "3.14.0"},
+			},
+			Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: "manager", Image: "openpolicyagent/gatekeeper@sha256:" + testDigest}},
+			}}},
+		},
+	)
+
+	k := &k8sDB{client: client, dynamicClient: admissionPolicyClient(t, "v1")}
+	templates, err := k.PodTemplates(context.Background())
+	require.NoError(t, err)
+	res, err := k.Admission(context.Background(), templates)
+	require.NoError(t, err)
+
+	require.Len(t, res.ValidatingWebhooks, 1)
+	assert.Equal(t, model.Webhook{
+		Name:              "validate.kyverno.svc",
+		Service:           &model.ServiceReference{Namespace: "kyverno", Name: "kyverno-svc", Path: "/validate"},
+		FailurePolicy:     "Ignore",
+		TimeoutSeconds:    5,
+		NamespaceSelector: "kubernetes.io/metadata.name notin (kyverno)",
+		Rules: []model.AdmissionRule{
+			{Operations: []string{"CREATE", "UPDATE"}, APIGroups: []string{"*"}, APIVersions: []string{"*"}, Resources: []string{"pods"}},
+		},
+	}, res.ValidatingWebhooks[0].Webhooks[0])
+
+	require.Len(t, res.MutatingWebhooks, 1)
+	assert.Equal(t, "https://example.com", res.MutatingWebhooks[0].Webhooks[0].URL)
+	assert.Equal(t, "Fail", res.MutatingWebhooks[0].Webhooks[0].FailurePolicy)
+	assert.Equal(t, int32(10), res.MutatingWebhooks[0].Webhooks[0].TimeoutSeconds)
+
+	require.Len(t, res.Policies, 1)
+	assert.Equal(t, "admissionregistration.k8s.io/v1", res.Policies[0].APIVersion)
+	assert.Equal(t, 1, res.Policies[0].Validations)
+	assert.Equal(t, []model.AdmissionPolicyBinding{{Name: "replica-limit-binding", ValidationActions: []string{"Deny"}}},
+		res.Policies[0].Bindings)
+
+	assert.Equal(t, []model.DetectedComponent{
+		{
+			Name:      "gatekeeper",
+			Version:   "3.14.0",
+			Namespace: "gatekeeper-system",
+			Workload:  "Deployment/gatekeeper-controller-manager",
+			Image:     "openpolicyagent/gatekeeper@sha256:" + testDigest,
+		},
+		{
+			Name:      "kyverno",
+			Version:   "v1.11.4",
+			Namespace: "kyverno",
+			Workload:  "Deployment/kyverno-admission-controller",
+			Image:     "ghcr.io/kyverno/kyverno:v1.11.4",
+		},
+	}, res.PolicyEngines)
+}
+
+func TestAdmissionPoliciesFallback(t *testing.T) {
+	k := &k8sDB{dynamicClient: admissionPolicyClient(t, "v1beta1")}
+	policies, err := k.admissionPolicies(context.Background())
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	assert.Equal(t, "admissionregistration.k8s.io/v1beta1", policies[0].APIVersion)
+	assert.Equal(t, "replica-limit", policies[0].Name)
+	assert.Equal(t, "Fail", policies[0].FailurePolicy)
+
+	k = &k8sDB{dynamicClient: admissionPolicyClient(t, "")}
+	policies, err = k.admissionPolicies(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, policies, "no policies are reported when no version is served")
+}
+
+// admissionPolicyClient returns a dynamic client serving a ValidatingAdmissionPolicy and its binding in the given
+// version only, the other versions are not found like on clusters where they are not enabled
+func admissionPolicyClient(t *testing.T, version string) *dynamicfake.FakeDynamicClient {
+	t.Helper()
+
+	listKinds := make(map[schema.GroupVersionResource]string)
+	for _, v := range admissionPolicyVersions {
+		gv := schema.GroupVersion{Group: admissionv1.GroupName, Version: v}
+		listKinds[gv.WithResource("validatingadmissionpolicies")] = "ValidatingAdmissionPolicyList"
+		listKinds[gv.WithResource("validatingadmissionpolicybindings")] = "ValidatingAdmissionPolicyBindingList"
+	}
+
+	var objects []runtime.Object
+	if version != "" {
+		apiVersion := schema.GroupVersion{Group: admissionv1.GroupName, Version: version}.String()
+		objects = append(objects,
+			toUnstructured(t, apiVersion, "ValidatingAdmissionPolicy", &admissionv1beta1.ValidatingAdmissionPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "replica-limit"},
+				Spec: admissionv1beta1.ValidatingAdmissionPolicySpec{
+					Validations: []admissionv1beta1.Validation{{Expression: "object.spec.replicas <= 5"}},
+				},
+			}),
+			toUnstructured(t, apiVersion, "ValidatingAdmissionPolicyBinding", &admissionv1beta1.ValidatingAdmissionPolicyBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "replica-limit-binding"},
+				Spec: admissionv1beta1.ValidatingAdmissionPolicyBindingSpec{
+					PolicyName:        "replica-limit",
+					ValidationActions: []admissionv1beta1.ValidationAction{admissionv1beta1.Deny},
+				},
+			}),
+		)
+	}
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+	client.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetResource().Version == version {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewNotFound(action.GetResource().GroupResource(), "")
+	})
+
+	return client
+}
+
+func toUnstructured(t *testing.T, apiVersion, kind string, obj runtime.Object) *unstructured.Unstructured {
+	t.Helper()
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	require.NoError(t, err)
+
+	u := &unstructured.Unstructured{Object: content}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+
+	return u
+}
+
+const testDigest = "0000000000000000000000000000000000000000000000000000000000000001"
@@ -0,0 +1,83 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/distribution/reference"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+const appVersionLabel = "app.kubernetes.io/version"
+
+// signature recognizes a well-known component by the repository paths of its container images
+type signature struct {
+	name   string
+	images []string
+}
+
+// detectComponents returns components whose signature matches a container image of the workloads.
+// Version is taken from the image tag, falling back to the app.kubernetes.io/version label.
+func detectComponents(templates []podTemplate, signatures []signature) []model.DetectedComponent {
+	seen := make(map[string]bool)
+	detected := make([]model.DetectedComponent, 0)
+	for i := range templates {
+		t := &templates[i]
+		for _, c := range t.spec.Containers {
+			sig, named, ok := matchSignature(c.Image, signatures)
+			if !ok {
+				continue
+			}
+
+			workload := fmt.Sprintf("%s/%s", t.kind, t.meta.Name)
+			key := strings.Join([]string{sig.name, t.meta.Namespace, workload}, "/")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			version := t.meta.Labels[appVersionLabel]
+			if tagged, ok := named.(reference.Tagged); ok {
+				version = tagged.Tag()
+			}
+
+			detected = append(detected, model.DetectedComponent{
+				Name:      sig.name,
+				Version:   version,
+				Namespace: t.meta.Namespace,
+				Workload:  workload,
+				Image:     c.Image,
+			})
+		}
+	}
+
+	sort.SliceStable(detected, func(i, j int) bool {
+		if detected[i].Name != detected[j].Name {
+			return detected[i].Name < detected[j].Name
+		}
+
+		return detected[i].Namespace+detected[i].Workload < detected[j].Namespace+detected[j].Workload
+	})
+
+	return detected
+}
+
+func matchSignature(image string, signatures []signature) (signature, reference.Named, bool) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return signature{}, nil, false
+	}
+
+	path := reference.Path(named)
+	for _, sig := range signatures {
+		for _, img := range sig.images {
+			if path == img || strings.HasSuffix(path, "/"+img) {
+				return sig, named, true
+			}
+		}
+	}
+
+	return signature{}, nil, false
+}
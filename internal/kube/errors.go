@@ -0,0 +1,44 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+// ErrInvalidImage is returned for container image references that can not be parsed
+var ErrInvalidImage = errors.New("invalid image reference")
+
+// NewCollectionError describes err of the given phase, classifying it by the API status or network error behind it
+func NewCollectionError(phase, resource string, err error) model.CollectionError {
+	return model.CollectionError{
+		Phase:    phase,
+		Resource: resource,
+		Class:    ErrorClass(err),
+		Message:  err.Error(),
+	}
+}
+
+// ErrorClass returns the model.ErrorClass* of err
+func ErrorClass(err error) string {
+	var netErr net.Error
+	switch {
+	case apierrors.IsForbidden(err):
+		return model.ErrorClassForbidden
+	case apierrors.IsUnauthorized(err):
+		return model.ErrorClassUnauthorized
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err), errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return model.ErrorClassTimeout
+	case apierrors.IsNotFound(err):
+		return model.ErrorClassNotFound
+	case errors.Is(err, ErrInvalidImage):
+		return model.ErrorClassInvalid
+	default:
+		return model.ErrorClassUnknown
+	}
+}
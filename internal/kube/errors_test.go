@@ -0,0 +1,36 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+func TestErrorClass(t *testing.T) {
+	secrets := schema.GroupResource{Resource: "secrets"}
+	tests := []struct {
+		err   error
+		class string
+	}{
+		{err: apierrors.NewForbidden(secrets, "", errors.New("denied")), class: model.ErrorClassForbidden},
+		{err: fmt.Errorf("failed to list pods: %w", apierrors.NewUnauthorized("expired")), class: model.ErrorClassUnauthorized},
+		{err: apierrors.NewTimeoutError("slow", 1), class: model.ErrorClassTimeout},
+		{err: fmt.Errorf("list: %w", context.DeadlineExceeded), class: model.ErrorClassTimeout},
+		{err: apierrors.NewNotFound(secrets, "x"), class: model.ErrorClassNotFound},
+		{err: fmt.Errorf("%w %q", ErrInvalidImage, "Invalid:Ref"), class: model.ErrorClassInvalid},
+		{err: errors.New("boom"), class: model.ErrorClassUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			assert.Equal(t, tt.class, ErrorClass(tt.err))
+		})
+	}
+}
@@ -3,8 +3,11 @@ package kube
 import (
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/Masterminds/semver"
@@ -14,6 +17,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
@@ -23,6 +27,11 @@ import (
 	"github.com/rad-security/kbom/internal/model"
 )
 
+const (
+	helmChartLabel = "helm.sh/chart"
+	latestTag      = "latest"
+)
+
 type K8sClient interface {
 	ClusterName(ctx context.Context) (string, error)
 	Metadata(ctx context.Context) (string, string, error)
@@ -30,39 +39,106 @@ type K8sClient interface {
 	AllImages(ctx context.Context) ([]model.Image, error)
 	AllNodes(ctx context.Context, full bool) ([]model.Node, error)
 	AllResources(ctx context.Context, full bool) (map[string]model.ResourceList, error)
+	// ResourceListErrors returns the resources AllResources failed to list, keyed like its result. A group version that
+	// failed discovery is keyed with the resource *.
+	ResourceListErrors() map[string]error
+	// ImageErrors returns the containers AllImages skipped because of an invalid image reference, by
+	// namespace/pod/container, and the namespaces whose pods could not be listed, by namespace
+	ImageErrors() map[string]error
+	RBAC(ctx context.Context) (*model.RBAC, error)
+	// PodTemplates lists the workloads once for the sections resolving pods to workloads
+	PodTemplates(ctx context.Context) (*PodTemplates, error)
+	PodSecurity(ctx context.Context, podTemplates *PodTemplates) (*model.PodSecurity, error)
+	Admission(ctx context.Context, podTemplates *PodTemplates) (*model.Admission, error)
+	Network(ctx context.Context, podTemplates *PodTemplates) (*model.Network, error)
+	Storage(ctx context.Context, podTemplates *PodTemplates) (*model.Storage, error)
+	ServiceMesh(ctx context.Context, podTemplates *PodTemplates) (*model.ServiceMesh, error)
+	NodePools(ctx context.Context, fallbackLabel string) ([]model.NodePool, error)
+	Workloads(ctx context.Context, podTemplates *PodTemplates) (*model.Workloads, error)
+	PullSecrets(ctx context.Context, podTemplates *PodTemplates) (*model.PullSecrets, error)
 }
 
 func NewClient(k8sContext string) (K8sClient, error) {
+	cfg, currentK8sContext, err := RestConfig(k8sContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClientForConfig(cfg, currentK8sContext)
+}
+
+// NewContextClient creates the client of a kubeconfig context, even when running in a pod. It is used to generate the
+// KBOMs of several contexts, which must not all resolve to the cluster the pod runs in.
+func NewContextClient(k8sContext string) (K8sClient, error) {
+	cfg, err := kubeConfig(k8sContext).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubernetes client of context %s: %w", k8sContext, err)
+	}
+
+	return NewClientForConfig(cfg, k8sContext)
+}
+
+// inClusterConfig is replaced in tests to fake running in a pod
+var inClusterConfig = rest.InClusterConfig
+
+// RestConfig returns the in-cluster config when running in a pod, the kubeconfig one for k8sContext otherwise,
+// together with the name of the context used
+func RestConfig(k8sContext string) (*rest.Config, string, error) {
+	cfg, err := inClusterConfig()
+	if err == nil {
+		return cfg, k8sContext, nil
+	}
+
+	clientConfig := kubeConfig(k8sContext)
+	rawConfig, err := clientConfig.RawConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get kubernetes out-cluster client: %w", err)
+	}
+
 	currentK8sContext := k8sContext
+	if k8sContext == "" {
+		currentK8sContext = rawConfig.CurrentContext
+	}
 
-	cfg, err := rest.InClusterConfig()
+	cfg, err = clientConfig.ClientConfig()
 	if err != nil {
-		kubeConfigPath := os.Getenv("KUBECONFIG")
-		if kubeConfigPath == "" {
-			kubeConfigPath = os.Getenv("HOME") + "/.kube/config"
-		}
+		return nil, "", fmt.Errorf("failed to get kubernetes out-cluster client: %w", err)
+	}
 
-		clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-			&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeConfigPath},
-			&clientcmd.ConfigOverrides{
-				CurrentContext: k8sContext,
-			})
+	return cfg, currentK8sContext, nil
+}
 
-		rawConfig, err := clientConfig.RawConfig()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get kubernetes out-cluster client: %w", err)
-		}
+// Contexts returns the names of all contexts in the kubeconfig, sorted
+func Contexts() ([]string, error) {
+	rawConfig, err := kubeConfig("").RawConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
 
-		if k8sContext == "" {
-			currentK8sContext = rawConfig.CurrentContext
-		}
+	contexts := make([]string, 0, len(rawConfig.Contexts))
+	for name := range rawConfig.Contexts {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
 
-		cfg, err = clientConfig.ClientConfig()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get kubernetes out-cluster client: %w", err)
-		}
+	return contexts, nil
+}
+
+func kubeConfig(k8sContext string) clientcmd.ClientConfig {
+	kubeConfigPath := os.Getenv("KUBECONFIG")
+	if kubeConfigPath == "" {
+		kubeConfigPath = os.Getenv("HOME") + "/.kube/config"
 	}
 
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeConfigPath},
+		&clientcmd.ConfigOverrides{
+			CurrentContext: k8sContext,
+		})
+}
+
+// NewClientForConfig creates the client for an already loaded config, k8sContext is reported as the cluster name
+func NewClientForConfig(cfg *rest.Config, k8sContext string) (K8sClient, error) {
 	clientset, err := kubernetes.NewForConfig(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("can not create kubernetes client: %w", err)
@@ -76,7 +152,7 @@ func NewClient(k8sContext string) (K8sClient, error) {
 	rest.SetDefaultWarningHandler(rest.NoWarnings{})
 
 	return &k8sDB{
-		k8sContext:    currentK8sContext,
+		k8sContext:    k8sContext,
 		cfg:           cfg,
 		client:        clientset,
 		dynamicClient: dynamicClient,
@@ -88,6 +164,11 @@ type k8sDB struct {
 	cfg           *rest.Config
 	client        kubernetes.Interface
 	dynamicClient dynamic.Interface
+
+	listErrors  map[string]error
+	imageErrors map[string]error
+	// platforms are the node platforms of the last AllNodes call, so AllImages does not list the nodes again
+	platforms map[string]string
 }
 
 func (k *k8sDB) ClusterName(ctx context.Context) (string, error) {
@@ -123,111 +204,167 @@ func (k *k8sDB) AllNodes(ctx context.Context, full bool) ([]model.Node, error) {
 
 	modelNodes := make([]model.Node, 0)
 	for i := range nodes.Items {
-		var labels, annotations map[string]string
-		if full {
-			labels = nodes.Items[i].Labels
-			annotations = nodes.Items[i].Annotations
-		}
-
-		modelNodes = append(modelNodes, model.Node{
-			Name:     nodes.Items[i].Name,
-			OsImage:  nodes.Items[i].Status.NodeInfo.OSImage,
-			Hostname: getLabelValue(nodes.Items[i].Labels, "kubernetes.io/hostname"),
-			Type:     getLabelValue(nodes.Items[i].Labels, "node.kubernetes.io/instance-type"),
-			Capacity: &model.Capacity{
-				CPU:              nodes.Items[i].Status.Capacity.Cpu().String(),
-				Memory:           nodes.Items[i].Status.Capacity.Memory().String(),
-				EphemeralStorage: nodes.Items[i].Status.Capacity.StorageEphemeral().String(),
-				Pods:             nodes.Items[i].Status.Capacity.Pods().String(),
-			},
-			Allocatable: &model.Capacity{
-				CPU:              nodes.Items[i].Status.Allocatable.Cpu().String(),
-				Memory:           nodes.Items[i].Status.Allocatable.Memory().String(),
-				EphemeralStorage: nodes.Items[i].Status.Allocatable.StorageEphemeral().String(),
-				Pods:             nodes.Items[i].Status.Allocatable.Pods().String(),
-			},
-			Labels:                  labels,
-			Annotations:             annotations,
-			MachineID:               nodes.Items[i].Status.NodeInfo.MachineID,
-			Architecture:            nodes.Items[i].Status.NodeInfo.Architecture,
-			KernelVersion:           nodes.Items[i].Status.NodeInfo.KernelVersion,
-			ContainerRuntimeVersion: nodes.Items[i].Status.NodeInfo.ContainerRuntimeVersion,
-			BootID:                  nodes.Items[i].Status.NodeInfo.BootID,
-			KubeProxyVersion:        nodes.Items[i].Status.NodeInfo.KubeProxyVersion,
-			KubeletVersion:          nodes.Items[i].Status.NodeInfo.KubeletVersion,
-			OperatingSystem:         nodes.Items[i].Status.NodeInfo.OperatingSystem,
-		})
+		modelNodes = append(modelNodes, toModelNode(&nodes.Items[i], full))
 	}
+	k.platforms = platformsByNode(nodes.Items)
 
 	return modelNodes, nil
 }
 
+func toModelNode(n *v1.Node, full bool) model.Node {
+	var labels, annotations map[string]string
+	if full {
+		labels = n.Labels
+		annotations = n.Annotations
+	}
+
+	return model.Node{
+		Name:     n.Name,
+		OsImage:  n.Status.NodeInfo.OSImage,
+		Hostname: getLabelValue(n.Labels, "kubernetes.io/hostname"),
+		Type:     getLabelValue(n.Labels, "node.kubernetes.io/instance-type"),
+		Capacity: &model.Capacity{
+			CPU:              n.Status.Capacity.Cpu().String(),
+			Memory:           n.Status.Capacity.Memory().String(),
+			EphemeralStorage: n.Status.Capacity.StorageEphemeral().String(),
+			Pods:             n.Status.Capacity.Pods().String(),
+		},
+		Allocatable: &model.Capacity{
+			CPU:              n.Status.Allocatable.Cpu().String(),
+			Memory:           n.Status.Allocatable.Memory().String(),
+			EphemeralStorage: n.Status.Allocatable.StorageEphemeral().String(),
+			Pods:             n.Status.Allocatable.Pods().String(),
+		},
+		Labels:                  labels,
+		Annotations:             annotations,
+		MachineID:               n.Status.NodeInfo.MachineID,
+		Architecture:            n.Status.NodeInfo.Architecture,
+		KernelVersion:           n.Status.NodeInfo.KernelVersion,
+		ContainerRuntimeVersion: n.Status.NodeInfo.ContainerRuntimeVersion,
+		BootID:                  n.Status.NodeInfo.BootID,
+		KubeProxyVersion:        n.Status.NodeInfo.KubeProxyVersion,
+		KubeletVersion:          n.Status.NodeInfo.KubeletVersion,
+		OperatingSystem:         n.Status.NodeInfo.OperatingSystem,
+		Runtime:                 model.ParseRuntime(n.Status.NodeInfo.ContainerRuntimeVersion),
+		OS:                      model.ParseOS(n.Status.NodeInfo.OSImage),
+		Kernel:                  model.ParseKernel(n.Status.NodeInfo.OperatingSystem, n.Status.NodeInfo.KernelVersion),
+	}
+}
+
 func (k *k8sDB) AllImages(ctx context.Context) ([]model.Image, error) {
 	namespaces, err := k.client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list namespaces: %v", err)
 	}
 
+	platforms := k.platforms
+	if platforms == nil {
+		platforms = k.nodePlatforms(ctx)
+	}
+
 	images := make(map[string]model.Image)
+	k.imageErrors = make(map[string]error)
 	for i := range namespaces.Items {
-		pods, err := k.client.CoreV1().Pods(namespaces.Items[i].Name).List(ctx, metav1.ListOptions{})
+		namespace := namespaces.Items[i].Name
+		pods, err := k.client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
 		if err != nil {
-			return nil, fmt.Errorf("failed to list pods: %w", err)
+			log.Debug().Err(err).Str("namespace", namespace).Msg("Failed to list pods, skipping their images")
+			k.imageErrors[namespace] = fmt.Errorf("failed to list pods: %w", err)
+			continue
 		}
-		namespace := namespaces.Items[i].Name
 
 		log.Debug().Str("namespace", namespace).Int("count", len(pods.Items)).Msg("Found pods in namespace")
 
 		for j := range pods.Items {
-			pod := pods.Items[j]
+			addPodImages(images, k.imageErrors, &pods.Items[j], platforms[pods.Items[j].Spec.NodeName])
+		}
+	}
 
-			for k := range pod.Spec.InitContainers {
-				img, err := containerToImage(pod.Spec.InitContainers[k].Image,
-					pod.Spec.InitContainers[k].Name, pod.Status.InitContainerStatuses, namespace)
-				if err != nil {
-					return nil, err
-				}
+	toReturn := make([]model.Image, 0)
+	for _, v := range images {
+		toReturn = append(toReturn, v)
+	}
 
-				images[img.FullName] = *img
-			}
+	return toReturn, nil
+}
 
-			for k := range pod.Spec.Containers {
-				img, err := containerToImage(pod.Spec.Containers[k].Image, pod.Spec.Containers[k].Name, pod.Status.ContainerStatuses, namespace)
-				if err != nil {
-					return nil, err
-				}
+// nodePlatforms lists the nodes for their platforms. The platforms only add detail to the images, so when the nodes
+// can not be listed the images are collected without them.
+func (k *k8sDB) nodePlatforms(ctx context.Context) map[string]string {
+	nodes, err := k.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to list nodes, collecting the images without their platforms")
+		return nil
+	}
 
-				images[img.FullName] = *img
-			}
+	return platformsByNode(nodes.Items)
+}
 
-			for k := range pod.Spec.EphemeralContainers {
-				img, err := containerToImage(pod.Spec.EphemeralContainers[k].Image,
-					pod.Spec.EphemeralContainers[k].Name, pod.Status.EphemeralContainerStatuses, namespace)
-				if err != nil {
-					return nil, err
-				}
+// platformsByNode returns the os/arch platform of every node by name
+func platformsByNode(nodes []v1.Node) map[string]string {
+	platforms := make(map[string]string, len(nodes))
+	for i := range nodes {
+		if platform := nodePlatform(&nodes[i]); platform != "" {
+			platforms[nodes[i].Name] = platform
+		}
+	}
 
-				images[img.FullName] = *img
-			}
+	return platforms
+}
+
+// nodePlatform returns the os/arch of the node, falling back to the well-known labels when the node info is not reported
+func nodePlatform(node *v1.Node) string {
+	osName := node.Status.NodeInfo.OperatingSystem
+	if osName == "" {
+		osName = getLabelValue(node.Labels, v1.LabelOSStable)
+	}
+	arch := node.Status.NodeInfo.Architecture
+	if arch == "" {
+		arch = getLabelValue(node.Labels, v1.LabelArchStable)
+	}
+
+	if osName == "" || arch == "" {
+		return ""
+	}
+
+	return osName + "/" + arch
+}
+
+// addPodImages adds the images of all init, app and ephemeral containers of the pod. Containers with an image reference
+// that can not be parsed are skipped and recorded in skipped by namespace/pod/container.
+func addPodImages(images map[string]model.Image, skipped map[string]error, pod *v1.Pod, platform string) {
+	add := func(container, image string, statuses []v1.ContainerStatus) {
+		img, err := containerToImage(image, container, statuses, pod.Namespace, platform)
+		if err != nil {
+			log.Debug().Err(err).Str("namespace", pod.Namespace).Str("pod", pod.Name).Msg("Skipping container image")
+			skipped[pod.Namespace+"/"+pod.Name+"/"+container] = err
+			return
 		}
+
+		addImage(images, img)
 	}
 
-	toReturn := make([]model.Image, 0)
-	for _, v := range images {
-		toReturn = append(toReturn, v)
+	for k := range pod.Spec.InitContainers {
+		add(pod.Spec.InitContainers[k].Name, pod.Spec.InitContainers[k].Image, pod.Status.InitContainerStatuses)
 	}
 
-	return toReturn, nil
+	for k := range pod.Spec.Containers {
+		add(pod.Spec.Containers[k].Name, pod.Spec.Containers[k].Image, pod.Status.ContainerStatuses)
+	}
+
+	for k := range pod.Spec.EphemeralContainers {
+		add(pod.Spec.EphemeralContainers[k].Name, pod.Spec.EphemeralContainers[k].Image, pod.Status.EphemeralContainerStatuses)
+	}
 }
 
-func containerToImage(img, imgName string, statuses []v1.ContainerStatus, namespace string) (*model.Image, error) {
+func containerToImage(img, imgName string, statuses []v1.ContainerStatus, namespace, platform string) (*model.Image, error) {
 	if img == "" {
-		return nil, fmt.Errorf("container %s has no image", img)
+		return nil, fmt.Errorf("%w: container %s has no image", ErrInvalidImage, imgName)
 	}
 
 	named, err := reference.ParseNormalizedNamed(img)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w %q: %w", ErrInvalidImage, img, err)
 	}
 
 	controlPlane := false
@@ -241,6 +378,8 @@ func containerToImage(img, imgName string, statuses []v1.ContainerStatus, namesp
 	}
 
 	res.Name = named.Name()
+	res.Registry = reference.Domain(named)
+	res.Repository = reference.Path(named)
 	tagged, ok := named.(reference.Tagged)
 	if ok {
 		res.Version = tagged.Tag()
@@ -249,24 +388,134 @@ func containerToImage(img, imgName string, statuses []v1.ContainerStatus, namesp
 	digested, ok := named.(reference.Digested)
 	if ok {
 		res.Digest = digested.Digest().String()
+		res.SpecDigest = res.Digest
 	}
 
-	// search in statuses for ImageID to get digest
+	// TagNameOnly adds the implicit latest tag, but only to references without a digest
+	normalized := reference.TagNameOnly(named)
+	if tagged, ok := normalized.(reference.Tagged); ok {
+		res.Tag = tagged.Tag()
+	}
+	res.Normalized = normalized.String()
+	res.MutableTag = res.SpecDigest == ""
+	res.LatestTag = res.MutableTag && res.Tag == latestTag
+
+	digest, kind := runtimeDigest(imgName, statuses)
+	if digest != "" {
+		res.Digest = digest
+		res.RuntimeDigests = []string{digest}
+	}
+
+	if platform != "" {
+		res.Platforms = []model.ImagePlatform{{Platform: platform, Digest: digest, DigestKind: kind}}
+	}
+
+	return res, nil
+}
+
+// runtimeDigest returns the digest of the image the container runs and its kind, empty when it is not known yet.
+// A digest qualified by the repository is the registry manifest digest, which is the manifest list for multi-arch images,
+// while a bare image ID is the platform specific image the runtime unpacked.
+func runtimeDigest(containerName string, statuses []v1.ContainerStatus) (string, string) {
 	for i := range statuses {
-		if imgName == statuses[i].Name {
-			if statuses[i].State.Running == nil && statuses[i].State.Terminated == nil {
-				break // We can get valid digest only from running or terminated containers
-			}
-			if strings.Contains(statuses[i].ImageID, "@") {
-				res.Digest = strings.Split(statuses[i].ImageID, "@")[1]
-			} else if strings.HasPrefix(statuses[i].ImageID, "sha256:") {
-				res.Digest = statuses[i].ImageID
-			}
-			break
+		if containerName != statuses[i].Name {
+			continue
+		}
+
+		if statuses[i].State.Running == nil && statuses[i].State.Terminated == nil {
+			return "", "" // We can get valid digest only from running or terminated containers
+		}
+		if strings.Contains(statuses[i].ImageID, "@") {
+			return strings.Split(statuses[i].ImageID, "@")[1], model.DigestKindRepository
+		}
+		if strings.HasPrefix(statuses[i].ImageID, "sha256:") {
+			return statuses[i].ImageID, model.DigestKindPlatform
 		}
+
+		return "", ""
 	}
 
-	return res, nil
+	return "", ""
+}
+
+// addImage adds the image to the set, merging the runtime digests of containers using the same image reference
+func addImage(images map[string]model.Image, img *model.Image) {
+	existing, ok := images[img.FullName]
+	if !ok {
+		images[img.FullName] = *img
+		return
+	}
+
+	for _, digest := range img.RuntimeDigests {
+		existing.RuntimeDigests = appendUnique(existing.RuntimeDigests, digest)
+	}
+	for _, platform := range img.Platforms {
+		existing.Platforms = appendPlatform(existing.Platforms, platform)
+	}
+
+	if existing.Digest == "" || existing.Digest == existing.SpecDigest {
+		existing.Digest = img.Digest
+	}
+	existing.DigestDrift = digestDrift(&existing)
+
+	images[img.FullName] = existing
+}
+
+// digestDrift reports whether containers of the image resolved it to different digests. Platform image IDs differ per
+// platform by design, so they are only compared on the same platform, while repository digests, the manifest list of a
+// multi-arch image, are the same on every platform. Digests of containers on nodes without a known platform are compared
+// among themselves.
+func digestDrift(img *model.Image) bool {
+	groups := make(map[string]string)
+	drift := func(group, digest string) bool {
+		if existing, ok := groups[group]; ok && existing != digest {
+			return true
+		}
+		groups[group] = digest
+		return false
+	}
+
+	known := make(map[string]bool)
+	for _, p := range img.Platforms {
+		if p.Digest == "" {
+			continue
+		}
+		known[p.Digest] = true
+
+		group := p.DigestKind
+		if p.DigestKind == model.DigestKindPlatform {
+			group += "/" + p.Platform
+		}
+		if drift(group, p.Digest) {
+			return true
+		}
+	}
+
+	for _, digest := range img.RuntimeDigests {
+		if !known[digest] && drift("", digest) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// appendPlatform adds the platform unless it is already recorded, keeping the platforms sorted
+func appendPlatform(platforms []model.ImagePlatform, platform model.ImagePlatform) []model.ImagePlatform {
+	if slices.Contains(platforms, platform) {
+		return platforms
+	}
+
+	platforms = append(platforms, platform)
+	sort.Slice(platforms, func(i, j int) bool {
+		if platforms[i].Platform != platforms[j].Platform {
+			return platforms[i].Platform < platforms[j].Platform
+		}
+
+		return platforms[i].Digest < platforms[j].Digest
+	})
+
+	return platforms
 }
 
 // Metadata returns the kubernetes version
@@ -305,12 +554,18 @@ func (k *k8sDB) Metadata(ctx context.Context) (k8sVersion, caDigest string, err
 	return ver, caDigest, nil
 }
 
+// AllResources lists every listable resource. Resources that fail to list and API groups that fail discovery, e.g.
+// an unavailable aggregated API, are recorded in the ResourceListErrors and skipped.
 func (k *k8sDB) AllResources(ctx context.Context, full bool) (map[string]model.ResourceList, error) {
-	apiResourceList, err := k.client.Discovery().ServerPreferredResources()
+	apiResourceList, groupErrors, err := preferredResources(k.client.Discovery())
 	if err != nil {
-		return nil, fmt.Errorf("failed to get api groups: %w", err)
+		return nil, err
 	}
 
+	k.listErrors = make(map[string]error, len(groupErrors))
+	for gv, groupErr := range groupErrors {
+		k.listErrors[groupErrorKey(gv)] = groupErr
+	}
 	resourceMap := make(map[string]model.ResourceList)
 	for _, apiResource := range apiResourceList {
 		gv, err := schema.ParseGroupVersion(apiResource.GroupVersion)
@@ -320,6 +575,10 @@ func (k *k8sDB) AllResources(ctx context.Context, full bool) (map[string]model.R
 
 		for i := range apiResource.APIResources {
 			res := apiResource.APIResources[i]
+			if !isListable(&res) {
+				continue
+			}
+
 			gvr := schema.GroupVersionResource{
 				Group:    gv.Group,
 				Version:  gv.Version,
@@ -329,6 +588,7 @@ func (k *k8sDB) AllResources(ctx context.Context, full bool) (map[string]model.R
 			resourceList, err := k.dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
 			if err != nil {
 				log.Debug().Err(err).Interface("gvr", gvr).Msg("Failed to list resources")
+				k.listErrors[gvr.String()] = err
 				continue
 			}
 
@@ -345,17 +605,9 @@ func (k *k8sDB) AllResources(ctx context.Context, full bool) (map[string]model.R
 			}
 
 			if full {
-				for _, item := range resourceList.Items {
-					res := model.Resource{
-						Name:                 item.GetName(),
-						Namespace:            item.GetNamespace(),
-						AdditionalProperties: map[string]string{},
-					}
-					if version, ok := getVersion(item); ok {
-						res.AdditionalProperties["version"] = version
-					}
+				for i := range resourceList.Items {
 					val := resourceMap[gvr.String()]
-					val.Resources = append(val.Resources, res)
+					val.Resources = append(val.Resources, toModelResource(&resourceList.Items[i]))
 					resourceMap[gvr.String()] = val
 				}
 			}
@@ -365,7 +617,62 @@ func (k *k8sDB) AllResources(ctx context.Context, full bool) (map[string]model.R
 	return resourceMap, nil
 }
 
-func getVersion(item unstructured.Unstructured) (version string, ok bool) {
+// preferredResources returns the preferred version of every resource, and the errors of the API groups that failed
+// discovery
+func preferredResources(d discovery.DiscoveryInterface) ([]*metav1.APIResourceList, map[schema.GroupVersion]error, error) {
+	groupErrors := make(map[schema.GroupVersion]error)
+	apiResourceList, err := discovery.ServerPreferredResources(d)
+	if err != nil {
+		var failed *discovery.ErrGroupDiscoveryFailed
+		if !errors.As(err, &failed) {
+			return nil, nil, fmt.Errorf("failed to get api groups: %w", err)
+		}
+
+		for gv, groupErr := range failed.Groups {
+			log.Warn().Err(groupErr).Str("groupVersion", gv.String()).Msg("Skipping API group that failed discovery")
+			groupErrors[gv] = groupErr
+		}
+	}
+
+	return apiResourceList, groupErrors, nil
+}
+
+// groupErrorKey returns the list error key of a group version that failed discovery, the resource key of all its
+// resources, e.g. "metrics.k8s.io/v1beta1, Resource=*"
+func groupErrorKey(gv schema.GroupVersion) string {
+	return gv.WithResource("*").String()
+}
+
+// isListable reports whether the resource can be listed, subresources like pods/log can not
+func isListable(res *metav1.APIResource) bool {
+	return !strings.Contains(res.Name, "/") && slices.Contains(res.Verbs, "list")
+}
+
+func (k *k8sDB) ResourceListErrors() map[string]error {
+	return k.listErrors
+}
+
+func (k *k8sDB) ImageErrors() map[string]error {
+	return k.imageErrors
+}
+
+func toModelResource(item *unstructured.Unstructured) model.Resource {
+	res := model.Resource{
+		Name:                 item.GetName(),
+		Namespace:            item.GetNamespace(),
+		AdditionalProperties: map[string]string{},
+	}
+	if version, ok := getVersion(item); ok {
+		res.AdditionalProperties["version"] = version
+	}
+	if chart, ok := item.GetLabels()[helmChartLabel]; ok {
+		res.AdditionalProperties["chart"] = chart
+	}
+
+	return res
+}
+
+func getVersion(item *unstructured.Unstructured) (version string, ok bool) {
 	obj := item.Object
 	if obj == nil {
 		return "", false
@@ -0,0 +1,347 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+func TestContainerToImage(t *testing.T) {
+	tests := []struct {
+		image      string
+		registry   string
+		repository string
+		tag        string
+		normalized string
+	}{
+		{
+			image:      "nginx",
+			registry:   "docker.io",
+			repository: "library/nginx",
+			tag:        "latest",
+			normalized: "docker.io/library/nginx:latest",
+		},
+		{
+			image:      "bitnami/redis:7.2",
+			registry:   "docker.io",
+			repository: "bitnami/redis",
+			tag:        "7.2",
+			normalized: "docker.io/bitnami/redis:7.2",
+		},
+		{
+			image:      "registry.k8s.io/kube-proxy:v1.29.0",
+			registry:   "registry.k8s.io",
+			repository: "kube-proxy",
+			tag:        "v1.29.0",
+			normalized: "registry.k8s.io/kube-proxy:v1.29.0",
+		},
+		{
+			image:      "localhost:5000/team/app@sha256:" + testDigest,
+			registry:   "localhost:5000",
+			repository: "team/app",
+			normalized: "localhost:5000/team/app@sha256:" + testDigest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			img, err := containerToImage(tt.image, "app", nil, "default", "")
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.image, img.FullName)
+			assert.Equal(t, tt.registry, img.Registry)
+			assert.Equal(t, tt.repository, img.Repository)
+			assert.Equal(t, tt.tag, img.Tag)
+			assert.Equal(t, tt.normalized, img.Normalized)
+		})
+	}
+}
+
+func TestContainerToImageDigests(t *testing.T) {
+	running := v1.ContainerState{Running: &v1.ContainerStateRunning{}}
+	waiting := v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"}}
+
+	img, err := containerToImage("nginx", "web", []v1.ContainerStatus{
+		{Name: "web", State: running, ImageID: "docker.io/library/nginx@sha256:" + testDigest},
+	}, "default", "")
+	require.NoError(t, err)
+	assert.Equal(t, "", img.SpecDigest)
+	assert.Equal(t, []string{"sha256:" + testDigest}, img.RuntimeDigests)
+	assert.Equal(t, "sha256:"+testDigest, img.Digest)
+	assert.True(t, img.MutableTag)
+	assert.True(t, img.LatestTag)
+
+	img, err = containerToImage("nginx:1.25@sha256:"+testDigest, "web", []v1.ContainerStatus{
+		{Name: "web", State: waiting},
+	}, "default", "")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:"+testDigest, img.SpecDigest)
+	assert.Nil(t, img.RuntimeDigests)
+	assert.Equal(t, "sha256:"+testDigest, img.Digest)
+	assert.False(t, img.MutableTag)
+	assert.False(t, img.LatestTag)
+}
+
+func TestAddImage(t *testing.T) {
+	otherDigest := "sha256:0000000000000000000000000000000000000000000000000000000000000002"
+	images := make(map[string]model.Image)
+
+	addImage(images, &model.Image{FullName: "app:1.0", Digest: "sha256:" + testDigest, RuntimeDigests: []string{"sha256:" + testDigest}})
+	addImage(images, &model.Image{FullName: "app:1.0"})
+	assert.Equal(t, "sha256:"+testDigest, images["app:1.0"].Digest, "an unresolved container keeps the known digest")
+	assert.False(t, images["app:1.0"].DigestDrift)
+
+	addImage(images, &model.Image{FullName: "app:1.0", Digest: otherDigest, RuntimeDigests: []string{otherDigest}})
+	assert.Equal(t, []string{"sha256:" + testDigest, otherDigest}, images["app:1.0"].RuntimeDigests)
+	assert.True(t, images["app:1.0"].DigestDrift)
+
+	platformImage := func(platform, digest, kind string) *model.Image {
+		return &model.Image{FullName: "multi:1.0", Digest: digest, RuntimeDigests: []string{digest},
+			Platforms: []model.ImagePlatform{{Platform: platform, Digest: digest, DigestKind: kind}}}
+	}
+	addImage(images, platformImage("linux/amd64", "sha256:"+testDigest, model.DigestKindPlatform))
+	addImage(images, platformImage("linux/arm64", otherDigest, model.DigestKindPlatform))
+	assert.False(t, images["multi:1.0"].DigestDrift, "the platform image IDs of a multi-arch image differ per architecture")
+
+	addImage(images, platformImage("linux/arm64", "sha256:"+testDigest, model.DigestKindPlatform))
+	assert.True(t, images["multi:1.0"].DigestDrift, "two image IDs on the same platform drifted")
+
+	repoDigest := "sha256:0000000000000000000000000000000000000000000000000000000000000003"
+	delete(images, "multi:1.0")
+	addImage(images, platformImage("linux/amd64", repoDigest, model.DigestKindRepository))
+	addImage(images, platformImage("linux/arm64", repoDigest, model.DigestKindRepository))
+	assert.False(t, images["multi:1.0"].DigestDrift)
+	addImage(images, platformImage("linux/arm64", otherDigest, model.DigestKindRepository))
+	assert.True(t, images["multi:1.0"].DigestDrift, "the repository digest is the same on every platform")
+}
+
+func TestAllImagesPlatforms(t *testing.T) {
+	running := v1.ContainerState{Running: &v1.ContainerStateRunning{}}
+	otherDigest := "sha256:0000000000000000000000000000000000000000000000000000000000000002"
+	pod := func(name, node, imageID string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       v1.PodSpec{NodeName: node, Containers: []v1.Container{{Name: "app", Image: "app:1.0"}}},
+			Status:     v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{Name: "app", State: running, ImageID: imageID}}},
+		}
+	}
+
+	client := fake.NewSimpleClientset(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "amd"},
+			Status:     v1.NodeStatus{NodeInfo: v1.NodeSystemInfo{OperatingSystem: "linux", Architecture: "amd64"}},
+		},
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "arm", Labels: map[string]string{
+			v1.LabelOSStable:   "linux",
+			v1.LabelArchStable: "arm64",
+		}}},
+		pod("app-1", "amd", "docker.io/library/app@sha256:"+testDigest),
+		pod("app-2", "arm", otherDigest),
+		pod("app-3", "", ""),
+	)
+
+	k := &k8sDB{client: client}
+	images, err := k.AllImages(context.Background())
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+
+	assert.Equal(t, []model.ImagePlatform{
+		{Platform: "linux/amd64", Digest: "sha256:" + testDigest, DigestKind: model.DigestKindRepository},
+		{Platform: "linux/arm64", Digest: otherDigest, DigestKind: model.DigestKindPlatform},
+	}, images[0].Platforms)
+	assert.False(t, images[0].DigestDrift, "the amd64 and arm64 digests are not compared")
+
+	_, err = k.AllNodes(context.Background(), false)
+	require.NoError(t, err)
+	client.ClearActions()
+	_, err = k.AllImages(context.Background())
+	require.NoError(t, err)
+	for _, action := range client.Actions() {
+		assert.NotEqual(t, "nodes", action.GetResource().Resource, "the nodes of AllNodes are reused")
+	}
+
+	client.PrependReactor("list", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(v1.Resource("nodes"), "", errors.New("forbidden"))
+	})
+	images, err = (&k8sDB{client: client}).AllImages(context.Background())
+	require.NoError(t, err, "the images are collected without the platforms when the nodes can not be listed")
+	require.Len(t, images, 1)
+	assert.Empty(t, images[0].Platforms)
+}
+
+func TestNewContextClientInCluster(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster: {server: "https://prod.example.com"}
+- name: staging
+  cluster: {server: "https://staging.example.com"}
+users:
+- name: admin
+  user: {token: "token"}
+contexts:
+- name: prod
+  context: {cluster: prod, user: admin}
+- name: staging
+  context: {cluster: staging, user: admin}
+current-context: prod
+`), 0o600))
+	t.Setenv("KUBECONFIG", kubeconfig)
+
+	defer func(f func() (*rest.Config, error)) { inClusterConfig = f }(inClusterConfig)
+	inClusterConfig = func() (*rest.Config, error) {
+		return &rest.Config{Host: "https://10.96.0.1:443"}, nil
+	}
+
+	cfg, _, err := RestConfig("staging")
+	require.NoError(t, err)
+	assert.Equal(t, "https://10.96.0.1:443", cfg.Host, "a single KBOM is generated for the cluster the pod runs in")
+
+	for _, name := range []string{"prod", "staging"} {
+		client, err := NewContextClient(name)
+		require.NoError(t, err)
+		assert.Equal(t, "https://"+name+".example.com", client.(*k8sDB).cfg.Host)
+
+		clusterName, err := client.ClusterName(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, name, clusterName)
+	}
+
+	_, err = NewContextClient("missing")
+	assert.ErrorContains(t, err, "failed to get kubernetes client of context missing")
+}
+
+func TestAllImagesSkipsInvalidReferences(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: v1.PodSpec{
+				InitContainers: []v1.Container{{Name: "init", Image: "Invalid:Ref"}},
+				Containers:     []v1.Container{{Name: "app", Image: "nginx:1.25"}},
+			},
+		},
+	)
+
+	k := &k8sDB{client: client}
+	images, err := k.AllImages(context.Background())
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Equal(t, "nginx:1.25", images[0].FullName)
+
+	require.Contains(t, k.ImageErrors(), "default/web/init")
+	assert.ErrorIs(t, k.ImageErrors()["default/web/init"], ErrInvalidImage)
+}
+
+func TestAllImagesNamespaceForbidden(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "restricted"}},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app", Image: "nginx:1.25"}}},
+		},
+	)
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() != "restricted" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewForbidden(v1.Resource("pods"), "", errors.New("forbidden"))
+	})
+
+	k := &k8sDB{client: client}
+	images, err := k.AllImages(context.Background())
+	require.NoError(t, err)
+	require.Len(t, images, 1, "the images of the other namespaces are kept")
+	assert.Equal(t, "nginx:1.25", images[0].FullName)
+
+	require.Contains(t, k.ImageErrors(), "restricted")
+	assert.Equal(t, model.ErrorClassForbidden, ErrorClass(k.ImageErrors()["restricted"]))
+}
+
+// failingDiscovery fails the discovery of the given group versions, like an unavailable aggregated API
+type failingDiscovery struct {
+	discovery.DiscoveryInterface
+	failed map[string]bool
+}
+
+func (d *failingDiscovery) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	if d.failed[groupVersion] {
+		return nil, errors.New("the server is currently unable to handle the request")
+	}
+
+	return d.DiscoveryInterface.ServerResourcesForGroupVersion(groupVersion)
+}
+
+type discoveryClientset struct {
+	*fake.Clientset
+	discovery discovery.DiscoveryInterface
+}
+
+func (c *discoveryClientset) Discovery() discovery.DiscoveryInterface {
+	return c.discovery
+}
+
+func TestAllResources(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "example.com/v1", APIResources: []metav1.APIResource{
+			{Name: "widgets", Kind: "Widget", Namespaced: true, Verbs: []string{"list"}},
+			{Name: "widgets/status", Kind: "Widget", Namespaced: true, Verbs: []string{"get"}},
+		}},
+		{GroupVersion: "authentication.k8s.io/v1", APIResources: []metav1.APIResource{
+			{Name: "tokenreviews", Kind: "TokenReview", Verbs: []string{"create"}},
+		}},
+		{GroupVersion: "metrics.k8s.io/v1beta1", APIResources: []metav1.APIResource{
+			{Name: "pods", Kind: "PodMetrics", Namespaced: true, Verbs: []string{"list"}},
+		}},
+	}
+
+	widgets := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{widgets: "WidgetList"},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "Widget",
+			"metadata":   map[string]interface{}{"name": "w1", "namespace": "default"},
+		}},
+	)
+
+	k := &k8sDB{
+		client: &discoveryClientset{
+			Clientset: client,
+			discovery: &failingDiscovery{DiscoveryInterface: client.Discovery(), failed: map[string]bool{"metrics.k8s.io/v1beta1": true}},
+		},
+		dynamicClient: dynamicClient,
+	}
+
+	resources, err := k.AllResources(context.Background(), true)
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, 1, resources[widgets.String()].ResourcesCount)
+
+	listErrors := k.ResourceListErrors()
+	require.Len(t, listErrors, 1, "only the failed group is recorded, not the subresources and resources that can not be listed")
+	assert.ErrorContains(t, listErrors["metrics.k8s.io/v1beta1, Resource=*"], "unable to handle the request")
+}
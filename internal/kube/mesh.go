@@ -0,0 +1,203 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+const (
+	replicaSetKind      = "ReplicaSet"
+	podTemplateHashKey  = "pod-template-hash"
+	crdGroup            = "apiextensions.k8s.io"
+	crdResource         = "customresourcedefinitions"
+	linkerdStablePrefix = "stable-"
+	linkerdEdgePrefix   = "edge-"
+)
+
+// meshDefinition recognizes a service mesh by its control plane images, injected sidecar images and CRD group
+type meshDefinition struct {
+	name         string
+	controlPlane []string
+	sidecars     []string
+	crdGroup     string
+}
+
+var meshDefinitions = []meshDefinition{
+	{
+		name:         "istio",
+		controlPlane: []string{"istio/pilot"},
+		sidecars:     []string{"istio/proxyv2"},
+		crdGroup:     "istio.io",
+	},
+	{
+		name:         "linkerd",
+		controlPlane: []string{"linkerd/controller", "linkerd/policy-controller"},
+		sidecars:     []string{"linkerd/proxy"},
+		crdGroup:     "linkerd.io",
+	},
+	{
+		name:         "consul",
+		controlPlane: []string{"hashicorp/consul-k8s-control-plane"},
+		sidecars:     []string{"hashicorp/consul-dataplane"},
+		crdGroup:     "consul.hashicorp.com",
+	},
+	{
+		// Cilium runs its proxy per node, so there are no sidecars to track
+		name:         "cilium",
+		controlPlane: []string{"cilium/cilium", "cilium/operator-generic"},
+		crdGroup:     "cilium.io",
+	},
+}
+
+// ServiceMesh returns the detected service meshes with their control plane and per-namespace sidecar versions
+func (k *k8sDB) ServiceMesh(ctx context.Context, podTemplates *PodTemplates) (*model.ServiceMesh, error) {
+	templates := podTemplates.items
+
+	crds, err := k.dynamicClient.Resource(schema.GroupVersionResource{Group: crdGroup, Version: "v1", Resource: crdResource}).
+		List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom resource definitions: %w", err)
+	}
+
+	res := &model.ServiceMesh{Meshes: make([]model.Mesh, 0)}
+	for _, def := range meshDefinitions {
+		mesh := model.Mesh{
+			Name:         def.name,
+			ControlPlane: detectComponents(templates, []signature{{name: def.name, images: def.controlPlane}}),
+			CRDs:         meshCRDs(crds.Items, def.crdGroup),
+		}
+
+		for _, c := range mesh.ControlPlane {
+			if c.Version != "" {
+				mesh.Version = c.Version
+				break
+			}
+		}
+
+		mesh.Namespaces = meshNamespaces(podTemplates.pods, def, mesh.Version)
+
+		if len(mesh.ControlPlane) == 0 && len(mesh.CRDs) == 0 && len(mesh.Namespaces) == 0 {
+			continue
+		}
+
+		res.Meshes = append(res.Meshes, mesh)
+	}
+
+	return res, nil
+}
+
+func meshCRDs(crds []unstructured.Unstructured, group string) []string {
+	res := make([]string, 0)
+	for i := range crds {
+		g, _, _ := unstructured.NestedString(crds[i].Object, "spec", "group")
+		if g == group || strings.HasSuffix(g, "."+group) {
+			res = append(res, crds[i].GetName())
+		}
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+// meshNamespaces groups the injected sidecars by namespace and version. Native sidecars run as init containers.
+func meshNamespaces(pods []v1.Pod, def meshDefinition, controlPlaneVersion string) []model.MeshNamespace {
+	sidecars := []signature{{name: def.name, images: def.sidecars}}
+	namespaces := make(map[string]map[string]*model.SidecarProxy)
+
+	for i := range pods {
+		p := &pods[i]
+		containers := append(append([]v1.Container{}, p.Spec.InitContainers...), p.Spec.Containers...)
+		for _, c := range containers {
+			if _, _, ok := matchSignature(c.Image, sidecars); !ok {
+				continue
+			}
+
+			_, version := imageNameAndTag(c.Image)
+			if namespaces[p.Namespace] == nil {
+				namespaces[p.Namespace] = make(map[string]*model.SidecarProxy)
+			}
+
+			proxy, ok := namespaces[p.Namespace][c.Image]
+			if !ok {
+				proxy = &model.SidecarProxy{
+					Version:   version,
+					Image:     c.Image,
+					Workloads: make([]string, 0),
+					Outdated:  olderThan(version, controlPlaneVersion),
+				}
+				namespaces[p.Namespace][c.Image] = proxy
+			}
+
+			proxy.Pods++
+			proxy.Workloads = appendUnique(proxy.Workloads, podWorkload(p))
+
+			break
+		}
+	}
+
+	res := make([]model.MeshNamespace, 0, len(namespaces))
+	for ns, proxies := range namespaces {
+		mn := model.MeshNamespace{Namespace: ns, Sidecars: make([]model.SidecarProxy, 0, len(proxies))}
+		for _, proxy := range proxies {
+			mn.Sidecars = append(mn.Sidecars, *proxy)
+		}
+		sort.Slice(mn.Sidecars, func(i, j int) bool {
+			return mn.Sidecars[i].Image < mn.Sidecars[j].Image
+		})
+		res = append(res, mn)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Namespace < res[j].Namespace
+	})
+
+	return res
+}
+
+// podWorkload returns the workload owning the pod as Kind/name, resolving ReplicaSets to their Deployment
+func podWorkload(p *v1.Pod) string {
+	owner := metav1.GetControllerOf(p)
+	if owner == nil {
+		return fmt.Sprintf("%s/%s", podKind, p.Name)
+	}
+
+	if hash, ok := p.Labels[podTemplateHashKey]; ok && owner.Kind == replicaSetKind {
+		return fmt.Sprintf("%s/%s", deploymentKind, strings.TrimSuffix(owner.Name, "-"+hash))
+	}
+
+	return fmt.Sprintf("%s/%s", owner.Kind, owner.Name)
+}
+
+// olderThan reports whether version is lower than reference, false when either is not a semantic version. Only
+// major.minor.patch are compared, suffixes like -distroless are image variants rather than prereleases.
+func olderThan(version, reference string) bool {
+	v, err := meshVersion(version)
+	if err != nil {
+		return false
+	}
+
+	r, err := meshVersion(reference)
+	if err != nil {
+		return false
+	}
+
+	return slices.Compare([]int64{v.Major(), v.Minor(), v.Patch()}, []int64{r.Major(), r.Minor(), r.Patch()}) < 0
+}
+
+// meshVersion parses a proxy or control plane version, including Linkerd stable-x.y.z and edge-y.m.n channels
+func meshVersion(version string) (*semver.Version, error) {
+	version = strings.TrimPrefix(version, linkerdStablePrefix)
+	version = strings.TrimPrefix(version, linkerdEdgePrefix)
+
+	return semver.NewVersion(strings.TrimPrefix(version, "v"))
+}
@@ -0,0 +1,113 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+func TestServiceMesh(t *testing.T) {
+	isController := true
+	sidecarPod := func(name, namespace, owner, hash, version string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       namespace,
+				Labels:          map[string]string{podTemplateHashKey: hash},
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: owner, Controller: &isController}},
+			},
+			Spec: v1.PodSpec{Containers: []v1.Container{
+				{Name: "app", Image: "example/app:1.0"},
+				{Name: "istio-proxy", Image: "docker.io/istio/proxyv2:" + version},
+			}},
+		}
+	}
+
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "istiod", Namespace: "istio-system"},
+			Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: "discovery", Image: "docker.io/istio/pilot:1.20.2"}},
+			}}},
+		},
+		sidecarPod("web-6d4cf56db6-abcde", "shop", "web-6d4cf56db6", "6d4cf56db6", "1.20.2"),
+		sidecarPod("web-6d4cf56db6-fghij", "shop", "web-6d4cf56db6", "6d4cf56db6", "1.20.2"),
+		sidecarPod("legacy-7f9b8c6d5-klmno", "shop", "legacy-7f9b8c6d5", "7f9b8c6d5", "1.19.5"),
+	)
+
+	crds := []runtime.Object{
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1",
+			"kind":       "CustomResourceDefinition",
+			"metadata":   map[string]interface{}{"name": "virtualservices.networking.istio.io"},
+			"spec":       map[string]interface{}{"group": "networking.istio.io"},
+		}},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1",
+			"kind":       "CustomResourceDefinition",
+			"metadata":   map[string]interface{}{"name": "certificates.cert-manager.io"},
+			"spec":       map[string]interface{}{"group": "cert-manager.io"},
+		}},
+	}
+
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: crdGroup, Version: "v1", Resource: crdResource}: "CustomResourceDefinitionList",
+	}
+
+	k := &k8sDB{
+		client:        client,
+		dynamicClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, crds...),
+	}
+	templates, err := k.PodTemplates(context.Background())
+	require.NoError(t, err)
+	res, err := k.ServiceMesh(context.Background(), templates)
+	require.NoError(t, err)
+
+	require.Len(t, res.Meshes, 1)
+	mesh := res.Meshes[0]
+	assert.Equal(t, "istio", mesh.Name)
+	assert.Equal(t, "1.20.2", mesh.Version)
+	assert.Equal(t, []string{"virtualservices.networking.istio.io"}, mesh.CRDs)
+	require.Len(t, mesh.ControlPlane, 1)
+	assert.Equal(t, "Deployment/istiod", mesh.ControlPlane[0].Workload)
+
+	assert.Equal(t, []model.MeshNamespace{{
+		Namespace: "shop",
+		Sidecars: []model.SidecarProxy{
+			{
+				Version:   "1.19.5",
+				Image:     "docker.io/istio/proxyv2:1.19.5",
+				Pods:      1,
+				Workloads: []string{"Deployment/legacy"},
+				Outdated:  true,
+			},
+			{
+				Version:   "1.20.2",
+				Image:     "docker.io/istio/proxyv2:1.20.2",
+				Pods:      2,
+				Workloads: []string{"Deployment/web"},
+			},
+		},
+	}}, mesh.Namespaces)
+}
+
+func TestOlderThan(t *testing.T) {
+	assert.True(t, olderThan("stable-2.13.7", "stable-2.14.10"))
+	assert.False(t, olderThan("1.20.2", "1.20.2"))
+	assert.False(t, olderThan("1.20.0-distroless", "1.20.0"), "variant suffixes are not prereleases")
+	assert.True(t, olderThan("1.19.5-distroless", "1.20.0"))
+	assert.False(t, olderThan("latest", "1.20.2"))
+	assert.False(t, olderThan("1.20.2", ""))
+}
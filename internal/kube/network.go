@@ -0,0 +1,416 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+const gatewayAPIGroup = "gateway.networking.k8s.io"
+
+var networkControllerSignatures = []signature{
+	{name: "ingress-nginx", images: []string{"ingress-nginx/controller"}},
+	{name: "nginx-ingress", images: []string{"nginx/nginx-ingress"}},
+	{name: "traefik", images: []string{"traefik"}},
+	{name: "haproxy-ingress", images: []string{"haproxytech/kubernetes-ingress", "jcmoraisjr/haproxy-ingress"}},
+	{name: "contour", images: []string{"projectcontour/contour"}},
+	{name: "kong", images: []string{"kong/kubernetes-ingress-controller"}},
+	{name: "envoy-gateway", images: []string{"envoyproxy/gateway"}},
+	{name: "aws-load-balancer-controller", images: []string{"eks/aws-load-balancer-controller"}},
+}
+
+// gatewayRoute is a Gateway API route resource with the versions to try, newest first
+type gatewayRoute struct {
+	kind     string
+	resource string
+	versions []string
+}
+
+var gatewayRoutes = []gatewayRoute{
+	{kind: "HTTPRoute", resource: "httproutes", versions: []string{"v1", "v1beta1"}},
+	{kind: "GRPCRoute", resource: "grpcroutes", versions: []string{"v1", "v1alpha2"}},
+	{kind: "TLSRoute", resource: "tlsroutes", versions: []string{"v1alpha2"}},
+	{kind: "TCPRoute", resource: "tcproutes", versions: []string{"v1alpha2"}},
+}
+
+// Network returns how the cluster is exposed: LoadBalancer and NodePort Services, Ingresses, Gateway API
+// gateways and routes with the workloads they route to, detected ingress controllers and namespaces
+// without any NetworkPolicy
+func (k *k8sDB) Network(ctx context.Context, podTemplates *PodTemplates) (*model.Network, error) {
+	services, err := k.client.CoreV1().Services("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	templates := podTemplates.items
+
+	ingresses, err := k.client.NetworkingV1().Ingresses("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+
+	unprotected, err := k.namespacesWithoutNetworkPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	backends := newBackendResolver(services.Items, templates)
+
+	res := &model.Network{
+		ExposedServices:                exposedServices(services.Items, backends),
+		Ingresses:                      make([]model.Ingress, 0, len(ingresses.Items)),
+		Controllers:                    detectComponents(templates, networkControllerSignatures),
+		NamespacesWithoutNetworkPolicy: unprotected,
+	}
+
+	for i := range ingresses.Items {
+		res.Ingresses = append(res.Ingresses, toIngress(&ingresses.Items[i], backends))
+	}
+
+	if res.Gateways, err = k.gateways(ctx); err != nil {
+		return nil, err
+	}
+
+	if res.Routes, err = k.routes(ctx, backends); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (k *k8sDB) namespacesWithoutNetworkPolicy(ctx context.Context) ([]string, error) {
+	namespaces, err := k.client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	policies, err := k.client.NetworkingV1().NetworkPolicies("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list network policies: %w", err)
+	}
+
+	protected := make(map[string]bool)
+	for i := range policies.Items {
+		protected[policies.Items[i].Namespace] = true
+	}
+
+	res := make([]string, 0)
+	for i := range namespaces.Items {
+		if !protected[namespaces.Items[i].Name] {
+			res = append(res, namespaces.Items[i].Name)
+		}
+	}
+	sort.Strings(res)
+
+	return res, nil
+}
+
+// backendResolver maps Services to the workloads their selectors match
+type backendResolver struct {
+	services  map[string]*v1.Service
+	templates []podTemplate
+}
+
+func newBackendResolver(services []v1.Service, templates []podTemplate) *backendResolver {
+	r := &backendResolver{services: make(map[string]*v1.Service, len(services)), templates: templates}
+	for i := range services {
+		r.services[services[i].Namespace+"/"+services[i].Name] = &services[i]
+	}
+
+	return r
+}
+
+// workloads returns the workloads selected by the Service, empty when it does not exist or has no selector
+func (r *backendResolver) workloads(namespace, name string) []model.WorkloadRef {
+	res := make([]model.WorkloadRef, 0)
+	svc, ok := r.services[namespace+"/"+name]
+	if !ok || len(svc.Spec.Selector) == 0 {
+		return res
+	}
+
+	selector := labels.SelectorFromSet(svc.Spec.Selector)
+	for i := range r.templates {
+		t := &r.templates[i]
+		if t.meta.Namespace != namespace || !selector.Matches(labels.Set(t.labels)) {
+			continue
+		}
+		res = append(res, model.WorkloadRef{Kind: t.kind, Name: t.meta.Name, Namespace: namespace})
+	}
+
+	return res
+}
+
+func (r *backendResolver) backend(namespace, service, port string) model.Backend {
+	return model.Backend{
+		Service:   service,
+		Namespace: namespace,
+		Port:      port,
+		Workloads: r.workloads(namespace, service),
+	}
+}
+
+func exposedServices(services []v1.Service, backends *backendResolver) []model.ExposedService {
+	res := make([]model.ExposedService, 0)
+	for i := range services {
+		svc := &services[i]
+		if svc.Spec.Type != v1.ServiceTypeLoadBalancer && svc.Spec.Type != v1.ServiceTypeNodePort {
+			continue
+		}
+
+		exposed := model.ExposedService{
+			Name:        svc.Name,
+			Namespace:   svc.Namespace,
+			Type:        string(svc.Spec.Type),
+			ExternalIPs: append(slices.Clone(svc.Spec.ExternalIPs), loadBalancerAddresses(svc.Status.LoadBalancer.Ingress)...),
+			Ports:       make([]model.ServicePort, 0, len(svc.Spec.Ports)),
+			Workloads:   backends.workloads(svc.Namespace, svc.Name),
+		}
+
+		for _, p := range svc.Spec.Ports {
+			exposed.Ports = append(exposed.Ports, model.ServicePort{
+				Name:       p.Name,
+				Protocol:   string(p.Protocol),
+				Port:       p.Port,
+				NodePort:   p.NodePort,
+				TargetPort: p.TargetPort.String(),
+			})
+		}
+
+		res = append(res, exposed)
+	}
+
+	return res
+}
+
+func loadBalancerAddresses(ingress []v1.LoadBalancerIngress) []string {
+	var res []string
+	for _, i := range ingress {
+		if i.IP != "" {
+			res = append(res, i.IP)
+		}
+		if i.Hostname != "" {
+			res = append(res, i.Hostname)
+		}
+	}
+
+	return res
+}
+
+func toIngress(ing *networkingv1.Ingress, backends *backendResolver) model.Ingress {
+	res := model.Ingress{
+		Name:      ing.Name,
+		Namespace: ing.Namespace,
+		Hosts:     make([]string, 0),
+		Backends:  make([]model.Backend, 0),
+	}
+
+	if ing.Spec.IngressClassName != nil {
+		res.ClassName = *ing.Spec.IngressClassName
+	}
+
+	for _, tls := range ing.Spec.TLS {
+		res.TLS = append(res.TLS, model.TLSConfig{Hosts: tls.Hosts, SecretName: tls.SecretName})
+	}
+
+	for _, i := range ing.Status.LoadBalancer.Ingress {
+		if i.IP != "" {
+			res.ExternalAddresses = append(res.ExternalAddresses, i.IP)
+		}
+		if i.Hostname != "" {
+			res.ExternalAddresses = append(res.ExternalAddresses, i.Hostname)
+		}
+	}
+
+	if b := ing.Spec.DefaultBackend; b != nil && b.Service != nil {
+		res.Backends = append(res.Backends, backends.backend(ing.Namespace, b.Service.Name, ingressPort(b.Service.Port)))
+	}
+
+	for _, rule := range ing.Spec.Rules {
+		if rule.Host != "" {
+			res.Hosts = appendUnique(res.Hosts, rule.Host)
+		}
+		if rule.HTTP == nil {
+			continue
+		}
+
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service == nil {
+				continue
+			}
+			backend := backends.backend(ing.Namespace, path.Backend.Service.Name, ingressPort(path.Backend.Service.Port))
+			backend.Host = rule.Host
+			backend.Path = path.Path
+			res.Backends = append(res.Backends, backend)
+		}
+	}
+
+	return res
+}
+
+func ingressPort(port networkingv1.ServiceBackendPort) string {
+	if port.Name != "" {
+		return port.Name
+	}
+
+	return fmt.Sprint(port.Number)
+}
+
+// listGatewayAPI lists a Gateway API resource in the newest served version, nil when none is served
+func (k *k8sDB) listGatewayAPI(ctx context.Context, resource string, versions []string) ([]unstructured.Unstructured, error) {
+	for _, version := range versions {
+		gvr := schema.GroupVersionResource{Group: gatewayAPIGroup, Version: version, Resource: resource}
+		list, err := k.dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", resource, err)
+		}
+
+		return list.Items, nil
+	}
+
+	return nil, nil
+}
+
+func (k *k8sDB) gateways(ctx context.Context) ([]model.Gateway, error) {
+	items, err := k.listGatewayAPI(ctx, "gateways", []string{"v1", "v1beta1"})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]model.Gateway, 0, len(items))
+	for i := range items {
+		obj := items[i].Object
+		gw := model.Gateway{
+			Name:      items[i].GetName(),
+			Namespace: items[i].GetNamespace(),
+			Listeners: make([]model.Listener, 0),
+		}
+		gw.ClassName, _, _ = unstructured.NestedString(obj, "spec", "gatewayClassName")
+
+		addresses, _, _ := unstructured.NestedSlice(obj, "status", "addresses")
+		for _, a := range addresses {
+			if value := nestedString(a, "value"); value != "" {
+				gw.Addresses = append(gw.Addresses, value)
+			}
+		}
+
+		listeners, _, _ := unstructured.NestedSlice(obj, "spec", "listeners")
+		for _, l := range listeners {
+			listener := model.Listener{
+				Name:     nestedString(l, "name"),
+				Hostname: nestedString(l, "hostname"),
+				Protocol: nestedString(l, "protocol"),
+			}
+			if m, ok := l.(map[string]interface{}); ok {
+				listener.Port, _, _ = unstructured.NestedInt64(m, "port")
+				refs, _, _ := unstructured.NestedSlice(m, "tls", "certificateRefs")
+				for _, ref := range refs {
+					listener.TLSSecrets = append(listener.TLSSecrets, refName(ref, gw.Namespace))
+				}
+			}
+			gw.Listeners = append(gw.Listeners, listener)
+		}
+
+		res = append(res, gw)
+	}
+
+	return res, nil
+}
+
+func (k *k8sDB) routes(ctx context.Context, backends *backendResolver) ([]model.Route, error) {
+	res := make([]model.Route, 0)
+	for _, r := range gatewayRoutes {
+		items, err := k.listGatewayAPI(ctx, r.resource, r.versions)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range items {
+			res = append(res, toRoute(r.kind, &items[i], backends))
+		}
+	}
+
+	return res, nil
+}
+
+func toRoute(kind string, item *unstructured.Unstructured, backends *backendResolver) model.Route {
+	obj := item.Object
+	route := model.Route{
+		Kind:      kind,
+		Name:      item.GetName(),
+		Namespace: item.GetNamespace(),
+		Gateways:  make([]string, 0),
+		Backends:  make([]model.Backend, 0),
+	}
+	route.Hostnames, _, _ = unstructured.NestedStringSlice(obj, "spec", "hostnames")
+
+	parents, _, _ := unstructured.NestedSlice(obj, "spec", "parentRefs")
+	for _, p := range parents {
+		route.Gateways = append(route.Gateways, refName(p, route.Namespace))
+	}
+
+	rules, _, _ := unstructured.NestedSlice(obj, "spec", "rules")
+	for _, rule := range rules {
+		m, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		refs, _, _ := unstructured.NestedSlice(m, "backendRefs")
+		for _, ref := range refs {
+			// only Services can be resolved to workloads, other backend kinds are skipped
+			if kind := nestedString(ref, "kind"); kind != "" && kind != "Service" {
+				continue
+			}
+
+			namespace := nestedString(ref, "namespace")
+			if namespace == "" {
+				namespace = route.Namespace
+			}
+
+			port := ""
+			if m, ok := ref.(map[string]interface{}); ok {
+				if p, found, _ := unstructured.NestedInt64(m, "port"); found {
+					port = fmt.Sprint(p)
+				}
+			}
+
+			route.Backends = append(route.Backends, backends.backend(namespace, nestedString(ref, "name"), port))
+		}
+	}
+
+	return route
+}
+
+// refName returns a Gateway API object reference as namespace/name, defaulting to the referring namespace
+func refName(ref interface{}, namespace string) string {
+	if ns := nestedString(ref, "namespace"); ns != "" {
+		namespace = ns
+	}
+
+	return namespace + "/" + nestedString(ref, "name")
+}
+
+func nestedString(obj interface{}, field string) string {
+	m, ok := obj.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	s, _, _ := unstructured.NestedString(m, field)
+
+	return s
+}
@@ -0,0 +1,179 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+func TestNetwork(t *testing.T) {
+	className := "nginx"
+	webLabels := map[string]string{"app": "web"}
+
+	client := fake.NewSimpleClientset(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ingress-nginx"}},
+		&networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "deny-all", Namespace: "default"}},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: webLabels},
+				Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "web", Image: "nginx:1.25"}}},
+			}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "ingress-nginx-controller", Namespace: "ingress-nginx"},
+			Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: "controller", Image: "registry.k8s.io/ingress-nginx/controller:v1.9.5"}},
+			}}},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: v1.ServiceSpec{
+				Type:     v1.ServiceTypeLoadBalancer,
+				Selector: webLabels,
+				Ports:    []v1.ServicePort{{Protocol: v1.ProtocolTCP, Port: 80, NodePort: 30080, TargetPort: intstr.FromInt(8080)}},
+			},
+			Status: v1.ServiceStatus{LoadBalancer: v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "203.0.113.10"}}}},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "internal", Namespace: "default"},
+			Spec:       v1.ServiceSpec{Type: v1.ServiceTypeClusterIP, Selector: webLabels},
+		},
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: networkingv1.IngressSpec{
+				IngressClassName: &className,
+				TLS:              []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-tls"}},
+				Rules: []networkingv1.IngressRule{{
+					Host: "example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path: "/",
+							Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+								Name: "internal", Port: networkingv1.ServiceBackendPort{Number: 80},
+							}},
+						}},
+					}},
+				}},
+			},
+		},
+	)
+
+	gateway := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "Gateway",
+		"metadata":   map[string]interface{}{"name": "public", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"gatewayClassName": "envoy",
+			"listeners": []interface{}{
+				map[string]interface{}{
+					"name":     "https",
+					"port":     int64(443),
+					"protocol": "HTTPS",
+					"tls": map[string]interface{}{
+						"certificateRefs": []interface{}{map[string]interface{}{"name": "public-tls"}},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{
+			"addresses": []interface{}{map[string]interface{}{"type": "IPAddress", "value": "203.0.113.20"}},
+		},
+	}}
+	route := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"hostnames":  []interface{}{"www.example.com"},
+			"parentRefs": []interface{}{map[string]interface{}{"name": "public"}},
+			"rules": []interface{}{
+				map[string]interface{}{
+					"backendRefs": []interface{}{map[string]interface{}{"name": "web", "port": int64(80)}},
+				},
+			},
+		},
+	}}
+
+	listKinds := make(map[schema.GroupVersionResource]string)
+	listKinds[schema.GroupVersionResource{Group: gatewayAPIGroup, Version: "v1", Resource: "gateways"}] = "GatewayList"
+	for _, r := range gatewayRoutes {
+		for _, version := range r.versions {
+			listKinds[schema.GroupVersionResource{Group: gatewayAPIGroup, Version: version, Resource: r.resource}] = r.kind + "List"
+		}
+	}
+
+	k := &k8sDB{
+		client:        client,
+		dynamicClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, route),
+	}
+	// created through its resource, the fake client would otherwise guess the plural as "gatewaies"
+	gateways := schema.GroupVersionResource{Group: gatewayAPIGroup, Version: "v1", Resource: "gateways"}
+	_, err := k.dynamicClient.Resource(gateways).Namespace("default").Create(context.Background(), gateway, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	templates, err := k.PodTemplates(context.Background())
+	require.NoError(t, err)
+	res, err := k.Network(context.Background(), templates)
+	require.NoError(t, err)
+
+	web := []model.WorkloadRef{{Kind: "Deployment", Name: "web", Namespace: "default"}}
+
+	assert.Equal(t, []model.ExposedService{{
+		Name:        "web",
+		Namespace:   "default",
+		Type:        "LoadBalancer",
+		ExternalIPs: []string{"203.0.113.10"},
+		Ports:       []model.ServicePort{{Protocol: "TCP", Port: 80, NodePort: 30080, TargetPort: "8080"}},
+		Workloads:   web,
+	}}, res.ExposedServices)
+
+	assert.Equal(t, []model.Ingress{{
+		Name:      "web",
+		Namespace: "default",
+		ClassName: "nginx",
+		Hosts:     []string{"example.com"},
+		TLS:       []model.TLSConfig{{Hosts: []string{"example.com"}, SecretName: "example-tls"}},
+		Backends: []model.Backend{
+			{Host: "example.com", Path: "/", Service: "internal", Namespace: "default", Port: "80", Workloads: web},
+		},
+	}}, res.Ingresses)
+
+	assert.Equal(t, []model.Gateway{{
+		Name:      "public",
+		Namespace: "default",
+		ClassName: "envoy",
+		Addresses: []string{"203.0.113.20"},
+		Listeners: []model.Listener{{Name: "https", Port: 443, Protocol: "HTTPS", TLSSecrets: []string{"default/public-tls"}}},
+	}}, res.Gateways)
+
+	assert.Equal(t, []model.Route{{
+		Kind:      "HTTPRoute",
+		Name:      "web",
+		Namespace: "default",
+		Hostnames: []string{"www.example.com"},
+		Gateways:  []string{"default/public"},
+		Backends:  []model.Backend{{Service: "web", Namespace: "default", Port: "80", Workloads: web}},
+	}}, res.Routes)
+
+	require.Len(t, res.Controllers, 1)
+	assert.Equal(t, "ingress-nginx", res.Controllers[0].Name)
+	assert.Equal(t, "v1.9.5", res.Controllers[0].Version)
+
+	assert.Equal(t, []string{"ingress-nginx"}, res.NamespacesWithoutNetworkPolicy)
+}
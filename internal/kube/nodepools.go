@@ -0,0 +1,154 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+const unknownNodePool = "unknown"
+
+// nodePoolLabels are the node pool labels set by managed Kubernetes providers and Karpenter, checked in order
+var nodePoolLabels = []string{
+	"eks.amazonaws.com/nodegroup",
+	"cloud.google.com/gke-nodepool",
+	"kubernetes.azure.com/agentpool",
+	"karpenter.sh/nodepool",
+}
+
+// nodePool accumulates the nodes of a pool
+type nodePool struct {
+	pool            model.NodePool
+	instanceTypes   map[string]int
+	osImages        map[string]int
+	kernelVersions  map[string]int
+	kubeletVersions map[string]int
+	runtimeVersions map[string]int
+	capacity        v1.ResourceList
+	allocatable     v1.ResourceList
+}
+
+// NodePools groups the nodes by their provider node pool label, falling back to fallbackLabel when set.
+// Nodes without any of the labels are grouped into the "unknown" pool.
+func (k *k8sDB) NodePools(ctx context.Context, fallbackLabel string) ([]model.NodePool, error) {
+	nodes, err := k.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	labels := slices.Clone(nodePoolLabels)
+	if fallbackLabel != "" {
+		labels = append(labels, fallbackLabel)
+	}
+
+	pools := make(map[string]*nodePool)
+	for i := range nodes.Items {
+		n := &nodes.Items[i]
+		label, name := nodePoolOf(n.Labels, labels)
+
+		p, ok := pools[label+"/"+name]
+		if !ok {
+			p = &nodePool{
+				pool:            model.NodePool{Name: name, Label: label},
+				instanceTypes:   make(map[string]int),
+				osImages:        make(map[string]int),
+				kernelVersions:  make(map[string]int),
+				kubeletVersions: make(map[string]int),
+				runtimeVersions: make(map[string]int),
+				capacity:        make(v1.ResourceList),
+				allocatable:     make(v1.ResourceList),
+			}
+			pools[label+"/"+name] = p
+		}
+
+		p.add(n)
+	}
+
+	res := make([]model.NodePool, 0, len(pools))
+	for _, p := range pools {
+		res = append(res, p.build())
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Name != res[j].Name {
+			return res[i].Name < res[j].Name
+		}
+
+		return res[i].Label < res[j].Label
+	})
+
+	return res, nil
+}
+
+func nodePoolOf(nodeLabels map[string]string, labels []string) (string, string) {
+	for _, label := range labels {
+		if name, ok := nodeLabels[label]; ok && name != "" {
+			return label, name
+		}
+	}
+
+	return "", unknownNodePool
+}
+
+func (p *nodePool) add(n *v1.Node) {
+	p.pool.Nodes++
+	if instanceType := getLabelValue(n.Labels, "node.kubernetes.io/instance-type"); instanceType != "" {
+		p.instanceTypes[instanceType]++
+	}
+	p.osImages[n.Status.NodeInfo.OSImage]++
+	p.kernelVersions[n.Status.NodeInfo.KernelVersion]++
+	p.kubeletVersions[n.Status.NodeInfo.KubeletVersion]++
+	p.runtimeVersions[n.Status.NodeInfo.ContainerRuntimeVersion]++
+
+	addResources(p.capacity, n.Status.Capacity)
+	addResources(p.allocatable, n.Status.Allocatable)
+}
+
+func (p *nodePool) build() model.NodePool {
+	pool := p.pool
+	pool.InstanceTypes = valueCounts(p.instanceTypes)
+	pool.OsImages = valueCounts(p.osImages)
+	pool.KernelVersions = valueCounts(p.kernelVersions)
+	pool.KubeletVersions = valueCounts(p.kubeletVersions)
+	pool.RuntimeVersions = valueCounts(p.runtimeVersions)
+	pool.Capacity = toCapacity(p.capacity)
+	pool.Allocatable = toCapacity(p.allocatable)
+	pool.VersionSkew = len(p.osImages) > 1 || len(p.kernelVersions) > 1 || len(p.kubeletVersions) > 1 ||
+		len(p.runtimeVersions) > 1
+
+	return pool
+}
+
+func addResources(total, resources v1.ResourceList) {
+	for name, q := range resources {
+		sum := total[name]
+		sum.Add(q)
+		total[name] = sum
+	}
+}
+
+func toCapacity(resources v1.ResourceList) *model.Capacity {
+	return &model.Capacity{
+		CPU:              resources.Cpu().String(),
+		Memory:           resources.Memory().String(),
+		EphemeralStorage: resources.StorageEphemeral().String(),
+		Pods:             resources.Pods().String(),
+	}
+}
+
+func valueCounts(counts map[string]int) []model.ValueCount {
+	res := make([]model.ValueCount, 0, len(counts))
+	for value, count := range counts {
+		res = append(res, model.ValueCount{Value: value, Count: count})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Value < res[j].Value
+	})
+
+	return res
+}
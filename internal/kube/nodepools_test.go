@@ -0,0 +1,80 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+func TestNodePools(t *testing.T) {
+	node := func(name string, labels map[string]string, kubelet string) *v1.Node {
+		resources := v1.ResourceList{
+			v1.ResourceCPU:              resource.MustParse("2"),
+			v1.ResourceMemory:           resource.MustParse("4Gi"),
+			v1.ResourcePods:             resource.MustParse("110"),
+			v1.ResourceEphemeralStorage: resource.MustParse("20Gi"),
+		}
+
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status: v1.NodeStatus{
+				Capacity:    resources,
+				Allocatable: resources,
+				NodeInfo: v1.NodeSystemInfo{
+					OSImage:                 "Bottlerocket OS 1.19.0 (aws-k8s-1.29)",
+					KernelVersion:           "6.1.72",
+					KubeletVersion:          kubelet,
+					ContainerRuntimeVersion: "containerd://1.6.28+bottlerocket",
+				},
+			},
+		}
+	}
+
+	client := fake.NewSimpleClientset(
+		node("node-1", map[string]string{
+			"eks.amazonaws.com/nodegroup":      "general",
+			"node.kubernetes.io/instance-type": "m5.large",
+		}, "v1.29.0-eks-5e0fdde"),
+		node("node-2", map[string]string{
+			"eks.amazonaws.com/nodegroup":      "general",
+			"node.kubernetes.io/instance-type": "m5.xlarge",
+		}, "v1.28.5-eks-5e0fdde"),
+		node("node-3", map[string]string{"pool": "batch"}, "v1.29.0-eks-5e0fdde"),
+		node("node-4", nil, "v1.29.0-eks-5e0fdde"),
+	)
+
+	k := &k8sDB{client: client}
+	res, err := k.NodePools(context.Background(), "pool")
+	require.NoError(t, err)
+
+	require.Len(t, res, 3)
+	assert.Equal(t, "batch", res[0].Name)
+	assert.Equal(t, "pool", res[0].Label)
+	assert.False(t, res[0].VersionSkew)
+
+	assert.Equal(t, model.NodePool{
+		Name:            "general",
+		Label:           "eks.amazonaws.com/nodegroup",
+		Nodes:           2,
+		InstanceTypes:   []model.ValueCount{{Value: "m5.large", Count: 1}, {Value: "m5.xlarge", Count: 1}},
+		OsImages:        []model.ValueCount{{Value: "Bottlerocket OS 1.19.0 (aws-k8s-1.29)", Count: 2}},
+		KernelVersions:  []model.ValueCount{{Value: "6.1.72", Count: 2}},
+		KubeletVersions: []model.ValueCount{{Value: "v1.28.5-eks-5e0fdde", Count: 1}, {Value: "v1.29.0-eks-5e0fdde", Count: 1}},
+		RuntimeVersions: []model.ValueCount{{Value: "containerd://1.6.28+bottlerocket", Count: 2}},
+		Capacity:        &model.Capacity{CPU: "4", Memory: "8Gi", Pods: "220", EphemeralStorage: "40Gi"},
+		Allocatable:     &model.Capacity{CPU: "4", Memory: "8Gi", Pods: "220", EphemeralStorage: "40Gi"},
+		VersionSkew:     true,
+	}, res[1])
+
+	assert.Equal(t, "unknown", res[2].Name)
+	assert.Equal(t, "", res[2].Label)
+	assert.Equal(t, 1, res[2].Nodes)
+}
@@ -0,0 +1,218 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// SectionCore are the permissions of the metadata, nodes and images, SectionResources the ones of AllResources
+	SectionCore      = "core"
+	SectionResources = "resources"
+
+	verbList = "list"
+	verbGet  = "get"
+)
+
+// Permission is an API access generating the KBOM needs, in all namespaces unless Namespace is set.
+// Section is the part of the KBOM needing it, the core, the resources or an optional section.
+type Permission struct {
+	Section   string
+	Verb      string
+	Group     string
+	Resource  string
+	Namespace string
+	Name      string
+}
+
+func (p *Permission) String() string {
+	return p.Verb + " " + p.Target()
+}
+
+// Target returns the resource the permission is for, e.g. deployments.apps or kube-system/configmaps/kube-root-ca.crt
+func (p *Permission) Target() string {
+	resource := p.Resource
+	if p.Group != "" {
+		resource += "." + p.Group
+	}
+	if p.Name != "" {
+		resource += "/" + p.Name
+	}
+	if p.Namespace != "" {
+		resource = p.Namespace + "/" + resource
+	}
+
+	return resource
+}
+
+// CorePermissions are the permissions of Metadata, AllNodes, Location and AllImages
+var CorePermissions = []Permission{
+	{Section: SectionCore, Verb: verbList, Resource: "nodes"},
+	{Section: SectionCore, Verb: verbList, Resource: "namespaces"},
+	{Section: SectionCore, Verb: verbList, Resource: "pods"},
+	{Section: SectionCore, Verb: verbGet, Resource: "configmaps", Namespace: "kube-system", Name: "kube-root-ca.crt"},
+}
+
+// AllResourcesPermission lists every resource, which AllResources needs to collect resources that are not known upfront
+var AllResourcesPermission = Permission{Section: SectionResources, Verb: verbList, Group: rbacv1.APIGroupAll, Resource: rbacv1.ResourceAll}
+
+// ListPermissions returns list permissions for the given group resources
+func ListPermissions(section string, resources ...schema.GroupResource) []Permission {
+	res := make([]Permission, 0, len(resources))
+	for _, r := range resources {
+		res = append(res, Permission{Section: section, Verb: verbList, Group: r.Group, Resource: r.Resource})
+	}
+
+	return res
+}
+
+// PermissionCheck is the result of reviewing a permission for the current user
+type PermissionCheck struct {
+	Permission
+	Allowed bool
+	Reason  string
+}
+
+// AccessReviewer checks the permissions of the user the client is authenticated as
+type AccessReviewer struct {
+	client kubernetes.Interface
+}
+
+func NewAccessReviewer(client kubernetes.Interface) *AccessReviewer {
+	return &AccessReviewer{client: client}
+}
+
+// ResourcePermissions returns the list permission of every listable resource AllResources collects. Groups that fail
+// discovery, e.g. unavailable aggregated APIs, are skipped as they can not be listed either way.
+func (a *AccessReviewer) ResourcePermissions(_ context.Context) ([]Permission, error) {
+	apiResourceList, err := discovery.ServerPreferredResources(a.client.Discovery())
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, fmt.Errorf("failed to get api groups: %w", err)
+		}
+		log.Warn().Err(err).Msg("Skipping API groups that failed discovery")
+	}
+
+	var res []Permission
+	for _, apiResource := range apiResourceList {
+		gv, err := schema.ParseGroupVersion(apiResource.GroupVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse group version: %w", err)
+		}
+
+		for i := range apiResource.APIResources {
+			r := apiResource.APIResources[i]
+			if !isListable(&r) {
+				continue
+			}
+
+			res = append(res, ListPermissions(SectionResources, schema.GroupResource{Group: gv.Group, Resource: r.Name})...)
+		}
+	}
+
+	return res, nil
+}
+
+// Check reviews every permission with a SelfSubjectAccessReview
+func (a *AccessReviewer) Check(ctx context.Context, permissions []Permission) ([]PermissionCheck, error) {
+	res := make([]PermissionCheck, 0, len(permissions))
+	for _, p := range permissions {
+		review, err := a.client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Verb:      p.Verb,
+					Group:     p.Group,
+					Resource:  p.Resource,
+					Namespace: p.Namespace,
+					Name:      p.Name,
+				},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to review %s: %w", p.String(), err)
+		}
+
+		res = append(res, PermissionCheck{Permission: p, Allowed: review.Status.Allowed, Reason: review.Status.Reason})
+	}
+
+	return res, nil
+}
+
+// ClusterRole returns a ClusterRole granting the permissions, with a rule per API group and verb.
+// Permissions limited to a resource name keep a rule of their own with resourceNames.
+func ClusterRole(name string, permissions []Permission) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Rules:      policyRules(permissions),
+	}
+}
+
+// Role returns a Role granting the permissions in the namespace, with the rules of ClusterRole
+func Role(name, namespace string, permissions []Permission) *rbacv1.Role {
+	return &rbacv1.Role{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Rules:      policyRules(permissions),
+	}
+}
+
+// policyRules groups the permissions into a rule per API group, verb and resource name. List permissions covered by
+// AllResourcesPermission are left out.
+func policyRules(permissions []Permission) []rbacv1.PolicyRule {
+	type ruleKey struct {
+		group, verb, name string
+	}
+
+	listAll := slices.ContainsFunc(permissions, func(p Permission) bool {
+		return p.Verb == verbList && p.Group == rbacv1.APIGroupAll && p.Resource == rbacv1.ResourceAll
+	})
+
+	resources := make(map[ruleKey][]string)
+	for _, p := range permissions {
+		if listAll && p.Verb == verbList && p.Name == "" && p.Group != rbacv1.APIGroupAll {
+			continue
+		}
+
+		key := ruleKey{group: p.Group, verb: p.Verb, name: p.Name}
+		if !slices.Contains(resources[key], p.Resource) {
+			resources[key] = append(resources[key], p.Resource)
+		}
+	}
+
+	keys := make([]ruleKey, 0, len(resources))
+	for key := range resources {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		return strings.Join([]string{a.group, a.verb, a.name}, "/") < strings.Join([]string{b.group, b.verb, b.name}, "/")
+	})
+
+	rules := make([]rbacv1.PolicyRule, 0, len(keys))
+	for _, key := range keys {
+		slices.Sort(resources[key])
+		rule := rbacv1.PolicyRule{
+			APIGroups: []string{key.group},
+			Resources: resources[key],
+			Verbs:     []string{key.verb},
+		}
+		if key.name != "" {
+			rule.ResourceNames = []string{key.name}
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
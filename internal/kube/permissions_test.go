@@ -0,0 +1,110 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// denyResources makes SelfSubjectAccessReviews deny the given resources and allow everything else
+func denyResources(client *fake.Clientset, resources ...string) {
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		allowed := true
+		for _, r := range resources {
+			if review.Spec.ResourceAttributes.Resource == r {
+				allowed = false
+			}
+		}
+		review.Status.Allowed = allowed
+
+		return true, review, nil
+	})
+}
+
+func TestResourcePermissions(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "pods", Verbs: []string{"get", "list", "watch"}},
+			{Name: "bindings", Verbs: []string{"create"}},
+		}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{{Name: "deployments", Verbs: []string{"list"}}}},
+	}
+
+	permissions, err := NewAccessReviewer(client).ResourcePermissions(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Permission{
+		{Section: SectionResources, Verb: "list", Resource: "pods"},
+		{Section: SectionResources, Verb: "list", Group: "apps", Resource: "deployments"},
+	}, permissions)
+}
+
+func TestCheck(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	denyResources(client, "secrets")
+
+	checks, err := NewAccessReviewer(client).Check(context.Background(), []Permission{
+		{Verb: "list", Resource: "pods"},
+		{Verb: "list", Resource: "secrets"},
+	})
+	require.NoError(t, err)
+	require.Len(t, checks, 2)
+	assert.True(t, checks[0].Allowed)
+	assert.False(t, checks[1].Allowed)
+}
+
+func TestClusterRole(t *testing.T) {
+	role := ClusterRole("kbom", []Permission{
+		{Verb: "list", Resource: "pods"},
+		{Verb: "list", Group: "apps", Resource: "deployments"},
+		{Verb: "list", Resource: "nodes"},
+		{Verb: "list", Group: "apps", Resource: "daemonsets"},
+		{Verb: "get", Resource: "configmaps", Namespace: "kube-system", Name: "kube-root-ca.crt"},
+		{Verb: "list", Resource: "pods"},
+	})
+
+	assert.Equal(t, "kbom", role.Name)
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}, ResourceNames: []string{"kube-root-ca.crt"}},
+		{APIGroups: []string{""}, Resources: []string{"nodes", "pods"}, Verbs: []string{"list"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"daemonsets", "deployments"}, Verbs: []string{"list"}},
+	}, role.Rules)
+}
+
+func TestClusterRoleListAll(t *testing.T) {
+	permissions := append([]Permission{}, CorePermissions...)
+	permissions = append(permissions, Permission{Verb: "list", Group: "apps", Resource: "deployments"}, AllResourcesPermission)
+
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"list"}},
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}, ResourceNames: []string{"kube-root-ca.crt"}},
+	}, ClusterRole("kbom", permissions).Rules)
+}
+
+func TestRole(t *testing.T) {
+	role := Role("kbom", "kbom-system", []Permission{
+		{Verb: "get", Group: "coordination.k8s.io", Resource: "leases"},
+		{Verb: "update", Group: "coordination.k8s.io", Resource: "leases"},
+	})
+
+	assert.Equal(t, "Role", role.Kind)
+	assert.Equal(t, "kbom-system", role.Namespace)
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{"coordination.k8s.io"}, Resources: []string{"leases"}, Verbs: []string{"get"}},
+		{APIGroups: []string{"coordination.k8s.io"}, Resources: []string{"leases"}, Verbs: []string{"update"}},
+	}, role.Rules)
+}
+
+func TestPermissionString(t *testing.T) {
+	assert.Equal(t, "list deployments.apps", (&Permission{Verb: "list", Group: "apps", Resource: "deployments"}).String())
+	assert.Equal(t, "get kube-system/configmaps/kube-root-ca.crt", CorePermissions[3].String())
+}
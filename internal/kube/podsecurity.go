@@ -0,0 +1,190 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+const (
+	psaLabelPrefix           = "pod-security.kubernetes.io/"
+	appArmorAnnotationPrefix = "container.apparmor.security.beta.kubernetes.io/"
+	seccompUnset             = "Unset"
+)
+
+// PodSecurity returns the security-relevant pod spec fields of every workload, grouped by namespace
+// together with the namespace Pod Security Admission labels
+func (k *k8sDB) PodSecurity(ctx context.Context, podTemplates *PodTemplates) (*model.PodSecurity, error) {
+	namespaces, err := k.client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	templates := podTemplates.items
+
+	byNamespace := make(map[string][]model.WorkloadSecurity)
+	for i := range templates {
+		ws := workloadSecurity(&templates[i])
+		byNamespace[ws.Namespace] = append(byNamespace[ws.Namespace], ws)
+	}
+
+	res := &model.PodSecurity{Namespaces: make([]model.NamespaceSecurity, 0, len(namespaces.Items))}
+	for i := range namespaces.Items {
+		ns := namespaces.Items[i]
+		workloads := byNamespace[ns.Name]
+		if workloads == nil {
+			workloads = make([]model.WorkloadSecurity, 0)
+		}
+
+		res.Namespaces = append(res.Namespaces, model.NamespaceSecurity{
+			Name:                 ns.Name,
+			PodSecurityAdmission: podSecurityAdmission(ns.Labels),
+			Summary:              securitySummary(workloads),
+			Workloads:            workloads,
+		})
+	}
+
+	return res, nil
+}
+
+func podSecurityAdmission(labels map[string]string) model.PodSecurityAdmission {
+	return model.PodSecurityAdmission{
+		Enforce:        labels[psaLabelPrefix+"enforce"],
+		EnforceVersion: labels[psaLabelPrefix+"enforce-version"],
+		Audit:          labels[psaLabelPrefix+"audit"],
+		AuditVersion:   labels[psaLabelPrefix+"audit-version"],
+		Warn:           labels[psaLabelPrefix+"warn"],
+		WarnVersion:    labels[psaLabelPrefix+"warn-version"],
+	}
+}
+
+func workloadSecurity(t *podTemplate) model.WorkloadSecurity {
+	spec := t.spec
+	ws := model.WorkloadSecurity{
+		Kind:                   t.kind,
+		Name:                   t.meta.Name,
+		Namespace:              t.meta.Namespace,
+		HostNetwork:            spec.HostNetwork,
+		HostPID:                spec.HostPID,
+		HostIPC:                spec.HostIPC,
+		RunAsNonRoot:           true,
+		ReadOnlyRootFilesystem: true,
+	}
+
+	for _, vol := range spec.Volumes {
+		if vol.HostPath != nil {
+			ws.HostPathMounts = append(ws.HostPathMounts, vol.HostPath.Path)
+		}
+	}
+
+	podSC := spec.SecurityContext
+	if podSC == nil {
+		podSC = &v1.PodSecurityContext{}
+	}
+
+	containers := append(slices.Clone(spec.InitContainers), spec.Containers...)
+	for i := range containers {
+		c := containers[i]
+		sc := c.SecurityContext
+		if sc == nil {
+			sc = &v1.SecurityContext{}
+		}
+
+		if sc.Privileged != nil && *sc.Privileged {
+			ws.Privileged = true
+		}
+
+		if sc.Capabilities != nil {
+			for _, capability := range sc.Capabilities.Add {
+				ws.AddedCapabilities = appendUnique(ws.AddedCapabilities, string(capability))
+			}
+		}
+
+		if !runsAsNonRoot(podSC, sc) {
+			ws.RunAsNonRoot = false
+		}
+
+		if sc.ReadOnlyRootFilesystem == nil || !*sc.ReadOnlyRootFilesystem {
+			ws.ReadOnlyRootFilesystem = false
+		}
+
+		ws.SeccompProfiles = appendUnique(ws.SeccompProfiles, seccompProfile(podSC.SeccompProfile, sc.SeccompProfile))
+
+		if profile, ok := t.annotations[appArmorAnnotationPrefix+c.Name]; ok {
+			ws.AppArmorProfiles = appendUnique(ws.AppArmorProfiles, profile)
+		}
+	}
+
+	return ws
+}
+
+func runsAsNonRoot(podSC *v1.PodSecurityContext, sc *v1.SecurityContext) bool {
+	nonRoot, user := podSC.RunAsNonRoot, podSC.RunAsUser
+	if sc.RunAsNonRoot != nil {
+		nonRoot = sc.RunAsNonRoot
+	}
+	if sc.RunAsUser != nil {
+		user = sc.RunAsUser
+	}
+
+	return (nonRoot != nil && *nonRoot) || (user != nil && *user != 0)
+}
+
+func seccompProfile(podProfile, containerProfile *v1.SeccompProfile) string {
+	profile := podProfile
+	if containerProfile != nil {
+		profile = containerProfile
+	}
+
+	if profile == nil {
+		return seccompUnset
+	}
+
+	if profile.Type == v1.SeccompProfileTypeLocalhost && profile.LocalhostProfile != nil {
+		return fmt.Sprintf("%s/%s", profile.Type, *profile.LocalhostProfile)
+	}
+
+	return string(profile.Type)
+}
+
+func securitySummary(workloads []model.WorkloadSecurity) model.SecuritySummary {
+	summary := model.SecuritySummary{Workloads: len(workloads)}
+	for i := range workloads {
+		w := workloads[i]
+		summary.Privileged += boolToInt(w.Privileged)
+		summary.HostNamespaces += boolToInt(w.HostNetwork || w.HostPID || w.HostIPC)
+		summary.HostPath += boolToInt(len(w.HostPathMounts) > 0)
+		summary.AddedCapabilities += boolToInt(len(w.AddedCapabilities) > 0)
+		summary.RunAsRoot += boolToInt(!w.RunAsNonRoot)
+		summary.WritableRootFilesystem += boolToInt(!w.ReadOnlyRootFilesystem)
+		summary.SeccompUnconfined += boolToInt(slices.Contains(w.SeccompProfiles, seccompUnset) ||
+			slices.Contains(w.SeccompProfiles, string(v1.SeccompProfileTypeUnconfined)))
+	}
+
+	return summary
+}
+
+func appendUnique(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+
+	values = append(values, value)
+	sort.Strings(values)
+
+	return values
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,142 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+func TestPodSecurity(t *testing.T) {
+	restricted := v1.PodSpec{
+		SecurityContext: &v1.PodSecurityContext{
+			RunAsNonRoot:   ptr.To(true),
+			SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault},
+		},
+		Containers: []v1.Container{
+			{
+				Name:            "app",
+				SecurityContext: &v1.SecurityContext{ReadOnlyRootFilesystem: ptr.To(true)},
+			},
+		},
+	}
+
+	privileged := v1.PodSpec{
+		HostNetwork: true,
+		Volumes: []v1.Volume{
+			{Name: "root", VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: "/"}}},
+		},
+		InitContainers: []v1.Container{{Name: "init", SecurityContext: &v1.SecurityContext{RunAsUser: ptr.To[int64](0)}}},
+		Containers: []v1.Container{
+			{
+				Name: "agent",
+				SecurityContext: &v1.SecurityContext{
+					Privileged:   ptr.To(true),
+					Capabilities: &v1.Capabilities{Add: []v1.Capability{"SYS_ADMIN", "NET_ADMIN"}},
+				},
+			},
+		},
+	}
+
+	client := fake.NewSimpleClientset(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app", Labels: map[string]string{
+			"pod-security.kubernetes.io/enforce":         "restricted",
+			"pod-security.kubernetes.io/enforce-version": "latest",
+		}}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "monitoring"}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "empty"}},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+			Spec:       appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: restricted}},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-agent", Namespace: "monitoring"},
+			Spec: appsv1.DaemonSetSpec{Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					"container.apparmor.security.beta.kubernetes.io/agent": "unconfined",
+				}},
+				Spec: privileged,
+			}},
+		},
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "app", UID: "cronjob-uid"},
+			Spec: batchv1.CronJobSpec{JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{
+				Template: v1.PodTemplateSpec{Spec: restricted},
+			}}},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "backup-1", Namespace: "app", OwnerReferences: []metav1.OwnerReference{
+				{Kind: "CronJob", Name: "backup", UID: "cronjob-uid", Controller: ptr.To(true)},
+			}},
+			Spec: batchv1.JobSpec{Template: v1.PodTemplateSpec{Spec: restricted}},
+		},
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "app", OwnerReferences: []metav1.OwnerReference{
+				{Kind: "Deployment", Name: "web", Controller: ptr.To(true)},
+			}},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "app", OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ReplicaSet", Name: "web-1", Controller: ptr.To(true)},
+			}},
+			Spec: restricted,
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "app"},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "debug"}}},
+		},
+	)
+
+	k := &k8sDB{client: client}
+	templates, err := k.PodTemplates(context.Background())
+	require.NoError(t, err)
+	res, err := k.PodSecurity(context.Background(), templates)
+	require.NoError(t, err)
+	require.Len(t, res.Namespaces, 3)
+
+	app := res.Namespaces[0]
+	assert.Equal(t, "app", app.Name)
+	assert.Equal(t, model.PodSecurityAdmission{Enforce: "restricted", EnforceVersion: "latest"}, app.PodSecurityAdmission)
+	assert.Equal(t, model.SecuritySummary{
+		Workloads:              3,
+		RunAsRoot:              1,
+		WritableRootFilesystem: 1,
+		SeccompUnconfined:      1,
+	}, app.Summary)
+
+	assert.Equal(t, "empty", res.Namespaces[1].Name)
+	assert.Empty(t, res.Namespaces[1].Workloads)
+
+	monitoring := res.Namespaces[2]
+	require.Len(t, monitoring.Workloads, 1)
+	assert.Equal(t, model.WorkloadSecurity{
+		Kind:              "DaemonSet",
+		Name:              "node-agent",
+		Namespace:         "monitoring",
+		Privileged:        true,
+		HostNetwork:       true,
+		HostPathMounts:    []string{"/"},
+		AddedCapabilities: []string{"NET_ADMIN", "SYS_ADMIN"},
+		SeccompProfiles:   []string{"Unset"},
+		AppArmorProfiles:  []string{"unconfined"},
+	}, monitoring.Workloads[0])
+	assert.Equal(t, model.SecuritySummary{
+		Workloads:              1,
+		Privileged:             1,
+		HostNamespaces:         1,
+		HostPath:               1,
+		AddedCapabilities:      1,
+		RunAsRoot:              1,
+		WritableRootFilesystem: 1,
+		SeccompUnconfined:      1,
+	}, monitoring.Summary)
+}
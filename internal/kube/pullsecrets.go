@@ -0,0 +1,172 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+const (
+	pullSecretViaPod            = "pod"
+	pullSecretViaServiceAccount = "serviceaccount"
+)
+
+// pullSecretTypes are the secret types the kubelet accepts as image pull secrets
+var pullSecretTypes = []v1.SecretType{v1.SecretTypeDockerConfigJson, v1.SecretTypeDockercfg}
+
+// PullSecrets returns the image pull secrets with the registries they target and the workloads using them,
+// either directly or through their ServiceAccount, including referenced secrets that do not exist
+func (k *k8sDB) PullSecrets(ctx context.Context, podTemplates *PodTemplates) (*model.PullSecrets, error) {
+	secrets := make(map[string]*model.PullSecret)
+	for _, secretType := range pullSecretTypes {
+		list, err := k.client.CoreV1().Secrets("").List(ctx, metav1.ListOptions{FieldSelector: "type=" + string(secretType)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s secrets: %w", secretType, err)
+		}
+
+		for i := range list.Items {
+			s := &list.Items[i]
+			if s.Type != secretType {
+				continue
+			}
+
+			secrets[s.Namespace+"/"+s.Name] = &model.PullSecret{
+				Name:       s.Name,
+				Namespace:  s.Namespace,
+				Type:       string(s.Type),
+				Registries: dockerConfigRegistries(s),
+			}
+		}
+	}
+
+	serviceAccounts, err := k.client.CoreV1().ServiceAccounts("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+
+	accountSecrets := make(map[string][]v1.LocalObjectReference)
+	for i := range serviceAccounts.Items {
+		sa := &serviceAccounts.Items[i]
+		accountSecrets[sa.Namespace+"/"+sa.Name] = sa.ImagePullSecrets
+		for _, ref := range sa.ImagePullSecrets {
+			s := referencedSecret(secrets, sa.Namespace, ref.Name)
+			s.ServiceAccounts = appendUnique(s.ServiceAccounts, sa.Name)
+		}
+	}
+
+	templates := podTemplates.items
+
+	for i := range templates {
+		t := &templates[i]
+		addPullSecretUsers(secrets, t, t.spec.ImagePullSecrets, pullSecretViaPod)
+
+		serviceAccount := t.spec.ServiceAccountName
+		if serviceAccount == "" {
+			serviceAccount = defaultServiceAccount
+		}
+		addPullSecretUsers(secrets, t, accountSecrets[t.meta.Namespace+"/"+serviceAccount], pullSecretViaServiceAccount)
+	}
+
+	res := &model.PullSecrets{Secrets: make([]model.PullSecret, 0, len(secrets))}
+	for _, s := range secrets {
+		res.Secrets = append(res.Secrets, *s)
+	}
+	sort.Slice(res.Secrets, func(i, j int) bool {
+		if res.Secrets[i].Namespace != res.Secrets[j].Namespace {
+			return res.Secrets[i].Namespace < res.Secrets[j].Namespace
+		}
+
+		return res.Secrets[i].Name < res.Secrets[j].Name
+	})
+
+	return res, nil
+}
+
+// referencedSecret returns the pull secret, recording it as missing when it does not exist
+func referencedSecret(secrets map[string]*model.PullSecret, namespace, name string) *model.PullSecret {
+	key := namespace + "/" + name
+	s, ok := secrets[key]
+	if !ok {
+		s = &model.PullSecret{Name: name, Namespace: namespace, Missing: true}
+		secrets[key] = s
+	}
+
+	return s
+}
+
+func addPullSecretUsers(secrets map[string]*model.PullSecret, t *podTemplate, refs []v1.LocalObjectReference, via string) {
+	for _, ref := range refs {
+		if ref.Name == "" {
+			continue
+		}
+
+		s := referencedSecret(secrets, t.meta.Namespace, ref.Name)
+		s.Workloads = append(s.Workloads, model.PullSecretUser{
+			Kind:      t.kind,
+			Name:      t.meta.Name,
+			Namespace: t.meta.Namespace,
+			Via:       via,
+		})
+	}
+}
+
+// dockerConfigRegistries returns the registry hostnames of a .dockerconfigjson secret, whose registries are the keys of
+// its auths object, or of a legacy .dockercfg secret, which is a flat map of the registries. Only the keys are decoded,
+// the credentials are skipped.
+func dockerConfigRegistries(s *v1.Secret) []string {
+	var auths map[string]json.RawMessage
+	if data, ok := s.Data[v1.DockerConfigJsonKey]; ok {
+		var config struct {
+			Auths map[string]json.RawMessage `json:"auths"`
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			log.Debug().Err(err).Str("namespace", s.Namespace).Str("name", s.Name).Msg("Failed to parse docker config")
+			return nil
+		}
+		auths = config.Auths
+	} else if data, ok := s.Data[v1.DockerConfigKey]; ok {
+		if err := json.Unmarshal(data, &auths); err != nil {
+			log.Debug().Err(err).Str("namespace", s.Namespace).Str("name", s.Name).Msg("Failed to parse docker config")
+			return nil
+		}
+	}
+
+	var registries []string
+	for key := range auths {
+		if registry := registryHost(key); registry != "" {
+			registries = appendUnique(registries, registry)
+		}
+	}
+
+	return registries
+}
+
+// registryHost returns the hostname of a docker config key, which may be a bare host or a URL,
+// using docker.io for the legacy Docker Hub index so it matches the image registries
+func registryHost(key string) string {
+	host := key
+	if strings.Contains(key, "://") {
+		u, err := url.Parse(key)
+		if err != nil {
+			return ""
+		}
+		host = u.Host
+	}
+	host, _, _ = strings.Cut(host, "/")
+
+	switch host {
+	case "index.docker.io", "registry-1.docker.io":
+		return "docker.io"
+	}
+
+	return host
+}
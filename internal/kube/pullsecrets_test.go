@@ -0,0 +1,81 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+func TestPullSecrets(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "regcred", Namespace: "apps"},
+			Type:       v1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{v1.DockerConfigJsonKey: []byte(`{"auths": {
+				"https://index.docker.io/v1/": {"auth": "c2VjcmV0"},
+				"ghcr.io": {"auth": "c2VjcmV0"},
+				"123456789012.dkr.ecr.eu-west-1.amazonaws.com": {"auth": "c2VjcmV0"}
+			}}`)},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "apps"},
+			Type:       v1.SecretTypeDockercfg,
+			Data: map[string][]byte{v1.DockerConfigKey: []byte(`{
+				"quay.io": {"auth": "c2VjcmV0"},
+				"https://registry.example.com/v1/": {"auth": "c2VjcmV0"}
+			}`)},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: "apps"},
+			Type:       v1.SecretTypeTLS,
+		},
+		&v1.ServiceAccount{
+			ObjectMeta:       metav1.ObjectMeta{Name: "default", Namespace: "apps"},
+			ImagePullSecrets: []v1.LocalObjectReference{{Name: "regcred"}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "apps"},
+			Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				ImagePullSecrets: []v1.LocalObjectReference{{Name: "old-regcred"}},
+				Containers:       []v1.Container{{Name: "api", Image: "ghcr.io/example/api:1.0"}},
+			}}},
+		},
+	)
+
+	k := &k8sDB{client: client}
+	templates, err := k.PodTemplates(context.Background())
+	require.NoError(t, err)
+	res, err := k.PullSecrets(context.Background(), templates)
+	require.NoError(t, err)
+
+	assert.Equal(t, []model.PullSecret{
+		{
+			Name:       "legacy",
+			Namespace:  "apps",
+			Type:       string(v1.SecretTypeDockercfg),
+			Registries: []string{"quay.io", "registry.example.com"},
+		},
+		{
+			Name:      "old-regcred",
+			Namespace: "apps",
+			Missing:   true,
+			Workloads: []model.PullSecretUser{{Kind: "Deployment", Name: "api", Namespace: "apps", Via: "pod"}},
+		},
+		{
+			Name:            "regcred",
+			Namespace:       "apps",
+			Type:            string(v1.SecretTypeDockerConfigJson),
+			Registries:      []string{"123456789012.dkr.ecr.eu-west-1.amazonaws.com", "docker.io", "ghcr.io"},
+			ServiceAccounts: []string{"default"},
+			Workloads:       []model.PullSecretUser{{Kind: "Deployment", Name: "api", Namespace: "apps", Via: "serviceaccount"}},
+		},
+	}, res.Secrets)
+}
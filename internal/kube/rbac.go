@@ -0,0 +1,238 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+const (
+	clusterRoleKind        = "ClusterRole"
+	roleKind               = "Role"
+	clusterRoleBindingKind = "ClusterRoleBinding"
+	roleBindingKind        = "RoleBinding"
+)
+
+// RBAC returns all ClusterRoles, Roles and their bindings resolved to subjects, with high-risk grants flagged
+func (k *k8sDB) RBAC(ctx context.Context) (*model.RBAC, error) {
+	clusterRoles, err := k.client.RbacV1().ClusterRoles().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster roles: %w", err)
+	}
+
+	roles, err := k.client.RbacV1().Roles("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	clusterRoleBindings, err := k.client.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster role bindings: %w", err)
+	}
+
+	roleBindings, err := k.client.RbacV1().RoleBindings("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role bindings: %w", err)
+	}
+
+	rbac := &model.RBAC{
+		ClusterRoles: make([]model.Role, 0, len(clusterRoles.Items)),
+		Roles:        make([]model.Role, 0, len(roles.Items)),
+		Bindings:     make([]model.RoleBinding, 0, len(clusterRoleBindings.Items)+len(roleBindings.Items)),
+	}
+
+	// role risks keyed by kind/namespace/name, used to resolve risks of the bindings
+	risks := make(map[string][]string)
+	for i := range clusterRoles.Items {
+		role := toRole(clusterRoleKind, &clusterRoles.Items[i].ObjectMeta, clusterRoles.Items[i].Rules)
+		risks[roleKey(clusterRoleKind, "", role.Name)] = role.Risks
+		rbac.ClusterRoles = append(rbac.ClusterRoles, role)
+	}
+
+	for i := range roles.Items {
+		role := toRole(roleKind, &roles.Items[i].ObjectMeta, roles.Items[i].Rules)
+		risks[roleKey(roleKind, role.Namespace, role.Name)] = role.Risks
+		rbac.Roles = append(rbac.Roles, role)
+	}
+
+	for i := range clusterRoleBindings.Items {
+		b := clusterRoleBindings.Items[i]
+		rbac.Bindings = append(rbac.Bindings, toRoleBinding(clusterRoleBindingKind, &b.ObjectMeta, b.RoleRef, b.Subjects))
+	}
+
+	for i := range roleBindings.Items {
+		b := roleBindings.Items[i]
+		rbac.Bindings = append(rbac.Bindings, toRoleBinding(roleBindingKind, &b.ObjectMeta, b.RoleRef, b.Subjects))
+	}
+
+	rbac.Subjects = subjectAccess(rbac.Bindings, risks)
+
+	return rbac, nil
+}
+
+func toRole(kind string, meta *metav1.ObjectMeta, rules []rbacv1.PolicyRule) model.Role {
+	role := model.Role{
+		Kind:      kind,
+		Name:      meta.Name,
+		Namespace: meta.Namespace,
+		Rules:     make([]model.PolicyRule, 0, len(rules)),
+	}
+
+	for i := range rules {
+		role.Rules = append(role.Rules, model.PolicyRule{
+			APIGroups:       rules[i].APIGroups,
+			Resources:       rules[i].Resources,
+			ResourceNames:   rules[i].ResourceNames,
+			NonResourceURLs: rules[i].NonResourceURLs,
+			Verbs:           rules[i].Verbs,
+		})
+	}
+
+	role.Risks = ruleRisks(rules)
+
+	return role
+}
+
+func toRoleBinding(kind string, meta *metav1.ObjectMeta, roleRef rbacv1.RoleRef, subjects []rbacv1.Subject) model.RoleBinding {
+	binding := model.RoleBinding{
+		Kind:      kind,
+		Name:      meta.Name,
+		Namespace: meta.Namespace,
+		RoleRef:   model.RoleRef{Kind: roleRef.Kind, Name: roleRef.Name},
+		Subjects:  make([]model.Subject, 0, len(subjects)),
+	}
+
+	for _, s := range subjects {
+		namespace := s.Namespace
+		if s.Kind == rbacv1.ServiceAccountKind && namespace == "" {
+			namespace = meta.Namespace
+		}
+
+		binding.Subjects = append(binding.Subjects, model.Subject{
+			Kind:      s.Kind,
+			Name:      s.Name,
+			Namespace: namespace,
+		})
+	}
+
+	return binding
+}
+
+// subjectAccess inverts the bindings into the list of grants per subject
+func subjectAccess(bindings []model.RoleBinding, risks map[string][]string) []model.SubjectAccess {
+	subjects := make(map[model.Subject]*model.SubjectAccess)
+	for _, b := range bindings {
+		roleNamespace := ""
+		if b.RoleRef.Kind == roleKind {
+			roleNamespace = b.Namespace
+		}
+		grantRisks := risks[roleKey(b.RoleRef.Kind, roleNamespace, b.RoleRef.Name)]
+
+		for _, s := range b.Subjects {
+			access, ok := subjects[s]
+			if !ok {
+				access = &model.SubjectAccess{Subject: s, Grants: make([]model.Grant, 0)}
+				subjects[s] = access
+			}
+
+			access.Grants = append(access.Grants, model.Grant{
+				Role:      b.RoleRef,
+				Binding:   b.Name,
+				Namespace: b.Namespace,
+				Risks:     grantRisks,
+			})
+			access.Risks = mergeRisks(access.Risks, grantRisks)
+		}
+	}
+
+	res := make([]model.SubjectAccess, 0, len(subjects))
+	for _, access := range subjects {
+		res = append(res, *access)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return subjectKey(res[i].Subject) < subjectKey(res[j].Subject)
+	})
+
+	return res
+}
+
+// ruleRisks returns the high-risk grants found in the rules
+func ruleRisks(rules []rbacv1.PolicyRule) []string {
+	var risks []string
+	for i := range rules {
+		rule := rules[i]
+
+		if slices.Contains(rule.Verbs, rbacv1.VerbAll) {
+			risks = mergeRisks(risks, []string{model.RiskWildcardVerbs})
+		}
+
+		for _, verb := range []string{model.RiskEscalate, model.RiskBind, model.RiskImpersonate} {
+			if slices.Contains(rule.Verbs, verb) {
+				risks = mergeRisks(risks, []string{verb})
+			}
+		}
+
+		if hasAnyVerb(rule, "get", "list", "watch") && matchesResource(rule, "", "secrets") {
+			risks = mergeRisks(risks, []string{model.RiskSecretsRead})
+		}
+
+		if hasAnyVerb(rule, "get", "create") && matchesResource(rule, "", "nodes/proxy") {
+			risks = mergeRisks(risks, []string{model.RiskNodesProxy})
+		}
+	}
+
+	return risks
+}
+
+func hasAnyVerb(rule rbacv1.PolicyRule, verbs ...string) bool {
+	for _, v := range rule.Verbs {
+		if v == rbacv1.VerbAll || slices.Contains(verbs, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesResource(rule rbacv1.PolicyRule, group, resource string) bool {
+	if !slices.Contains(rule.APIGroups, group) && !slices.Contains(rule.APIGroups, rbacv1.APIGroupAll) {
+		return false
+	}
+
+	parent, _, _ := strings.Cut(resource, "/")
+	for _, r := range rule.Resources {
+		if r == rbacv1.ResourceAll || r == resource || r == parent+"/*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func mergeRisks(risks, other []string) []string {
+	for _, r := range other {
+		if !slices.Contains(risks, r) {
+			risks = append(risks, r)
+		}
+	}
+
+	slices.Sort(risks)
+
+	return risks
+}
+
+func roleKey(kind, namespace, name string) string {
+	return strings.Join([]string{kind, namespace, name}, "/")
+}
+
+func subjectKey(s model.Subject) string {
+	return strings.Join([]string{s.Kind, s.Namespace, s.Name}, "/")
+}
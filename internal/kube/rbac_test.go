@@ -0,0 +1,86 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+func TestRBAC(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "admin"},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+			},
+		},
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-reader"},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"nodes", "nodes/*"}, Verbs: []string{"get"}},
+				{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"clusterroles"}, Verbs: []string{"bind", "escalate"}},
+			},
+		},
+		&rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "secret-reader", Namespace: "app"},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"list"}},
+				{APIGroups: []string{""}, Resources: []string{"users"}, Verbs: []string{"impersonate"}},
+			},
+		},
+		&rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "configmap-reader", Namespace: "app"},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}},
+			},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "admins"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+			Subjects:   []rbacv1.Subject{{Kind: "Group", Name: "ops"}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-secrets", Namespace: "app"},
+			RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "secret-reader"},
+			Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "app"}, {Kind: "Group", Name: "ops"}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-configmaps", Namespace: "app"},
+			RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "configmap-reader"},
+			Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "app", Namespace: "app"}},
+		},
+	)
+
+	k := &k8sDB{client: client}
+	rbac, err := k.RBAC(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, rbac.ClusterRoles, 2)
+	assert.Equal(t, []string{model.RiskNodesProxy, model.RiskSecretsRead, model.RiskWildcardVerbs}, rbac.ClusterRoles[0].Risks)
+	assert.Equal(t, []string{model.RiskBind, model.RiskEscalate, model.RiskNodesProxy}, rbac.ClusterRoles[1].Risks)
+
+	require.Len(t, rbac.Roles, 2)
+	assert.Empty(t, rbac.Roles[0].Risks)
+	assert.Equal(t, []string{model.RiskImpersonate, model.RiskSecretsRead}, rbac.Roles[1].Risks)
+
+	require.Len(t, rbac.Bindings, 3)
+	assert.Equal(t, "app-secrets", rbac.Bindings[2].Name)
+	assert.Equal(t, "app", rbac.Bindings[2].Subjects[0].Namespace, "ServiceAccount namespace defaults to the binding namespace")
+
+	require.Len(t, rbac.Subjects, 2)
+	assert.Equal(t, model.Subject{Kind: "Group", Name: "ops"}, rbac.Subjects[0].Subject)
+	assert.Len(t, rbac.Subjects[0].Grants, 2)
+	assert.Equal(t, []string{model.RiskImpersonate, model.RiskNodesProxy, model.RiskSecretsRead, model.RiskWildcardVerbs},
+		rbac.Subjects[0].Risks)
+
+	assert.Equal(t, model.Subject{Kind: "ServiceAccount", Name: "app", Namespace: "app"}, rbac.Subjects[1].Subject)
+	assert.Len(t, rbac.Subjects[1].Grants, 2)
+	assert.Equal(t, []string{model.RiskImpersonate, model.RiskSecretsRead}, rbac.Subjects[1].Risks)
+}
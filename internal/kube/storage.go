@@ -0,0 +1,337 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/distribution/reference"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+const (
+	defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+	csiControllerComponent = "controller"
+	csiNodeComponent       = "node"
+)
+
+// csiSidecars are the image names of the kubernetes-csi sidecars deployed next to every CSI driver
+var csiSidecars = map[string]bool{
+	"csi-provisioner":                        true,
+	"csi-attacher":                           true,
+	"csi-resizer":                            true,
+	"csi-snapshotter":                        true,
+	"csi-node-driver-registrar":              true,
+	"csi-external-health-monitor-controller": true,
+	"livenessprobe":                          true,
+}
+
+// csiPluginDir matches the kubelet plugin directory a node plugin registers its socket in
+var csiPluginDir = regexp.MustCompile(`/var/lib/kubelet/plugins/([^/\s]+)`)
+
+// Storage returns the CSI drivers with their plugin workloads, StorageClasses and volume summaries
+func (k *k8sDB) Storage(ctx context.Context, podTemplates *PodTemplates) (*model.Storage, error) {
+	drivers, err := k.client.StorageV1().CSIDrivers().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list csi drivers: %w", err)
+	}
+
+	classes, err := k.client.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage classes: %w", err)
+	}
+
+	volumes, err := k.client.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list persistent volumes: %w", err)
+	}
+
+	claims, err := k.client.CoreV1().PersistentVolumeClaims("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list persistent volume claims: %w", err)
+	}
+
+	templates := podTemplates.items
+
+	res := &model.Storage{
+		CSIDrivers:        csiDrivers(drivers.Items, templates),
+		StorageClasses:    make([]model.StorageClass, 0, len(classes.Items)),
+		PersistentVolumes: persistentVolumeSummary(volumes.Items),
+		Claims:            claimSummaries(claims.Items),
+	}
+
+	for i := range classes.Items {
+		res.StorageClasses = append(res.StorageClasses, toStorageClass(&classes.Items[i]))
+	}
+
+	return res, nil
+}
+
+func csiDrivers(drivers []storagev1.CSIDriver, templates []podTemplate) []model.CSIDriver {
+	names := make(map[string]bool, len(drivers))
+	for i := range drivers {
+		names[drivers[i].Name] = true
+	}
+
+	plugins := csiPlugins(templates, names)
+
+	res := make([]model.CSIDriver, 0, len(drivers))
+	for i := range drivers {
+		d := &drivers[i]
+		driver := model.CSIDriver{
+			Name:           d.Name,
+			AttachRequired: d.Spec.AttachRequired == nil || *d.Spec.AttachRequired,
+			PodInfoOnMount: d.Spec.PodInfoOnMount != nil && *d.Spec.PodInfoOnMount,
+			Plugins:        plugins[d.Name],
+		}
+
+		if driver.Plugins == nil {
+			driver.Plugins = make([]model.CSIPlugin, 0)
+		}
+
+		for _, mode := range d.Spec.VolumeLifecycleModes {
+			driver.LifecycleModes = append(driver.LifecycleModes, string(mode))
+		}
+
+		for _, p := range driver.Plugins {
+			for _, c := range p.Containers {
+				if !c.Sidecar && driver.Version == "" {
+					driver.Version = c.Version
+				}
+			}
+		}
+
+		res = append(res, driver)
+	}
+
+	return res
+}
+
+// csiPlugins returns the workloads running CSI sidecars grouped by the driver they serve. The driver is
+// recognized from the kubelet plugin directory or a driver name argument; workloads without one are
+// attributed to the only driver found in the same namespace.
+func csiPlugins(templates []podTemplate, drivers map[string]bool) map[string][]model.CSIPlugin {
+	res := make(map[string][]model.CSIPlugin)
+	unresolved := make([]model.CSIPlugin, 0)
+	namespaceDrivers := make(map[string][]string)
+
+	for i := range templates {
+		t := &templates[i]
+		plugin, ok := toCSIPlugin(t)
+		if !ok {
+			continue
+		}
+
+		driver := csiDriverName(t, drivers)
+		if driver == "" {
+			unresolved = append(unresolved, plugin)
+			continue
+		}
+
+		res[driver] = append(res[driver], plugin)
+		namespaceDrivers[t.meta.Namespace] = appendUnique(namespaceDrivers[t.meta.Namespace], driver)
+	}
+
+	for _, plugin := range unresolved {
+		if d := namespaceDrivers[plugin.Namespace]; len(d) == 1 {
+			res[d[0]] = append(res[d[0]], plugin)
+		}
+	}
+
+	for _, plugins := range res {
+		sort.Slice(plugins, func(i, j int) bool {
+			if plugins[i].Component != plugins[j].Component {
+				return plugins[i].Component < plugins[j].Component
+			}
+
+			return plugins[i].Namespace+plugins[i].Workload < plugins[j].Namespace+plugins[j].Workload
+		})
+	}
+
+	return res
+}
+
+func toCSIPlugin(t *podTemplate) (model.CSIPlugin, bool) {
+	plugin := model.CSIPlugin{
+		Component:  csiControllerComponent,
+		Namespace:  t.meta.Namespace,
+		Workload:   fmt.Sprintf("%s/%s", t.kind, t.meta.Name),
+		Containers: make([]model.CSIContainer, 0, len(t.spec.Containers)),
+	}
+
+	if t.kind == daemonSetKind {
+		plugin.Component = csiNodeComponent
+	}
+
+	hasSidecar := false
+	for _, c := range t.spec.Containers {
+		name, version := imageNameAndTag(c.Image)
+		sidecar := csiSidecars[path.Base(name)]
+		hasSidecar = hasSidecar || sidecar
+		plugin.Containers = append(plugin.Containers, model.CSIContainer{
+			Name:    c.Name,
+			Image:   c.Image,
+			Version: version,
+			Sidecar: sidecar,
+		})
+	}
+
+	return plugin, hasSidecar
+}
+
+func csiDriverName(t *podTemplate, drivers map[string]bool) string {
+	candidates := make([]string, 0)
+	for _, c := range t.spec.Containers {
+		candidates = append(candidates, c.Args...)
+		for _, env := range c.Env {
+			candidates = append(candidates, env.Value)
+		}
+	}
+	for _, v := range t.spec.Volumes {
+		if v.HostPath != nil {
+			candidates = append(candidates, v.HostPath.Path)
+		}
+	}
+
+	for _, s := range candidates {
+		for _, m := range csiPluginDir.FindAllStringSubmatch(s, -1) {
+			if drivers[m[1]] {
+				return m[1]
+			}
+		}
+
+		for _, flag := range []string{"--drivername=", "--driver-name="} {
+			if name, ok := strings.CutPrefix(s, flag); ok && drivers[name] {
+				return name
+			}
+		}
+	}
+
+	return ""
+}
+
+// imageNameAndTag returns the repository path and the tag of the image, tag empty when it is not tagged
+func imageNameAndTag(image string) (string, string) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return image, ""
+	}
+
+	if tagged, ok := named.(reference.Tagged); ok {
+		return reference.Path(named), tagged.Tag()
+	}
+
+	return reference.Path(named), ""
+}
+
+func toStorageClass(sc *storagev1.StorageClass) model.StorageClass {
+	class := model.StorageClass{
+		Name:              sc.Name,
+		Provisioner:       sc.Provisioner,
+		Default:           sc.Annotations[defaultStorageClassAnnotation] == "true",
+		ReclaimPolicy:     string(v1.PersistentVolumeReclaimDelete),
+		VolumeBindingMode: string(storagev1.VolumeBindingImmediate),
+	}
+
+	if sc.ReclaimPolicy != nil {
+		class.ReclaimPolicy = string(*sc.ReclaimPolicy)
+	}
+
+	if sc.VolumeBindingMode != nil {
+		class.VolumeBindingMode = string(*sc.VolumeBindingMode)
+	}
+
+	if sc.AllowVolumeExpansion != nil {
+		class.AllowVolumeExpansion = *sc.AllowVolumeExpansion
+	}
+
+	for key, value := range sc.Parameters {
+		// e.g. csi.storage.k8s.io/provisioner-secret-name, never include credentials references
+		if strings.Contains(strings.ToLower(key), "secret") {
+			continue
+		}
+
+		if class.Parameters == nil {
+			class.Parameters = make(map[string]string)
+		}
+		class.Parameters[key] = value
+	}
+
+	return class
+}
+
+func persistentVolumeSummary(volumes []v1.PersistentVolume) model.PersistentVolumeSummary {
+	summary := model.PersistentVolumeSummary{Total: len(volumes)}
+	capacity := resource.Quantity{}
+	for i := range volumes {
+		switch volumes[i].Status.Phase {
+		case v1.VolumeAvailable:
+			summary.Available++
+		case v1.VolumeBound:
+			summary.Bound++
+		case v1.VolumeReleased:
+			summary.Released++
+		case v1.VolumeFailed:
+			summary.Failed++
+		}
+
+		if q, ok := volumes[i].Spec.Capacity[v1.ResourceStorage]; ok {
+			capacity.Add(q)
+		}
+	}
+	summary.Capacity = capacity.String()
+
+	return summary
+}
+
+func claimSummaries(claims []v1.PersistentVolumeClaim) []model.ClaimSummary {
+	summaries := make(map[string]*model.ClaimSummary)
+	requested := make(map[string]*resource.Quantity)
+	for i := range claims {
+		c := &claims[i]
+		summary, ok := summaries[c.Namespace]
+		if !ok {
+			summary = &model.ClaimSummary{Namespace: c.Namespace, StorageClasses: make([]string, 0)}
+			summaries[c.Namespace] = summary
+			requested[c.Namespace] = &resource.Quantity{}
+		}
+
+		summary.Total++
+		switch c.Status.Phase {
+		case v1.ClaimBound:
+			summary.Bound++
+		case v1.ClaimPending:
+			summary.Pending++
+		case v1.ClaimLost:
+			summary.Lost++
+		}
+
+		if q, ok := c.Spec.Resources.Requests[v1.ResourceStorage]; ok {
+			requested[c.Namespace].Add(q)
+		}
+
+		if c.Spec.StorageClassName != nil && *c.Spec.StorageClassName != "" {
+			summary.StorageClasses = appendUnique(summary.StorageClasses, *c.Spec.StorageClassName)
+		}
+	}
+
+	res := make([]model.ClaimSummary, 0, len(summaries))
+	for ns, summary := range summaries {
+		summary.Requested = requested[ns].String()
+		res = append(res, *summary)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Namespace < res[j].Namespace
+	})
+
+	return res
+}
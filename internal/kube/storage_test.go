@@ -0,0 +1,142 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+func TestStorage(t *testing.T) {
+	attach := false
+	expansion := true
+	retain := v1.PersistentVolumeReclaimRetain
+	standard := "standard"
+
+	client := fake.NewSimpleClientset(
+		&storagev1.CSIDriver{
+			ObjectMeta: metav1.ObjectMeta{Name: "ebs.csi.aws.com"},
+			Spec: storagev1.CSIDriverSpec{
+				AttachRequired:       &attach,
+				VolumeLifecycleModes: []storagev1.VolumeLifecycleMode{storagev1.VolumeLifecyclePersistent},
+			},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "ebs-csi-node", Namespace: "kube-system"},
+			Spec: appsv1.DaemonSetSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{Name: "ebs-plugin", Image: "public.ecr.aws/ebs-csi-driver/aws-ebs-csi-driver:v1.26.0"},
+					{
+						Name:  "node-driver-registrar",
+						Image: "registry.k8s.io/sig-storage/csi-node-driver-registrar:v2.9.0",
+						Args:  []string{"--kubelet-registration-path=/var/lib/kubelet/plugins/ebs.csi.aws.com/csi.sock"},
+					},
+				},
+			}}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "ebs-csi-controller", Namespace: "kube-system"},
+			Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{Name: "ebs-plugin", Image: "public.ecr.aws/ebs-csi-driver/aws-ebs-csi-driver:v1.26.0"},
+					{Name: "csi-provisioner", Image: "registry.k8s.io/sig-storage/csi-provisioner:v3.6.0"},
+				},
+			}}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"},
+			Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: "coredns", Image: "registry.k8s.io/coredns/coredns:v1.11.1"}},
+			}}},
+		},
+		&storagev1.StorageClass{
+			ObjectMeta:           metav1.ObjectMeta{Name: "gp3", Annotations: map[string]string{defaultStorageClassAnnotation: "true"}},
+			Provisioner:          "ebs.csi.aws.com",
+			ReclaimPolicy:        &retain,
+			AllowVolumeExpansion: &expansion,
+			Parameters: map[string]string{
+				"type": "gp3",
+				"csi.storage.k8s.io/provisioner-secret-name": "ebs-credentials",
+			},
+		},
+		&v1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+			Spec:       v1.PersistentVolumeSpec{Capacity: v1.ResourceList{v1.ResourceStorage: resource.MustParse("10Gi")}},
+			Status:     v1.PersistentVolumeStatus{Phase: v1.VolumeBound},
+		},
+		&v1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-2"},
+			Spec:       v1.PersistentVolumeSpec{Capacity: v1.ResourceList{v1.ResourceStorage: resource.MustParse("5Gi")}},
+			Status:     v1.PersistentVolumeStatus{Phase: v1.VolumeReleased},
+		},
+		&v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "db"},
+			Spec: v1.PersistentVolumeClaimSpec{
+				StorageClassName: &standard,
+				Resources:        v1.VolumeResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("10Gi")}},
+			},
+			Status: v1.PersistentVolumeClaimStatus{Phase: v1.ClaimBound},
+		},
+		&v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "db"},
+			Spec: v1.PersistentVolumeClaimSpec{
+				Resources: v1.VolumeResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")}},
+			},
+			Status: v1.PersistentVolumeClaimStatus{Phase: v1.ClaimPending},
+		},
+	)
+
+	k := &k8sDB{client: client}
+	templates, err := k.PodTemplates(context.Background())
+	require.NoError(t, err)
+	res, err := k.Storage(context.Background(), templates)
+	require.NoError(t, err)
+
+	require.Len(t, res.CSIDrivers, 1)
+	driver := res.CSIDrivers[0]
+	assert.Equal(t, "ebs.csi.aws.com", driver.Name)
+	assert.Equal(t, "v1.26.0", driver.Version)
+	assert.False(t, driver.AttachRequired)
+	assert.Equal(t, []string{"Persistent"}, driver.LifecycleModes)
+	require.Len(t, driver.Plugins, 2)
+	// the controller is attributed through the node plugin registered in the same namespace
+	assert.Equal(t, "controller", driver.Plugins[0].Component)
+	assert.Equal(t, "Deployment/ebs-csi-controller", driver.Plugins[0].Workload)
+	assert.Equal(t, "node", driver.Plugins[1].Component)
+	assert.Equal(t, model.CSIContainer{
+		Name:    "node-driver-registrar",
+		Image:   "registry.k8s.io/sig-storage/csi-node-driver-registrar:v2.9.0",
+		Version: "v2.9.0",
+		Sidecar: true,
+	}, driver.Plugins[1].Containers[1])
+
+	assert.Equal(t, []model.StorageClass{{
+		Name:                 "gp3",
+		Provisioner:          "ebs.csi.aws.com",
+		Default:              true,
+		ReclaimPolicy:        "Retain",
+		VolumeBindingMode:    "Immediate",
+		AllowVolumeExpansion: true,
+		Parameters:           map[string]string{"type": "gp3"},
+	}}, res.StorageClasses)
+
+	assert.Equal(t, model.PersistentVolumeSummary{Total: 2, Bound: 1, Released: 1, Capacity: "15Gi"}, res.PersistentVolumes)
+
+	assert.Equal(t, []model.ClaimSummary{{
+		Namespace:      "db",
+		Total:          2,
+		Bound:          1,
+		Pending:        1,
+		Requested:      "11Gi",
+		StorageClasses: []string{"standard"},
+	}}, res.Claims)
+}
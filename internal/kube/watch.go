@@ -0,0 +1,418 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+// Watcher keeps the nodes, pods and all listable resources of the cluster in shared informer caches,
+// so the inventory can be rebuilt on every change without listing the cluster again
+type Watcher struct {
+	client         kubernetes.Interface
+	dynamicClient  dynamic.Interface
+	factory        informers.SharedInformerFactory
+	dynamicFactory dynamicinformer.DynamicSharedInformerFactory
+
+	nodes       cache.SharedIndexInformer
+	pods        cache.SharedIndexInformer
+	resources   []watchedResource
+	listErrors  map[string]error
+	imageErrors map[string]error
+
+	// failedGroups and failedResources are probed again by AllResources, at nextProbe
+	failedGroups    []schema.GroupVersion
+	failedResources []watchedResource
+	nextProbe       time.Time
+	probeBackoff    time.Duration
+
+	stop    <-chan struct{}
+	changes chan struct{}
+}
+
+// minProbeBackoff and maxProbeBackoff bound the interval between probes of the resources that failed to list
+const (
+	minProbeBackoff = 30 * time.Second
+	maxProbeBackoff = 10 * time.Minute
+)
+
+type watchedResource struct {
+	gvr        schema.GroupVersionResource
+	kind       string
+	namespaced bool
+	informer   cache.SharedIndexInformer
+}
+
+func NewWatcher(cfg *rest.Config) (*Watcher, error) {
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("can not create kubernetes client: %w", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("can not create kubernetes dynamic client: %w", err)
+	}
+
+	return newWatcher(clientset, dynamicClient), nil
+}
+
+func newWatcher(client kubernetes.Interface, dynamicClient dynamic.Interface) *Watcher {
+	return &Watcher{
+		client:         client,
+		dynamicClient:  dynamicClient,
+		factory:        informers.NewSharedInformerFactory(client, 0),
+		dynamicFactory: dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0),
+		changes:        make(chan struct{}, 1),
+		listErrors:     make(map[string]error),
+	}
+}
+
+// Changes is signalled whenever a watched object is added, updated or deleted. Signals are coalesced,
+// so a receiver sees at most one pending change.
+func (w *Watcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// Start discovers the resources, starts the informers and waits for their caches to sync.
+// Resources that can not be listed or watched are skipped, like in AllResources. The ones failing to list and the API
+// groups failing discovery are probed again by AllResources.
+func (w *Watcher) Start(ctx context.Context) error {
+	w.stop = ctx.Done()
+
+	apiResourceList, groupErrors, err := preferredResources(w.client.Discovery())
+	if err != nil {
+		return err
+	}
+	for gv, groupErr := range groupErrors {
+		w.listErrors[groupErrorKey(gv)] = groupErr
+		w.failedGroups = append(w.failedGroups, gv)
+	}
+
+	if err := w.watchResources(ctx, apiResourceList); err != nil {
+		return err
+	}
+	w.nextProbe = time.Now().Add(minProbeBackoff)
+
+	w.nodes = w.factory.Core().V1().Nodes().Informer()
+	w.pods = w.factory.Core().V1().Pods().Informer()
+	for _, informer := range []cache.SharedIndexInformer{w.nodes, w.pods} {
+		if err := informer.SetTransform(stripManagedFields); err != nil {
+			return err
+		}
+		if _, err := informer.AddEventHandler(w.handler()); err != nil {
+			return err
+		}
+	}
+
+	w.factory.Start(ctx.Done())
+	w.dynamicFactory.Start(ctx.Done())
+
+	for typ, synced := range w.factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return fmt.Errorf("failed to sync %v cache", typ)
+		}
+	}
+	for gvr, synced := range w.dynamicFactory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return fmt.Errorf("failed to sync %s cache", gvr)
+		}
+	}
+
+	log.Debug().Int("resources", len(w.resources)).Msg("Informer caches synced")
+
+	// the initial adds are part of the first inventory, not a change
+	select {
+	case <-w.changes:
+	default:
+	}
+
+	return nil
+}
+
+// watchResources watches the listable and watchable resources of the lists
+func (w *Watcher) watchResources(ctx context.Context, apiResourceList []*metav1.APIResourceList) error {
+	for _, apiResource := range apiResourceList {
+		gv, err := schema.ParseGroupVersion(apiResource.GroupVersion)
+		if err != nil {
+			return fmt.Errorf("failed to parse group version: %w", err)
+		}
+
+		for i := range apiResource.APIResources {
+			res := apiResource.APIResources[i]
+			if !isListable(&res) || !slices.Contains(res.Verbs, "watch") {
+				continue
+			}
+
+			if err := w.watchResource(ctx, watchedResource{gvr: gv.WithResource(res.Name), kind: res.Kind, namespaced: res.Namespaced}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// watchResource starts an informer for the resource, a resource that fails to list is recorded to be probed again
+func (w *Watcher) watchResource(ctx context.Context, r watchedResource) error {
+	key := r.gvr.String()
+	if _, err := w.dynamicClient.Resource(r.gvr).List(ctx, metav1.ListOptions{Limit: 1}); err != nil {
+		log.Debug().Err(err).Interface("gvr", r.gvr).Msg("Failed to list resources, not watching them")
+		w.listErrors[key] = err
+		w.failedResources = append(w.failedResources, r)
+		return nil
+	}
+	delete(w.listErrors, key)
+
+	r.informer = w.dynamicFactory.ForResource(r.gvr).Informer()
+	if err := r.informer.SetTransform(trimResource); err != nil {
+		return err
+	}
+	if _, err := r.informer.AddEventHandler(w.handler()); err != nil {
+		return err
+	}
+
+	w.resources = append(w.resources, r)
+	return nil
+}
+
+// probe retries the API groups and resources that failed, e.g. because of missing permissions or an unavailable
+// aggregated API. While some keep failing, the interval between probes doubles up to maxProbeBackoff.
+func (w *Watcher) probe(ctx context.Context) error {
+	if len(w.listErrors) == 0 || time.Now().Before(w.nextProbe) {
+		return nil
+	}
+
+	groups, resources := w.failedGroups, w.failedResources
+	w.failedGroups, w.failedResources = nil, nil
+	watched := len(w.resources)
+
+	for _, gv := range groups {
+		list, err := w.client.Discovery().ServerResourcesForGroupVersion(gv.String())
+		if err != nil {
+			w.listErrors[groupErrorKey(gv)] = err
+			w.failedGroups = append(w.failedGroups, gv)
+			continue
+		}
+
+		delete(w.listErrors, groupErrorKey(gv))
+		if err := w.watchResources(ctx, []*metav1.APIResourceList{list}); err != nil {
+			return err
+		}
+	}
+	for _, r := range resources {
+		if err := w.watchResource(ctx, r); err != nil {
+			return err
+		}
+	}
+
+	if len(w.resources) > watched {
+		w.dynamicFactory.Start(w.stop)
+		for gvr, synced := range w.dynamicFactory.WaitForCacheSync(ctx.Done()) {
+			if !synced {
+				return fmt.Errorf("failed to sync %s cache", gvr)
+			}
+		}
+		log.Debug().Int("resources", len(w.resources)-watched).Msg("Watching resources that failed before")
+	}
+
+	w.probeBackoff = min(max(2*w.probeBackoff, minProbeBackoff), maxProbeBackoff)
+	if len(w.listErrors) == 0 {
+		w.probeBackoff = 0
+	}
+	w.nextProbe = time.Now().Add(w.probeBackoff)
+
+	return nil
+}
+
+func (w *Watcher) handler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(interface{}) { w.notify() },
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if _, ok := newObj.(*unstructured.Unstructured); ok && reflect.DeepEqual(oldObj, newObj) {
+				return // only the resource version of a trimmed resource changed
+			}
+			w.notify()
+		},
+		DeleteFunc: func(interface{}) { w.notify() },
+	}
+}
+
+func (w *Watcher) notify() {
+	select {
+	case w.changes <- struct{}{}:
+	default:
+	}
+}
+
+// trimResource keeps only the fields of a resource the inventory reports
+func trimResource(obj interface{}) (interface{}, error) {
+	item, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return obj, nil
+	}
+
+	trimmed := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	trimmed.SetAPIVersion(item.GetAPIVersion())
+	trimmed.SetKind(item.GetKind())
+	trimmed.SetName(item.GetName())
+	trimmed.SetNamespace(item.GetNamespace())
+	if chart, ok := item.GetLabels()[helmChartLabel]; ok {
+		trimmed.SetLabels(map[string]string{helmChartLabel: chart})
+	}
+	if version, ok := getVersion(item); ok {
+		trimmed.Object["spec"] = map[string]interface{}{"version": version}
+	}
+
+	return trimmed, nil
+}
+
+func stripManagedFields(obj interface{}) (interface{}, error) {
+	if accessor, ok := obj.(metav1.Object); ok {
+		accessor.SetManagedFields(nil)
+	}
+
+	return obj, nil
+}
+
+// AllNodes returns the cached nodes sorted by name
+func (w *Watcher) AllNodes(_ context.Context, full bool) ([]model.Node, error) {
+	objs := w.nodes.GetStore().List()
+
+	nodes := make([]model.Node, 0, len(objs))
+	for _, obj := range objs {
+		nodes = append(nodes, toModelNode(obj.(*v1.Node), full))
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	return nodes, nil
+}
+
+// AllImages returns the images of the cached pods sorted by reference
+func (w *Watcher) AllImages(_ context.Context) ([]model.Image, error) {
+	platforms := make(map[string]string)
+	for _, obj := range w.nodes.GetStore().List() {
+		node := obj.(*v1.Node)
+		if platform := nodePlatform(node); platform != "" {
+			platforms[node.Name] = platform
+		}
+	}
+
+	images := make(map[string]model.Image)
+	w.imageErrors = make(map[string]error)
+	for _, obj := range w.pods.GetStore().List() {
+		pod := obj.(*v1.Pod)
+		addPodImages(images, w.imageErrors, pod, platforms[pod.Spec.NodeName])
+	}
+
+	res := make([]model.Image, 0, len(images))
+	for _, img := range images {
+		res = append(res, img)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].FullName < res[j].FullName
+	})
+
+	return res, nil
+}
+
+// AllResources returns the cached resources, only the kinds with at least one resource are included. The resources
+// that failed to list before are probed again first.
+func (w *Watcher) AllResources(ctx context.Context, full bool) (map[string]model.ResourceList, error) {
+	if err := w.probe(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to watch resources that failed before")
+	}
+
+	resourceMap := make(map[string]model.ResourceList)
+	for _, r := range w.resources {
+		objs := r.informer.GetStore().List()
+		if len(objs) == 0 {
+			continue
+		}
+
+		list := model.ResourceList{
+			Kind:           r.kind,
+			APIVersion:     r.gvr.GroupVersion().String(),
+			Namespaced:     r.namespaced,
+			ResourcesCount: len(objs),
+			Resources:      make([]model.Resource, 0),
+		}
+
+		if full {
+			for _, obj := range objs {
+				list.Resources = append(list.Resources, toModelResource(obj.(*unstructured.Unstructured)))
+			}
+			sort.Slice(list.Resources, func(i, j int) bool {
+				if list.Resources[i].Namespace != list.Resources[j].Namespace {
+					return list.Resources[i].Namespace < list.Resources[j].Namespace
+				}
+
+				return list.Resources[i].Name < list.Resources[j].Name
+			})
+		}
+
+		resourceMap[r.gvr.String()] = list
+	}
+
+	return resourceMap, nil
+}
+
+// ResourceListErrors returns the resources and API groups that could not be listed at the last probe, these are not
+// watched
+func (w *Watcher) ResourceListErrors() map[string]error {
+	return w.listErrors
+}
+
+// ImageErrors returns the containers the last AllImages call skipped
+func (w *Watcher) ImageErrors() map[string]error {
+	return w.imageErrors
+}
+
+// Client returns a client serving the nodes, images and resources from the caches and everything else from base
+func (w *Watcher) Client(base K8sClient) K8sClient {
+	return &cachedClient{K8sClient: base, watcher: w}
+}
+
+type cachedClient struct {
+	K8sClient
+	watcher *Watcher
+}
+
+func (c *cachedClient) AllNodes(ctx context.Context, full bool) ([]model.Node, error) {
+	return c.watcher.AllNodes(ctx, full)
+}
+
+func (c *cachedClient) AllImages(ctx context.Context) ([]model.Image, error) {
+	return c.watcher.AllImages(ctx)
+}
+
+func (c *cachedClient) AllResources(ctx context.Context, full bool) (map[string]model.ResourceList, error) {
+	return c.watcher.AllResources(ctx, full)
+}
+
+func (c *cachedClient) ResourceListErrors() map[string]error {
+	return c.watcher.ResourceListErrors()
+}
+
+func (c *cachedClient) ImageErrors() map[string]error {
+	return c.watcher.ImageErrors()
+}
@@ -0,0 +1,134 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestWatcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pod := func(name, image string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app", Image: image}}},
+		}
+	}
+
+	client := fake.NewSimpleClientset(
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		pod("web", "nginx:1.25"),
+	)
+	client.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "widgets", Kind: "Widget", Namespaced: true, Verbs: []string{"list", "watch"}},
+			{Name: "widgets/status", Kind: "Widget", Namespaced: true, Verbs: []string{"get"}},
+			{Name: "gadgets", Kind: "Gadget", Namespaced: true, Verbs: []string{"get"}},
+		},
+	}}
+
+	widgets := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{widgets: "WidgetList"},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "Widget",
+			"metadata": map[string]interface{}{
+				"name":      "w1",
+				"namespace": "default",
+				"labels":    map[string]interface{}{helmChartLabel: "widget-1.0.0", "team": "a"},
+			},
+			"spec": map[string]interface{}{"version": "1.2.3", "replicas": int64(2)},
+		}},
+	)
+
+	w := newWatcher(client, dynamicClient)
+	require.NoError(t, w.Start(ctx))
+
+	nodes, err := w.AllNodes(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+	assert.Equal(t, "node-1", nodes[0].Name)
+
+	resources, err := w.AllResources(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, resources, 1, "only the listable and watchable resources are watched")
+	list := resources[widgets.String()]
+	assert.Equal(t, "Widget", list.Kind)
+	assert.Equal(t, 1, list.ResourcesCount)
+	assert.Equal(t, map[string]string{"version": "1.2.3", "chart": "widget-1.0.0"}, list.Resources[0].AdditionalProperties)
+
+	_, err = client.CoreV1().Pods("default").Create(ctx, pod("api", "ghcr.io/example/api:2.0"), metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	select {
+	case <-w.Changes():
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change signalled for the new pod")
+	}
+
+	images, err := w.Client(nil).AllImages(ctx)
+	require.NoError(t, err)
+	require.Len(t, images, 2)
+	assert.Equal(t, "ghcr.io/example/api:2.0", images[0].FullName)
+	assert.Equal(t, "nginx:1.25", images[1].FullName)
+}
+
+func TestWatcherProbe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := fake.NewSimpleClientset()
+	client.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{{Name: "widgets", Kind: "Widget", Namespaced: true, Verbs: []string{"list", "watch"}}},
+	}}
+
+	widgets := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{widgets: "WidgetList"},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "Widget",
+			"metadata":   map[string]interface{}{"name": "w1", "namespace": "default"},
+		}},
+	)
+	forbidden := true
+	dynamicClient.PrependReactor("list", "widgets", func(k8stesting.Action) (bool, runtime.Object, error) {
+		if forbidden {
+			return true, nil, apierrors.NewForbidden(widgets.GroupResource(), "", errors.New("rbac not yet granted"))
+		}
+		return false, nil, nil
+	})
+
+	w := newWatcher(client, dynamicClient)
+	require.NoError(t, w.Start(ctx))
+	assert.Contains(t, w.ResourceListErrors(), widgets.String())
+
+	forbidden = false
+	resources, err := w.AllResources(ctx, false)
+	require.NoError(t, err)
+	assert.Empty(t, resources, "the resources are not probed again before the backoff")
+
+	w.nextProbe = time.Time{}
+	resources, err = w.AllResources(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, resources[widgets.String()].ResourcesCount)
+	assert.Empty(t, w.ResourceListErrors())
+}
@@ -0,0 +1,244 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+const (
+	deploymentKind  = "Deployment"
+	statefulSetKind = "StatefulSet"
+	daemonSetKind   = "DaemonSet"
+	jobKind         = "Job"
+	cronJobKind     = "CronJob"
+	podKind         = "Pod"
+
+	defaultServiceAccount = "default"
+	// maxOwnerDepth guards the owner chain resolution against reference cycles
+	maxOwnerDepth = 10
+)
+
+// podTemplate is a workload together with the spec of the pods it runs
+type podTemplate struct {
+	kind        string
+	meta        metav1.ObjectMeta
+	labels      map[string]string
+	annotations map[string]string
+	spec        v1.PodSpec
+
+	// replicas, selector and schedule are only set for the kinds that have them
+	replicas *int32
+	selector *metav1.LabelSelector
+	schedule string
+}
+
+// PodTemplates are the workloads of the cluster with the spec of the pods they run, listed once per KBOM and shared by
+// the sections resolving pods to workloads
+type PodTemplates struct {
+	items []podTemplate
+	pods  []v1.Pod
+}
+
+// PodTemplates returns the pod templates of all Deployments, StatefulSets, DaemonSets, Jobs and CronJobs, plus all pods
+// whose owner chain does not lead to one of them (bare and static pods, and pods of other controllers like Argo
+// Rollouts or bare ReplicaSets)
+func (k *k8sDB) PodTemplates(ctx context.Context) (*PodTemplates, error) {
+	templates := make([]podTemplate, 0)
+	// managed are the controllers whose pods are covered by a collected template
+	managed := make(map[string]bool)
+
+	deployments, err := k.client.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		d := deployments.Items[i]
+		t := newPodTemplate(deploymentKind, &d.ObjectMeta, &d.Spec.Template)
+		t.replicas, t.selector = d.Spec.Replicas, d.Spec.Selector
+		templates = append(templates, t)
+	}
+
+	statefulSets, err := k.client.AppsV1().StatefulSets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for i := range statefulSets.Items {
+		s := statefulSets.Items[i]
+		t := newPodTemplate(statefulSetKind, &s.ObjectMeta, &s.Spec.Template)
+		t.replicas, t.selector = s.Spec.Replicas, s.Spec.Selector
+		templates = append(templates, t)
+	}
+
+	daemonSets, err := k.client.AppsV1().DaemonSets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for i := range daemonSets.Items {
+		d := daemonSets.Items[i]
+		t := newPodTemplate(daemonSetKind, &d.ObjectMeta, &d.Spec.Template)
+		t.replicas, t.selector = &d.Status.DesiredNumberScheduled, d.Spec.Selector
+		templates = append(templates, t)
+	}
+
+	cronJobs, err := k.client.BatchV1().CronJobs("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+	for i := range cronJobs.Items {
+		c := cronJobs.Items[i]
+		t := newPodTemplate(cronJobKind, &c.ObjectMeta, &c.Spec.JobTemplate.Spec.Template)
+		t.schedule = c.Spec.Schedule
+		templates = append(templates, t)
+		managed[workloadKey(cronJobKind, c.Namespace, c.Name)] = true
+	}
+
+	jobs, err := k.client.BatchV1().Jobs("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	for i := range jobs.Items {
+		j := jobs.Items[i]
+		if owner := metav1.GetControllerOf(&j); owner != nil && managed[workloadKey(owner.Kind, j.Namespace, owner.Name)] {
+			managed[workloadKey(jobKind, j.Namespace, j.Name)] = true
+			continue // already covered by the CronJob template
+		}
+		t := newPodTemplate(jobKind, &j.ObjectMeta, &j.Spec.Template)
+		t.replicas, t.selector = j.Spec.Parallelism, j.Spec.Selector
+		templates = append(templates, t)
+	}
+
+	for i := range templates {
+		managed[workloadKey(templates[i].kind, templates[i].meta.Namespace, templates[i].meta.Name)] = true
+	}
+
+	unmanaged, pods, err := k.unmanagedPods(ctx, managed)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PodTemplates{items: append(templates, unmanaged...), pods: pods}, nil
+}
+
+// unmanagedPods returns the templates of the pods not covered by the managed controllers, pods of ReplicaSets being
+// covered by the Deployment owning the ReplicaSet, together with all pods
+func (k *k8sDB) unmanagedPods(ctx context.Context, managed map[string]bool) ([]podTemplate, []v1.Pod, error) {
+	replicaSets, err := k.client.AppsV1().ReplicaSets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list replicasets: %w", err)
+	}
+	for i := range replicaSets.Items {
+		r := replicaSets.Items[i]
+		if owner := metav1.GetControllerOf(&r); owner != nil && managed[workloadKey(owner.Kind, r.Namespace, owner.Name)] {
+			managed[workloadKey(replicaSetKind, r.Namespace, r.Name)] = true
+		}
+	}
+
+	pods, err := k.client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	templates := make([]podTemplate, 0)
+	for i := range pods.Items {
+		p := pods.Items[i]
+		if owner := metav1.GetControllerOf(&p); owner != nil && managed[workloadKey(owner.Kind, p.Namespace, owner.Name)] {
+			continue // managed by a collected workload
+		}
+		templates = append(templates, newPodTemplate(podKind, &p.ObjectMeta, &v1.PodTemplateSpec{ObjectMeta: p.ObjectMeta, Spec: p.Spec}))
+	}
+
+	return templates, pods.Items, nil
+}
+
+func newPodTemplate(kind string, meta *metav1.ObjectMeta, template *v1.PodTemplateSpec) podTemplate {
+	return podTemplate{
+		kind:        kind,
+		meta:        *meta,
+		labels:      template.Labels,
+		annotations: template.Annotations,
+		spec:        template.Spec,
+	}
+}
+
+// Workloads returns all workloads with their containers, service account and controller owner chain
+func (k *k8sDB) Workloads(ctx context.Context, podTemplates *PodTemplates) (*model.Workloads, error) {
+	templates := podTemplates.items
+
+	index := make(map[string]*podTemplate, len(templates))
+	for i := range templates {
+		t := &templates[i]
+		index[workloadKey(t.kind, t.meta.Namespace, t.meta.Name)] = t
+	}
+
+	res := &model.Workloads{
+		Counts: make(map[string]int),
+		Items:  make([]model.Workload, 0, len(templates)),
+	}
+	for i := range templates {
+		t := &templates[i]
+		res.Counts[t.kind]++
+		res.Items = append(res.Items, toWorkload(t, index))
+	}
+
+	return res, nil
+}
+
+func toWorkload(t *podTemplate, index map[string]*podTemplate) model.Workload {
+	w := model.Workload{
+		Kind:           t.kind,
+		Name:           t.meta.Name,
+		Namespace:      t.meta.Namespace,
+		Replicas:       t.replicas,
+		Selector:       formatSelector(t.selector),
+		Schedule:       t.schedule,
+		ServiceAccount: t.spec.ServiceAccountName,
+		Containers:     make([]model.WorkloadContainer, 0, len(t.spec.InitContainers)+len(t.spec.Containers)),
+		Owners:         ownerChain(t, index),
+	}
+
+	if w.ServiceAccount == "" {
+		w.ServiceAccount = defaultServiceAccount
+	}
+
+	for _, c := range t.spec.InitContainers {
+		w.Containers = append(w.Containers, model.WorkloadContainer{Name: c.Name, Image: c.Image, Init: true})
+	}
+
+	for _, c := range t.spec.Containers {
+		w.Containers = append(w.Containers, model.WorkloadContainer{Name: c.Name, Image: c.Image})
+	}
+
+	return w
+}
+
+// ownerChain follows the controller owner references of the workload. Owners which are not workloads
+// themselves (e.g. custom resources of an operator) end the chain.
+func ownerChain(t *podTemplate, index map[string]*podTemplate) []model.Owner {
+	var owners []model.Owner
+	meta := &t.meta
+	for len(owners) < maxOwnerDepth {
+		ref := metav1.GetControllerOfNoCopy(meta)
+		if ref == nil {
+			break
+		}
+
+		owners = append(owners, model.Owner{APIVersion: ref.APIVersion, Kind: ref.Kind, Name: ref.Name})
+
+		owner, ok := index[workloadKey(ref.Kind, t.meta.Namespace, ref.Name)]
+		if !ok {
+			break
+		}
+		meta = &owner.meta
+	}
+
+	return owners
+}
+
+func workloadKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
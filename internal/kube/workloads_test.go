@@ -0,0 +1,132 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+func TestWorkloads(t *testing.T) {
+	isController := true
+	replicas := int32(3)
+
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "kafka-entity-operator",
+				Namespace: "kafka",
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "kafka.strimzi.io/v1beta2", Kind: "Kafka", Name: "cluster", Controller: &isController},
+				},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "entity-operator"}},
+				Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+					ServiceAccountName: "entity-operator",
+					InitContainers:     []v1.Container{{Name: "init", Image: "busybox:1.36"}},
+					Containers:         []v1.Container{{Name: "operator", Image: "quay.io/strimzi/operator:0.39.0"}},
+				}},
+			},
+		},
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "db"},
+			Spec: batchv1.CronJobSpec{
+				Schedule: "0 2 * * *",
+				JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: "backup", Image: "postgres:16"}},
+				}}}},
+			},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "kube-apiserver-control-plane",
+				Namespace: "kube-system",
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "v1", Kind: "Node", Name: "control-plane", Controller: &isController},
+				},
+			},
+			Spec: v1.PodSpec{Containers: []v1.Container{{Name: "kube-apiserver", Image: "registry.k8s.io/kube-apiserver:v1.29.0"}}},
+		},
+	)
+
+	k := &k8sDB{client: client}
+	templates, err := k.PodTemplates(context.Background())
+	require.NoError(t, err)
+	res, err := k.Workloads(context.Background(), templates)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{"Deployment": 1, "CronJob": 1, "Pod": 1}, res.Counts)
+	require.Len(t, res.Items, 3)
+
+	assert.Equal(t, model.Workload{
+		Kind:           "Deployment",
+		Name:           "kafka-entity-operator",
+		Namespace:      "kafka",
+		Replicas:       &replicas,
+		Selector:       "app=entity-operator",
+		ServiceAccount: "entity-operator",
+		Containers: []model.WorkloadContainer{
+			{Name: "init", Image: "busybox:1.36", Init: true},
+			{Name: "operator", Image: "quay.io/strimzi/operator:0.39.0"},
+		},
+		Owners: []model.Owner{{APIVersion: "kafka.strimzi.io/v1beta2", Kind: "Kafka", Name: "cluster"}},
+	}, res.Items[0])
+
+	assert.Equal(t, "0 2 * * *", res.Items[1].Schedule)
+	assert.Equal(t, "default", res.Items[1].ServiceAccount)
+	assert.Nil(t, res.Items[1].Owners)
+
+	assert.Equal(t, []model.Owner{{APIVersion: "v1", Kind: "Node", Name: "control-plane"}}, res.Items[2].Owners)
+}
+
+func TestPodTemplatesOwnerChain(t *testing.T) {
+	controller := func(kind, name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: ptr.To(true)}}
+	}
+	pod := func(name string, owners []metav1.OwnerReference) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", OwnerReferences: owners}}
+	}
+	replicaSet := func(name string, owners []metav1.OwnerReference) *appsv1.ReplicaSet {
+		return &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", OwnerReferences: owners}}
+	}
+
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+		replicaSet("web-5d8f7", controller("Deployment", "web")),
+		replicaSet("bare", nil),
+		replicaSet("api-6c9b4", controller("Rollout", "api")),
+		&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "default"}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Name: "backup-28400", Namespace: "default", OwnerReferences: controller("CronJob", "backup"),
+		}},
+		pod("web-5d8f7-abcde", controller("ReplicaSet", "web-5d8f7")),
+		pod("backup-28400-fghij", controller("Job", "backup-28400")),
+		pod("bare-klmno", controller("ReplicaSet", "bare")),
+		pod("api-6c9b4-pqrst", controller("ReplicaSet", "api-6c9b4")),
+		pod("kafka-0", controller("StrimziPodSet", "kafka")),
+	)
+
+	k := &k8sDB{client: client}
+	templates, err := k.PodTemplates(context.Background())
+	require.NoError(t, err)
+
+	var pods []string
+	for _, template := range templates.items {
+		if template.kind == podKind {
+			pods = append(pods, template.meta.Name)
+		}
+	}
+	assert.ElementsMatch(t, []string{"bare-klmno", "api-6c9b4-pqrst", "kafka-0"}, pods,
+		"only the pods of collected workloads are left out")
+}
@@ -0,0 +1,141 @@
+// Package metrics exposes the inventory and collection health of the last KBOM as Prometheus metrics
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+const namespace = "kbom"
+
+// Metrics holds the collectors in a registry of their own, so only kbom metrics are exposed
+type Metrics struct {
+	registry *prometheus.Registry
+
+	phaseDuration *prometheus.GaugeVec
+	phaseErrors   *prometheus.CounterVec
+	lastSuccess   prometheus.Gauge
+	listErrors    *prometheus.GaugeVec
+	clusterInfo   *prometheus.GaugeVec
+	images        *prometheus.GaugeVec
+	nodes         *prometheus.GaugeVec
+	resources     *prometheus.GaugeVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		phaseDuration: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "collection_duration_seconds",
+			Help:      "Duration of the last run of each collection phase.",
+		}, []string{"phase"}),
+		phaseErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "collection_errors_total",
+			Help:      "Number of failed runs of each collection phase.",
+		}, []string{"phase"}),
+		lastSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "last_success_timestamp_seconds",
+			Help:      "Time the last KBOM was generated successfully.",
+		}),
+		listErrors: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "resource_list_errors",
+			Help:      "Resources that could not be listed in the last collection, 1 for each failed resource.",
+		}, []string{"group", "version", "resource"}),
+		clusterInfo: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "cluster_info",
+			Help:      "Cluster the last KBOM was generated for, always 1.",
+		}, []string{"cluster", "k8s_version"}),
+		images: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "images",
+			Help:      "Number of distinct images by registry.",
+		}, []string{"registry"}),
+		nodes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "nodes",
+			Help:      "Number of nodes by kubelet version.",
+		}, []string{"kubelet_version"}),
+		resources: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "resources",
+			Help:      "Number of resources by group, version and resource.",
+		}, []string{"group", "version", "resource"}),
+	}
+
+	m.registry.MustRegister(m.phaseDuration, m.phaseErrors, m.lastSuccess, m.listErrors, m.clusterInfo, m.images, m.nodes,
+		m.resources)
+
+	return m
+}
+
+// ObservePhase records the duration of a collection phase started at start, counting it as failed when err is set
+func (m *Metrics) ObservePhase(phase string, start time.Time, err error) {
+	m.phaseDuration.WithLabelValues(phase).Set(time.Since(start).Seconds())
+	if err != nil {
+		m.phaseErrors.WithLabelValues(phase).Inc()
+	}
+}
+
+// ObserveKBOM replaces the inventory metrics with the ones of the KBOM. listErrors are the resources that could
+// not be listed, keyed like the resources of the KBOM.
+func (m *Metrics) ObserveKBOM(kbom *model.KBOM, listErrors map[string]error) {
+	m.lastSuccess.Set(float64(kbom.GeneratedAt.Unix()))
+
+	m.clusterInfo.Reset()
+	m.clusterInfo.WithLabelValues(kbom.Cluster.Name, kbom.Cluster.K8sVersion).Set(1)
+
+	m.images.Reset()
+	for _, r := range kbom.Cluster.Components.Registries {
+		m.images.WithLabelValues(r.Registry).Set(float64(r.Images))
+	}
+
+	m.nodes.Reset()
+	for i := range kbom.Cluster.Nodes {
+		m.nodes.WithLabelValues(kbom.Cluster.Nodes[i].KubeletVersion).Inc()
+	}
+
+	m.resources.Reset()
+	for key, r := range kbom.Cluster.Components.Resources {
+		gvr := parseResourceKey(key)
+		m.resources.WithLabelValues(gvr.Group, gvr.Version, gvr.Resource).Set(float64(r.ResourcesCount))
+	}
+
+	m.listErrors.Reset()
+	for key := range listErrors {
+		gvr := parseResourceKey(key)
+		m.listErrors.WithLabelValues(gvr.Group, gvr.Version, gvr.Resource).Set(1)
+	}
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// WriteTextfile writes the metrics for the node exporter textfile collector
+func (m *Metrics) WriteTextfile(path string) error {
+	return prometheus.WriteToTextfile(path, m.registry)
+}
+
+// parseResourceKey parses the resource keys of the KBOM, e.g. "apps/v1, Resource=deployments"
+func parseResourceKey(key string) schema.GroupVersionResource {
+	groupVersion, resource, _ := strings.Cut(key, ", Resource=")
+	gv, err := schema.ParseGroupVersion(groupVersion)
+	if err != nil {
+		return schema.GroupVersionResource{Resource: resource}
+	}
+
+	return gv.WithResource(resource)
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+func TestObserveKBOM(t *testing.T) {
+	m := New()
+
+	kbom := &model.KBOM{
+		GeneratedAt: time.Unix(1700000000, 0),
+		Cluster: model.Cluster{
+			Name:       "prod",
+			K8sVersion: "1.29.1",
+			Nodes: []model.Node{
+				{Name: "a", KubeletVersion: "v1.29.1"},
+				{Name: "b", KubeletVersion: "v1.29.1"},
+				{Name: "c", KubeletVersion: "v1.28.5"},
+			},
+			Components: model.Components{
+				Registries: []model.RegistrySummary{{Registry: "docker.io", Images: 3}},
+				Resources: map[string]model.ResourceList{
+					"apps/v1, Resource=deployments": {ResourcesCount: 4},
+					"v1, Resource=pods":             {ResourcesCount: 9},
+				},
+			},
+		},
+	}
+
+	m.ObserveKBOM(kbom, map[string]error{"v1, Resource=secrets": errors.New("forbidden")})
+
+	assert.Equal(t, float64(1700000000), testutil.ToFloat64(m.lastSuccess))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.nodes.WithLabelValues("v1.29.1")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.nodes.WithLabelValues("v1.28.5")))
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.images.WithLabelValues("docker.io")))
+	assert.Equal(t, float64(4), testutil.ToFloat64(m.resources.WithLabelValues("apps", "v1", "deployments")))
+	assert.Equal(t, float64(9), testutil.ToFloat64(m.resources.WithLabelValues("", "v1", "pods")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.listErrors.WithLabelValues("", "v1", "secrets")))
+
+	// the next collection replaces the inventory of the previous one
+	kbom.Cluster.Nodes = kbom.Cluster.Nodes[:2]
+	m.ObserveKBOM(kbom, nil)
+	assert.Equal(t, 1, testutil.CollectAndCount(m.nodes))
+	assert.Equal(t, 0, testutil.CollectAndCount(m.listErrors))
+}
+
+func TestObservePhase(t *testing.T) {
+	m := New()
+
+	m.ObservePhase("AllImages", time.Now().Add(-time.Second), nil)
+	m.ObservePhase("AllResources", time.Now(), errors.New("timeout"))
+	m.ObservePhase("AllResources", time.Now(), errors.New("timeout"))
+
+	assert.GreaterOrEqual(t, testutil.ToFloat64(m.phaseDuration.WithLabelValues("AllImages")), float64(1))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.phaseErrors.WithLabelValues("AllImages")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.phaseErrors.WithLabelValues("AllResources")))
+}
@@ -0,0 +1,68 @@
+package model
+
+type Admission struct {
+	ValidatingWebhooks []WebhookConfiguration `json:"validating_webhooks"`
+	MutatingWebhooks   []WebhookConfiguration `json:"mutating_webhooks"`
+	Policies           []AdmissionPolicy      `json:"policies"`
+	PolicyEngines      []DetectedComponent    `json:"policy_engines"`
+}
+
+type WebhookConfiguration struct {
+	Name     string    `json:"name"`
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+type Webhook struct {
+	Name              string            `json:"name"`
+	Service           *ServiceReference `json:"service,omitempty" yaml:",omitempty"`
+	URL               string            `json:"url,omitempty" yaml:",omitempty"`
+	FailurePolicy     string            `json:"failure_policy"`
+	TimeoutSeconds    int32             `json:"timeout_seconds"`
+	SideEffects       string            `json:"side_effects,omitempty" yaml:",omitempty"`
+	NamespaceSelector string            `json:"namespace_selector,omitempty" yaml:",omitempty"`
+	ObjectSelector    string            `json:"object_selector,omitempty" yaml:",omitempty"`
+	Rules             []AdmissionRule   `json:"rules"`
+}
+
+type ServiceReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Path      string `json:"path,omitempty" yaml:",omitempty"`
+	Port      int32  `json:"port,omitempty" yaml:",omitempty"`
+}
+
+type AdmissionRule struct {
+	Operations  []string `json:"operations,omitempty" yaml:",omitempty"`
+	APIGroups   []string `json:"api_groups"`
+	APIVersions []string `json:"api_versions"`
+	Resources   []string `json:"resources"`
+	Scope       string   `json:"scope,omitempty" yaml:",omitempty"`
+}
+
+// AdmissionPolicy is a ValidatingAdmissionPolicy together with its bindings
+type AdmissionPolicy struct {
+	Name string `json:"name"`
+	// APIVersion is the admissionregistration.k8s.io version the policy was read from
+	APIVersion        string                   `json:"api_version"`
+	FailurePolicy     string                   `json:"failure_policy"`
+	ParamKind         string                   `json:"param_kind,omitempty" yaml:",omitempty"`
+	NamespaceSelector string                   `json:"namespace_selector,omitempty" yaml:",omitempty"`
+	Rules             []AdmissionRule          `json:"rules"`
+	Validations       int                      `json:"validations"`
+	Bindings          []AdmissionPolicyBinding `json:"bindings"`
+}
+
+type AdmissionPolicyBinding struct {
+	Name              string   `json:"name"`
+	ValidationActions []string `json:"validation_actions,omitempty" yaml:",omitempty"`
+	NamespaceSelector string   `json:"namespace_selector,omitempty" yaml:",omitempty"`
+}
+
+// DetectedComponent is a well-known cluster add-on recognized by the images of its workloads
+type DetectedComponent struct {
+	Name      string `json:"name"`
+	Version   string `json:"version,omitempty" yaml:",omitempty"`
+	Namespace string `json:"namespace"`
+	Workload  string `json:"workload"`
+	Image     string `json:"image"`
+}
@@ -0,0 +1,33 @@
+package model
+
+// Collection phases of the KBOM, the optional sections use their --include name
+const (
+	PhaseMetadata     = "Metadata"
+	PhaseClusterName  = "ClusterName"
+	PhaseLocation     = "Location"
+	PhaseAllNodes     = "AllNodes"
+	PhaseAllImages    = "AllImages"
+	PhaseAllResources = "AllResources"
+)
+
+// Error classes of collection errors
+const (
+	ErrorClassForbidden    = "forbidden"
+	ErrorClassUnauthorized = "unauthorized"
+	ErrorClassTimeout      = "timeout"
+	ErrorClassNotFound     = "not_found"
+	ErrorClassInvalid      = "invalid"
+	ErrorClassUnknown      = "unknown"
+)
+
+// CollectionError is a part of the cluster that could not be collected, so the sections it feeds are incomplete
+// rather than empty. Resource is the resource that failed to list, the namespace/pod/container of a skipped image or
+// the namespace whose pods could not be listed for their images.
+// Critical errors are failures of a whole core phase.
+type CollectionError struct {
+	Phase    string `json:"phase"`
+	Resource string `json:"resource,omitempty" yaml:",omitempty"`
+	Class    string `json:"class"`
+	Critical bool   `json:"critical,omitempty" yaml:",omitempty"`
+	Message  string `json:"message"`
+}
@@ -0,0 +1,34 @@
+package model
+
+const (
+	ClusterTarget  = "cluster"
+	NodeTarget     = "node"
+	ResourceTarget = "resource"
+	ImageTarget    = "image"
+)
+
+type Finding struct {
+	ID       string        `json:"id"`
+	Aliases  []string      `json:"aliases,omitempty" yaml:",omitempty"`
+	Summary  string        `json:"summary,omitempty" yaml:",omitempty"`
+	Severity string        `json:"severity,omitempty" yaml:",omitempty"`
+	CVSS     string        `json:"cvss,omitempty" yaml:",omitempty"`
+	Source   string        `json:"source"`
+	Package  string        `json:"package"`
+	Version  string        `json:"version"`
+	Fixed    []string      `json:"fixed,omitempty" yaml:",omitempty"`
+	Target   FindingTarget `json:"target"`
+
+	Status          string `json:"status,omitempty" yaml:",omitempty"`
+	Justification   string `json:"justification,omitempty" yaml:",omitempty"`
+	ImpactStatement string `json:"impact_statement,omitempty" yaml:",omitempty"`
+	ActionStatement string `json:"action_statement,omitempty" yaml:",omitempty"`
+}
+
+type FindingTarget struct {
+	Type      string `json:"type"`
+	Kind      string `json:"kind,omitempty" yaml:",omitempty"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty" yaml:",omitempty"`
+	Ref       string `json:"ref,omitempty" yaml:",omitempty"`
+}
@@ -0,0 +1,73 @@
+package model
+
+import "time"
+
+// FleetIndex lists the KBOMs generated for several kubeconfig contexts in one run
+type FleetIndex struct {
+	GeneratedAt time.Time      `json:"generated_at"`
+	Clusters    []FleetCluster `json:"clusters"`
+}
+
+// FleetCluster is the result for one context, the KBOM file and the error when the generation failed.
+// A KBOM failing only the --fail-on policy is still written, so both can be set.
+type FleetCluster struct {
+	Context    string `json:"context"`
+	ID         string `json:"id,omitempty" yaml:",omitempty"`
+	Cluster    string `json:"cluster,omitempty" yaml:",omitempty"`
+	K8sVersion string `json:"k8s_version,omitempty" yaml:",omitempty"`
+	File       string `json:"file,omitempty" yaml:",omitempty"`
+	Error      string `json:"error,omitempty" yaml:",omitempty"`
+}
+
+// Fleet merges the KBOMs of many clusters into one fleet wide inventory.
+// Every entry lists the names of the clusters it was found in, as in Clusters.
+type Fleet struct {
+	ID          string    `json:"id"`
+	GeneratedAt time.Time `json:"generated_at"`
+	GeneratedBy Tool      `json:"generated_by"`
+
+	Clusters    []FleetMember   `json:"clusters"`
+	K8sVersions []FleetVersion  `json:"k8s_versions"`
+	NodeOS      []FleetNodeOS   `json:"node_os"`
+	Images      []FleetImage    `json:"images"`
+	CRDs        []FleetPresence `json:"crds"`
+	HelmCharts  []FleetPresence `json:"helm_charts"`
+}
+
+// FleetMember is a cluster of the fleet. Name is the cluster name, suffixed with the KBOM ID when several clusters share it.
+type FleetMember struct {
+	Name        string    `json:"name"`
+	KBOMID      string    `json:"kbom_id"`
+	GeneratedAt time.Time `json:"generated_at"`
+	K8sVersion  string    `json:"k8s_version"`
+	Location    *Location `json:"location,omitempty" yaml:",omitempty"`
+	NodesCount  int       `json:"nodes_count"`
+	ImagesCount int       `json:"images_count"`
+}
+
+type FleetVersion struct {
+	Version  string   `json:"version"`
+	Clusters []string `json:"clusters"`
+}
+
+// FleetNodeOS is an OS distribution of the nodes, the raw OS image when it could not be parsed
+type FleetNodeOS struct {
+	Name       string   `json:"name"`
+	Version    string   `json:"version,omitempty" yaml:",omitempty"`
+	NodesCount int      `json:"nodes_count"`
+	Clusters   []string `json:"clusters"`
+}
+
+// FleetImage is an image digest, or an image tag when no digest is known, with the tags it is referenced by
+type FleetImage struct {
+	Name     string   `json:"name"`
+	Digest   string   `json:"digest,omitempty" yaml:",omitempty"`
+	Tags     []string `json:"tags,omitempty" yaml:",omitempty"`
+	Clusters []string `json:"clusters"`
+}
+
+// FleetPresence is a CRD or Helm chart (the helm.sh/chart label value) and the clusters it is installed in
+type FleetPresence struct {
+	Name     string   `json:"name"`
+	Clusters []string `json:"clusters"`
+}
@@ -9,6 +9,7 @@ import (
 
 const (
 	ociPrefix         = "oci"
+	genericPrefix     = "generic"
 	k8sPrefix         = "k8s"
 	pkgPrefix         = "pkg"
 	kubernetesPkgName = "k8s.io/kubernetes"
@@ -21,7 +22,9 @@ type KBOM struct {
 	GeneratedAt time.Time `json:"generated_at"`
 	GeneratedBy Tool      `json:"generated_by"`
 
-	Cluster Cluster `json:"cluster"`
+	Cluster          Cluster           `json:"cluster"`
+	Findings         []Finding         `json:"findings,omitempty" yaml:",omitempty"`
+	CollectionErrors []CollectionError `json:"collection_errors,omitempty" yaml:",omitempty"`
 }
 
 type Tool struct {
@@ -41,6 +44,7 @@ type Cluster struct {
 	Location     *Location  `json:"location"`
 	NodesCount   int        `json:"nodes_count"`
 	Nodes        []Node     `json:"nodes"`
+	NodePools    []NodePool `json:"node_pools,omitempty" yaml:",omitempty"`
 	Components   Components `json:"components"`
 }
 
@@ -53,8 +57,17 @@ func (c *Cluster) BOMName() string {
 }
 
 type Components struct {
-	Images    []Image                 `json:"images,omitempty"`
-	Resources map[string]ResourceList `json:"resources"`
+	Images      []Image                 `json:"images,omitempty"`
+	Registries  []RegistrySummary       `json:"registries,omitempty" yaml:",omitempty"`
+	Resources   map[string]ResourceList `json:"resources"`
+	RBAC        *RBAC                   `json:"rbac,omitempty" yaml:",omitempty"`
+	PodSecurity *PodSecurity            `json:"pod_security,omitempty" yaml:",omitempty"`
+	Admission   *Admission              `json:"admission,omitempty" yaml:",omitempty"`
+	Network     *Network                `json:"network,omitempty" yaml:",omitempty"`
+	Storage     *Storage                `json:"storage,omitempty" yaml:",omitempty"`
+	ServiceMesh *ServiceMesh            `json:"service_mesh,omitempty" yaml:",omitempty"`
+	Workloads   *Workloads              `json:"workloads,omitempty" yaml:",omitempty"`
+	PullSecrets *PullSecrets            `json:"pull_secrets,omitempty" yaml:",omitempty"`
 }
 
 type Resource struct {
@@ -96,14 +109,63 @@ type Node struct {
 	KubeletVersion          string            `json:"kubelet_version"`
 	OperatingSystem         string            `json:"operating_system"`
 	OsImage                 string            `json:"os_image"`
+	Runtime                 *Software         `json:"runtime,omitempty" yaml:",omitempty"`
+	OS                      *Software         `json:"os,omitempty" yaml:",omitempty"`
+	Kernel                  *Software         `json:"kernel,omitempty" yaml:",omitempty"`
+}
+
+// Software is a piece of node software parsed from the node info, e.g. the container runtime or the OS distribution
+type Software struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+func (s *Software) PkgID() string {
+	id := fmt.Sprintf("%s:%s/%s", pkgPrefix, genericPrefix, url.PathEscape(s.Name))
+	if s.Version != "" {
+		id += "@" + url.PathEscape(s.Version)
+	}
+
+	return id
 }
 
 type Image struct {
-	FullName     string `json:"full_name"`
-	Name         string `json:"name"`
-	Version      string `json:"version"`
-	Digest       string `json:"digest"`
-	ControlPlane bool   `json:"-"`
+	FullName   string `json:"full_name"`
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Digest     string `json:"digest"`
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+	// Tag is the image tag, latest when the reference has neither a tag nor a digest
+	Tag string `json:"tag,omitempty" yaml:",omitempty"`
+	// Normalized is the fully qualified reference, e.g. docker.io/library/nginx:latest for nginx
+	Normalized string `json:"normalized"`
+	// SpecDigest is the digest pinned in the pod spec, RuntimeDigests the digests the running containers resolved to.
+	// Digest is the runtime digest when one is known, the spec digest otherwise.
+	SpecDigest     string   `json:"spec_digest,omitempty" yaml:",omitempty"`
+	RuntimeDigests []string `json:"runtime_digests,omitempty" yaml:",omitempty"`
+	// MutableTag is set when the image is pulled by tag only, LatestTag when that tag is (implicitly) latest
+	MutableTag bool `json:"mutable_tag,omitempty" yaml:",omitempty"`
+	LatestTag  bool `json:"latest_tag,omitempty" yaml:",omitempty"`
+	// DigestDrift is set when containers of the same image reference run different digests
+	DigestDrift bool `json:"digest_drift,omitempty" yaml:",omitempty"`
+	// Platforms are the os/arch platforms of the nodes running the image, with the digest resolved on each
+	Platforms    []ImagePlatform `json:"platforms,omitempty" yaml:",omitempty"`
+	ControlPlane bool            `json:"-"`
+}
+
+const (
+	// DigestKindRepository is the registry manifest digest, the manifest list digest for multi-arch images
+	DigestKindRepository = "repository"
+	// DigestKindPlatform is the platform specific image ID
+	DigestKindPlatform = "platform"
+)
+
+type ImagePlatform struct {
+	// Platform is the os/arch of the node, e.g. linux/arm64
+	Platform   string `json:"platform"`
+	Digest     string `json:"digest,omitempty"`
+	DigestKind string `json:"digest_kind,omitempty" yaml:",omitempty"`
 }
 
 func (i *Image) PkgID() string {
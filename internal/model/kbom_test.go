@@ -1,6 +1,7 @@
 package model
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -58,3 +59,50 @@ func TestImagePkgID(t *testing.T) {
 		})
 	}
 }
+
+func TestSoftwarePkgID(t *testing.T) {
+	testCases := []struct {
+		name       string
+		software   Software
+		expectedID string
+	}{
+		{
+			name:       "WithVersion",
+			software:   Software{Name: "containerd", Version: "1.7.2"},
+			expectedID: "pkg:generic/containerd@1.7.2",
+		},
+		{
+			name:       "NoVersion",
+			software:   Software{Name: "cos"},
+			expectedID: "pkg:generic/cos",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := tc.software.PkgID()
+			if result != tc.expectedID {
+				t.Errorf("Expected %s, but got %s", tc.expectedID, result)
+			}
+		})
+	}
+}
+
+func TestSummarizeRegistries(t *testing.T) {
+	images := []Image{
+		{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"},
+		{Registry: "docker.io", Repository: "library/nginx", Tag: "1.24"},
+		{Registry: "docker.io", Repository: "bitnami/redis", Tag: "7.2"},
+		{Registry: "registry.k8s.io", Repository: "kube-proxy", Tag: "v1.29.0"},
+	}
+
+	expected := []RegistrySummary{
+		{Registry: "docker.io", Images: 3, Repositories: []string{"bitnami/redis", "library/nginx"}},
+		{Registry: "registry.k8s.io", Images: 1, Repositories: []string{"kube-proxy"}},
+	}
+
+	result := SummarizeRegistries(images)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, but got %v", expected, result)
+	}
+}
@@ -0,0 +1,29 @@
+package model
+
+type ServiceMesh struct {
+	Meshes []Mesh `json:"meshes"`
+}
+
+// Mesh is a service mesh recognized by its control plane, CRDs or injected sidecars
+type Mesh struct {
+	Name         string              `json:"name"`
+	Version      string              `json:"version,omitempty" yaml:",omitempty"`
+	ControlPlane []DetectedComponent `json:"control_plane"`
+	CRDs         []string            `json:"crds"`
+	Namespaces   []MeshNamespace     `json:"namespaces"`
+}
+
+// MeshNamespace lists the sidecar proxy versions running in a namespace
+type MeshNamespace struct {
+	Namespace string         `json:"namespace"`
+	Sidecars  []SidecarProxy `json:"sidecars"`
+}
+
+type SidecarProxy struct {
+	Version   string   `json:"version"`
+	Image     string   `json:"image"`
+	Pods      int      `json:"pods"`
+	Workloads []string `json:"workloads"`
+	// Outdated is set when the proxy is older than the control plane
+	Outdated bool `json:"outdated"`
+}
@@ -0,0 +1,86 @@
+package model
+
+type Network struct {
+	ExposedServices                []ExposedService    `json:"exposed_services"`
+	Ingresses                      []Ingress           `json:"ingresses"`
+	Gateways                       []Gateway           `json:"gateways"`
+	Routes                         []Route             `json:"routes"`
+	Controllers                    []DetectedComponent `json:"controllers"`
+	NamespacesWithoutNetworkPolicy []string            `json:"namespaces_without_network_policy"`
+}
+
+// ExposedService is a LoadBalancer or NodePort Service
+type ExposedService struct {
+	Name        string        `json:"name"`
+	Namespace   string        `json:"namespace"`
+	Type        string        `json:"type"`
+	ExternalIPs []string      `json:"external_ips,omitempty" yaml:",omitempty"`
+	Ports       []ServicePort `json:"ports"`
+	Workloads   []WorkloadRef `json:"workloads"`
+}
+
+type ServicePort struct {
+	Name       string `json:"name,omitempty" yaml:",omitempty"`
+	Protocol   string `json:"protocol"`
+	Port       int32  `json:"port"`
+	NodePort   int32  `json:"node_port,omitempty" yaml:",omitempty"`
+	TargetPort string `json:"target_port,omitempty" yaml:",omitempty"`
+}
+
+type WorkloadRef struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+type Ingress struct {
+	Name              string      `json:"name"`
+	Namespace         string      `json:"namespace"`
+	ClassName         string      `json:"class_name,omitempty" yaml:",omitempty"`
+	Hosts             []string    `json:"hosts"`
+	TLS               []TLSConfig `json:"tls,omitempty" yaml:",omitempty"`
+	ExternalAddresses []string    `json:"external_addresses,omitempty" yaml:",omitempty"`
+	Backends          []Backend   `json:"backends"`
+}
+
+type TLSConfig struct {
+	Hosts      []string `json:"hosts,omitempty" yaml:",omitempty"`
+	SecretName string   `json:"secret_name"`
+}
+
+// Backend is a Service receiving traffic from an Ingress or a route, resolved to the workloads behind it
+type Backend struct {
+	Host      string        `json:"host,omitempty" yaml:",omitempty"`
+	Path      string        `json:"path,omitempty" yaml:",omitempty"`
+	Service   string        `json:"service"`
+	Namespace string        `json:"namespace"`
+	Port      string        `json:"port,omitempty" yaml:",omitempty"`
+	Workloads []WorkloadRef `json:"workloads"`
+}
+
+// Gateway is a Gateway API gateway
+type Gateway struct {
+	Name      string     `json:"name"`
+	Namespace string     `json:"namespace"`
+	ClassName string     `json:"class_name"`
+	Addresses []string   `json:"addresses,omitempty" yaml:",omitempty"`
+	Listeners []Listener `json:"listeners"`
+}
+
+type Listener struct {
+	Name       string   `json:"name"`
+	Hostname   string   `json:"hostname,omitempty" yaml:",omitempty"`
+	Port       int64    `json:"port"`
+	Protocol   string   `json:"protocol"`
+	TLSSecrets []string `json:"tls_secrets,omitempty" yaml:",omitempty"`
+}
+
+// Route is a Gateway API route (HTTPRoute, GRPCRoute, TLSRoute or TCPRoute)
+type Route struct {
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	Hostnames []string  `json:"hostnames,omitempty" yaml:",omitempty"`
+	Gateways  []string  `json:"gateways"`
+	Backends  []Backend `json:"backends"`
+}
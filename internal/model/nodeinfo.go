@@ -0,0 +1,93 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+const linuxKernel = "linux"
+
+var (
+	// semverPrefix matches the major.minor.patch part of versions like 1.6.8+bottlerocket or 5.15.0-1051-aws
+	semverPrefix = regexp.MustCompile(`^v?(\d+\.\d+(?:\.\d+)?)`)
+	// osVersion matches the first version-like token of an OS image, e.g. 22.04.3 in Ubuntu 22.04.3 LTS
+	osVersion = regexp.MustCompile(`\bv?(\d+(?:\.\d+)*)\b`)
+)
+
+// osDistributions maps OS image prefixes reported by the kubelet to os-release IDs. More specific prefixes go first.
+var osDistributions = []struct {
+	prefix string
+	id     string
+}{
+	{prefix: "Ubuntu", id: "ubuntu"},
+	{prefix: "Debian", id: "debian"},
+	{prefix: "Amazon Linux", id: "amzn"},
+	{prefix: "Bottlerocket", id: "bottlerocket"},
+	{prefix: "Container-Optimized OS", id: "cos"},
+	{prefix: "Red Hat Enterprise Linux CoreOS", id: "rhcos"},
+	{prefix: "Red Hat Enterprise Linux", id: "rhel"},
+	{prefix: "CentOS", id: "centos"},
+	{prefix: "Rocky Linux", id: "rocky"},
+	{prefix: "Fedora CoreOS", id: "fedora-coreos"},
+	{prefix: "Fedora", id: "fedora"},
+	{prefix: "Flatcar", id: "flatcar"},
+	{prefix: "Talos", id: "talos"},
+	{prefix: "CBL-Mariner", id: "mariner"},
+	{prefix: "Azure Linux", id: "azurelinux"},
+	{prefix: "SUSE Linux Enterprise", id: "sles"},
+	{prefix: "openSUSE", id: "opensuse"},
+	{prefix: "Alpine", id: "alpine"},
+	{prefix: "Windows", id: "windows"},
+}
+
+// ParseRuntime parses the container runtime version reported by the kubelet, e.g. containerd://1.7.2, nil when it has
+// no runtime name
+func ParseRuntime(runtimeVersion string) *Software {
+	name, version, ok := strings.Cut(runtimeVersion, "://")
+	if !ok || name == "" {
+		return nil
+	}
+
+	return &Software{Name: name, Version: semverCore(version)}
+}
+
+// ParseOS returns the distribution ID and version of the OS image, nil when the distribution is not known
+func ParseOS(osImage string) *Software {
+	for _, d := range osDistributions {
+		rest, ok := strings.CutPrefix(osImage, d.prefix)
+		if !ok {
+			continue
+		}
+
+		os := &Software{Name: d.id}
+		if m := osVersion.FindStringSubmatch(rest); m != nil {
+			os.Version = m[1]
+		}
+
+		return os
+	}
+
+	return nil
+}
+
+// ParseKernel returns the kernel version of Linux nodes, e.g. 5.15.0 for 5.15.0-1051-aws
+func ParseKernel(operatingSystem, kernelVersion string) *Software {
+	if operatingSystem != linuxKernel {
+		return nil
+	}
+
+	version := semverCore(kernelVersion)
+	if version == "" {
+		return nil
+	}
+
+	return &Software{Name: linuxKernel, Version: version}
+}
+
+func semverCore(version string) string {
+	if m := semverPrefix.FindStringSubmatch(version); m != nil {
+		return m[1]
+	}
+
+	return ""
+}
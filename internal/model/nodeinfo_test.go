@@ -0,0 +1,59 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRuntime(t *testing.T) {
+	tests := []struct {
+		runtimeVersion string
+		expected       *Software
+	}{
+		{runtimeVersion: "containerd://1.7.2", expected: &Software{Name: "containerd", Version: "1.7.2"}},
+		{runtimeVersion: "containerd://1.6.8+bottlerocket", expected: &Software{Name: "containerd", Version: "1.6.8"}},
+		{runtimeVersion: "cri-o://1.28.1", expected: &Software{Name: "cri-o", Version: "1.28.1"}},
+		{runtimeVersion: "docker://20.10.7", expected: &Software{Name: "docker", Version: "20.10.7"}},
+		{runtimeVersion: "containerd://v1.7.11-k3s2", expected: &Software{Name: "containerd", Version: "1.7.11"}},
+		{runtimeVersion: "unknown", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.runtimeVersion, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseRuntime(tt.runtimeVersion))
+		})
+	}
+}
+
+func TestParseOS(t *testing.T) {
+	tests := []struct {
+		osImage  string
+		expected *Software
+	}{
+		{osImage: "Ubuntu 22.04.3 LTS", expected: &Software{Name: "ubuntu", Version: "22.04.3"}},
+		{osImage: "Amazon Linux 2", expected: &Software{Name: "amzn", Version: "2"}},
+		{osImage: "Bottlerocket OS 1.11.1 (aws-k8s-1.24)", expected: &Software{Name: "bottlerocket", Version: "1.11.1"}},
+		{osImage: "Container-Optimized OS from Google", expected: &Software{Name: "cos"}},
+		{osImage: "Debian GNU/Linux 12 (bookworm)", expected: &Software{Name: "debian", Version: "12"}},
+		{
+			osImage:  "Red Hat Enterprise Linux CoreOS 414.92.202402051952-0 (Plow)",
+			expected: &Software{Name: "rhcos", Version: "414.92.202402051952"},
+		},
+		{osImage: "Talos (v1.6.1)", expected: &Software{Name: "talos", Version: "1.6.1"}},
+		{osImage: "Windows Server 2019 Datacenter", expected: &Software{Name: "windows", Version: "2019"}},
+		{osImage: "Some Custom Linux", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.osImage, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseOS(tt.osImage))
+		})
+	}
+}
+
+func TestParseKernel(t *testing.T) {
+	assert.Equal(t, &Software{Name: "linux", Version: "5.15.0"}, ParseKernel("linux", "5.15.0-1051-aws"))
+	assert.Equal(t, &Software{Name: "linux", Version: "6.1.66"}, ParseKernel("linux", "6.1.66-91.160.amzn2023.x86_64"))
+	assert.Nil(t, ParseKernel("windows", "10.0.17763.5329"))
+}
@@ -0,0 +1,24 @@
+package model
+
+// NodePool groups the nodes sharing a node pool or node group label
+type NodePool struct {
+	Name string `json:"name"`
+	// Label is the node label the pool was derived from, empty for nodes without any pool label
+	Label           string       `json:"label,omitempty" yaml:",omitempty"`
+	Nodes           int          `json:"nodes"`
+	InstanceTypes   []ValueCount `json:"instance_types"`
+	OsImages        []ValueCount `json:"os_images"`
+	KernelVersions  []ValueCount `json:"kernel_versions"`
+	KubeletVersions []ValueCount `json:"kubelet_versions"`
+	RuntimeVersions []ValueCount `json:"runtime_versions"`
+	Capacity        *Capacity    `json:"capacity"`
+	Allocatable     *Capacity    `json:"allocatable"`
+	// VersionSkew is set when the nodes of the pool run more than one OS image, kernel, kubelet or runtime version
+	VersionSkew bool `json:"version_skew"`
+}
+
+// ValueCount is the number of nodes sharing a value
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
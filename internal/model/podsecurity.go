@@ -0,0 +1,50 @@
+package model
+
+type PodSecurity struct {
+	Namespaces []NamespaceSecurity `json:"namespaces"`
+}
+
+type NamespaceSecurity struct {
+	Name                 string               `json:"name"`
+	PodSecurityAdmission PodSecurityAdmission `json:"pod_security_admission"`
+	Summary              SecuritySummary      `json:"summary"`
+	Workloads            []WorkloadSecurity   `json:"workloads"`
+}
+
+// PodSecurityAdmission holds the pod-security.kubernetes.io labels of a namespace
+type PodSecurityAdmission struct {
+	Enforce        string `json:"enforce,omitempty" yaml:",omitempty"`
+	EnforceVersion string `json:"enforce_version,omitempty" yaml:",omitempty"`
+	Audit          string `json:"audit,omitempty" yaml:",omitempty"`
+	AuditVersion   string `json:"audit_version,omitempty" yaml:",omitempty"`
+	Warn           string `json:"warn,omitempty" yaml:",omitempty"`
+	WarnVersion    string `json:"warn_version,omitempty" yaml:",omitempty"`
+}
+
+// SecuritySummary counts the workloads of a namespace with each risky setting
+type SecuritySummary struct {
+	Workloads              int `json:"workloads"`
+	Privileged             int `json:"privileged"`
+	HostNamespaces         int `json:"host_namespaces"`
+	HostPath               int `json:"host_path"`
+	AddedCapabilities      int `json:"added_capabilities"`
+	RunAsRoot              int `json:"run_as_root"`
+	WritableRootFilesystem int `json:"writable_root_filesystem"`
+	SeccompUnconfined      int `json:"seccomp_unconfined"`
+}
+
+type WorkloadSecurity struct {
+	Kind                   string   `json:"kind"`
+	Name                   string   `json:"name"`
+	Namespace              string   `json:"namespace"`
+	Privileged             bool     `json:"privileged"`
+	HostNetwork            bool     `json:"host_network"`
+	HostPID                bool     `json:"host_pid"`
+	HostIPC                bool     `json:"host_ipc"`
+	HostPathMounts         []string `json:"host_path_mounts,omitempty" yaml:",omitempty"`
+	AddedCapabilities      []string `json:"added_capabilities,omitempty" yaml:",omitempty"`
+	RunAsNonRoot           bool     `json:"run_as_non_root"`
+	ReadOnlyRootFilesystem bool     `json:"read_only_root_filesystem"`
+	SeccompProfiles        []string `json:"seccomp_profiles,omitempty" yaml:",omitempty"`
+	AppArmorProfiles       []string `json:"apparmor_profiles,omitempty" yaml:",omitempty"`
+}
@@ -0,0 +1,27 @@
+package model
+
+type PullSecrets struct {
+	Secrets []PullSecret `json:"secrets"`
+}
+
+// PullSecret is an image pull secret, either existing or referenced by a pod or ServiceAccount.
+// Only the registry hostnames are read from the secret, never the credentials.
+type PullSecret struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Type      string `json:"type,omitempty" yaml:",omitempty"`
+	// Registries are the registry hostnames the secret has credentials for
+	Registries []string `json:"registries,omitempty" yaml:",omitempty"`
+	// Missing is set when the secret is referenced but does not exist
+	Missing         bool             `json:"missing,omitempty" yaml:",omitempty"`
+	ServiceAccounts []string         `json:"service_accounts,omitempty" yaml:",omitempty"`
+	Workloads       []PullSecretUser `json:"workloads,omitempty" yaml:",omitempty"`
+}
+
+// PullSecretUser is a workload using a pull secret, Via is either pod or serviceaccount
+type PullSecretUser struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Via       string `json:"via"`
+}
@@ -0,0 +1,66 @@
+package model
+
+const (
+	RiskWildcardVerbs = "wildcard_verbs"
+	RiskEscalate      = "escalate"
+	RiskBind          = "bind"
+	RiskImpersonate   = "impersonate"
+	RiskSecretsRead   = "secrets_read"
+	RiskNodesProxy    = "nodes_proxy"
+)
+
+type RBAC struct {
+	ClusterRoles []Role          `json:"cluster_roles"`
+	Roles        []Role          `json:"roles"`
+	Bindings     []RoleBinding   `json:"bindings"`
+	Subjects     []SubjectAccess `json:"subjects"`
+}
+
+type Role struct {
+	Kind      string       `json:"kind"`
+	Name      string       `json:"name"`
+	Namespace string       `json:"namespace,omitempty" yaml:",omitempty"`
+	Rules     []PolicyRule `json:"rules"`
+	Risks     []string     `json:"risks,omitempty" yaml:",omitempty"`
+}
+
+type PolicyRule struct {
+	APIGroups       []string `json:"api_groups,omitempty" yaml:",omitempty"`
+	Resources       []string `json:"resources,omitempty" yaml:",omitempty"`
+	ResourceNames   []string `json:"resource_names,omitempty" yaml:",omitempty"`
+	NonResourceURLs []string `json:"non_resource_urls,omitempty" yaml:",omitempty"`
+	Verbs           []string `json:"verbs"`
+}
+
+type RoleBinding struct {
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace,omitempty" yaml:",omitempty"`
+	RoleRef   RoleRef   `json:"role_ref"`
+	Subjects  []Subject `json:"subjects"`
+}
+
+type RoleRef struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+type Subject struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty" yaml:",omitempty"`
+}
+
+// SubjectAccess lists all roles granted to a user, group or ServiceAccount
+type SubjectAccess struct {
+	Subject
+	Grants []Grant  `json:"grants"`
+	Risks  []string `json:"risks,omitempty" yaml:",omitempty"`
+}
+
+type Grant struct {
+	Role      RoleRef  `json:"role"`
+	Binding   string   `json:"binding"`
+	Namespace string   `json:"namespace,omitempty" yaml:",omitempty"`
+	Risks     []string `json:"risks,omitempty" yaml:",omitempty"`
+}
@@ -0,0 +1,42 @@
+package model
+
+import "sort"
+
+// RegistrySummary lists the repositories pulled from a registry
+type RegistrySummary struct {
+	Registry     string   `json:"registry"`
+	Images       int      `json:"images"`
+	Repositories []string `json:"repositories"`
+}
+
+// SummarizeRegistries groups the images by registry, sorted by registry name
+func SummarizeRegistries(images []Image) []RegistrySummary {
+	summaries := make(map[string]*RegistrySummary)
+	repositories := make(map[string]map[string]bool)
+	for i := range images {
+		img := &images[i]
+		summary, ok := summaries[img.Registry]
+		if !ok {
+			summary = &RegistrySummary{Registry: img.Registry, Repositories: make([]string, 0)}
+			summaries[img.Registry] = summary
+			repositories[img.Registry] = make(map[string]bool)
+		}
+
+		summary.Images++
+		if !repositories[img.Registry][img.Repository] {
+			repositories[img.Registry][img.Repository] = true
+			summary.Repositories = append(summary.Repositories, img.Repository)
+		}
+	}
+
+	res := make([]RegistrySummary, 0, len(summaries))
+	for _, summary := range summaries {
+		sort.Strings(summary.Repositories)
+		res = append(res, *summary)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Registry < res[j].Registry
+	})
+
+	return res
+}
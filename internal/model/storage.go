@@ -0,0 +1,65 @@
+package model
+
+type Storage struct {
+	CSIDrivers        []CSIDriver             `json:"csi_drivers"`
+	StorageClasses    []StorageClass          `json:"storage_classes"`
+	PersistentVolumes PersistentVolumeSummary `json:"persistent_volumes"`
+	Claims            []ClaimSummary          `json:"claims"`
+}
+
+// CSIDriver is a registered CSI driver together with the workloads running its controller and node plugins
+type CSIDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty" yaml:",omitempty"`
+	AttachRequired bool        `json:"attach_required"`
+	PodInfoOnMount bool        `json:"pod_info_on_mount"`
+	LifecycleModes []string    `json:"lifecycle_modes,omitempty" yaml:",omitempty"`
+	Plugins        []CSIPlugin `json:"plugins"`
+}
+
+type CSIPlugin struct {
+	// Component is either controller or node
+	Component  string         `json:"component"`
+	Namespace  string         `json:"namespace"`
+	Workload   string         `json:"workload"`
+	Containers []CSIContainer `json:"containers"`
+}
+
+type CSIContainer struct {
+	Name    string `json:"name"`
+	Image   string `json:"image"`
+	Version string `json:"version,omitempty" yaml:",omitempty"`
+	// Sidecar is set for the upstream kubernetes-csi sidecars (provisioner, attacher, registrar, ...)
+	Sidecar bool `json:"sidecar"`
+}
+
+// StorageClass parameters referring to secrets are left out
+type StorageClass struct {
+	Name                 string            `json:"name"`
+	Provisioner          string            `json:"provisioner"`
+	Default              bool              `json:"default"`
+	ReclaimPolicy        string            `json:"reclaim_policy"`
+	VolumeBindingMode    string            `json:"volume_binding_mode"`
+	AllowVolumeExpansion bool              `json:"allow_volume_expansion"`
+	Parameters           map[string]string `json:"parameters,omitempty" yaml:",omitempty"`
+}
+
+type PersistentVolumeSummary struct {
+	Total     int    `json:"total"`
+	Available int    `json:"available"`
+	Bound     int    `json:"bound"`
+	Released  int    `json:"released"`
+	Failed    int    `json:"failed"`
+	Capacity  string `json:"capacity"`
+}
+
+// ClaimSummary counts the PersistentVolumeClaims of a namespace
+type ClaimSummary struct {
+	Namespace      string   `json:"namespace"`
+	Total          int      `json:"total"`
+	Bound          int      `json:"bound"`
+	Pending        int      `json:"pending"`
+	Lost           int      `json:"lost"`
+	Requested      string   `json:"requested"`
+	StorageClasses []string `json:"storage_classes"`
+}
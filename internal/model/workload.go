@@ -0,0 +1,33 @@
+package model
+
+type Workloads struct {
+	// Counts is the number of workloads per kind
+	Counts map[string]int `json:"counts"`
+	Items  []Workload     `json:"items"`
+}
+
+// Workload is a Deployment, StatefulSet, DaemonSet, Job, CronJob or a Pod not managed by any of them
+type Workload struct {
+	Kind           string              `json:"kind"`
+	Name           string              `json:"name"`
+	Namespace      string              `json:"namespace"`
+	Replicas       *int32              `json:"replicas,omitempty" yaml:",omitempty"`
+	Selector       string              `json:"selector,omitempty" yaml:",omitempty"`
+	Schedule       string              `json:"schedule,omitempty" yaml:",omitempty"`
+	ServiceAccount string              `json:"service_account"`
+	Containers     []WorkloadContainer `json:"containers"`
+	// Owners is the controller owner chain, nearest owner first
+	Owners []Owner `json:"owners,omitempty" yaml:",omitempty"`
+}
+
+type WorkloadContainer struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+	Init  bool   `json:"init,omitempty" yaml:",omitempty"`
+}
+
+type Owner struct {
+	APIVersion string `json:"api_version"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+}
@@ -0,0 +1,8 @@
+package operator
+
+import "embed"
+
+// CRDs holds the KBOMReport and KBOMRequest CustomResourceDefinitions
+//
+//go:embed crds/*.yaml
+var CRDs embed.FS
@@ -0,0 +1,231 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+const (
+	ConfigMapStorage = "configmap"
+	CRDStorage       = "crd"
+
+	triggerSchedule = "schedule"
+	triggerRequest  = "request"
+
+	phaseCompleted = "Completed"
+	phaseFailed    = "Failed"
+
+	leaseDuration = 15 * time.Second
+	renewDeadline = 10 * time.Second
+	retryPeriod   = 2 * time.Second
+)
+
+// GenerateFunc generates a new KBOM of the cluster
+type GenerateFunc func(ctx context.Context) (*model.KBOM, error)
+
+type Config struct {
+	// Namespace the reports, requests and the leader election lease live in
+	Namespace string
+	// Storage is either configmap or crd
+	Storage string
+	// Interval between scheduled reports, RequestInterval between checks for new KBOMRequests
+	Interval        time.Duration
+	RequestInterval time.Duration
+	// Retention is the number of reports kept
+	Retention int
+
+	LeaderElect bool
+	LeaseName   string
+	Identity    string
+}
+
+// Operator periodically, and on every new KBOMRequest, generates a KBOM and stores it in the cluster
+type Operator struct {
+	cfg           Config
+	client        kubernetes.Interface
+	dynamicClient dynamic.Interface
+	store         store
+	generate      GenerateFunc
+}
+
+func New(cfg Config, client kubernetes.Interface, dynamicClient dynamic.Interface, generate GenerateFunc) (*Operator, error) {
+	if cfg.Interval <= 0 || cfg.RequestInterval <= 0 {
+		return nil, fmt.Errorf("intervals must be positive")
+	}
+	if cfg.Retention < 1 {
+		return nil, fmt.Errorf("retention must be at least 1")
+	}
+
+	o := &Operator{
+		cfg:           cfg,
+		client:        client,
+		dynamicClient: dynamicClient,
+		generate:      generate,
+	}
+
+	switch cfg.Storage {
+	case ConfigMapStorage:
+		o.store = &configMapStore{client: client, namespace: cfg.Namespace}
+	case CRDStorage:
+		o.store = &crdStore{client: dynamicClient, namespace: cfg.Namespace}
+	default:
+		return nil, fmt.Errorf("storage %q is not supported, use one of: %s, %s", cfg.Storage, ConfigMapStorage, CRDStorage)
+	}
+
+	return o, nil
+}
+
+// Run runs the operator until ctx is cancelled. With leader election only the leader generates reports,
+// and losing the lease ends Run with an error so the pod restarts and campaigns again.
+func (o *Operator) Run(ctx context.Context) error {
+	if !o.cfg.LeaderElect {
+		o.loop(ctx)
+		return nil
+	}
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta:  metav1.ObjectMeta{Name: o.cfg.LeaseName, Namespace: o.cfg.Namespace},
+		Client:     o.client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: o.cfg.Identity},
+	}
+
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		ReleaseOnCancel: true,
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     retryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: o.loop,
+			OnStoppedLeading: func() {
+				log.Info().Str("identity", o.cfg.Identity).Msg("Stopped leading")
+			},
+			OnNewLeader: func(identity string) {
+				log.Info().Str("leader", identity).Msg("New leader elected")
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create leader elector: %w", err)
+	}
+
+	elector.Run(ctx)
+	if ctx.Err() == nil {
+		return errors.New("leader election lost")
+	}
+
+	return nil
+}
+
+func (o *Operator) loop(ctx context.Context) {
+	log.Info().Str("namespace", o.cfg.Namespace).Str("storage", o.cfg.Storage).Dur("interval", o.cfg.Interval).
+		Msg("Starting KBOM operator")
+
+	o.reconcile(ctx)
+
+	schedule := time.NewTicker(o.cfg.Interval)
+	defer schedule.Stop()
+	requests := time.NewTicker(o.cfg.RequestInterval)
+	defer requests.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-schedule.C:
+			o.reconcile(ctx)
+		case <-requests.C:
+			if err := o.processRequests(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to process KBOM requests")
+			}
+		}
+	}
+}
+
+// reconcile generates a scheduled report, errors are logged and retried on the next tick
+func (o *Operator) reconcile(ctx context.Context) {
+	name, err := o.generateReport(ctx, triggerSchedule)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to generate scheduled KBOM")
+		return
+	}
+
+	log.Info().Str("report", name).Msg("Generated scheduled KBOM")
+}
+
+func (o *Operator) generateReport(ctx context.Context, trigger string) (string, error) {
+	kbom, err := o.generate(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	r, err := newReport(kbom, trigger)
+	if err != nil {
+		return "", err
+	}
+
+	name, err := o.store.save(ctx, r)
+	if err != nil {
+		return "", err
+	}
+
+	if err := o.store.prune(ctx, o.cfg.Retention); err != nil {
+		log.Error().Err(err).Msg("Failed to prune old KBOM reports")
+	}
+
+	return name, nil
+}
+
+// processRequests generates a report for every KBOMRequest without a phase and records the result in its status
+func (o *Operator) processRequests(ctx context.Context) error {
+	requests, err := o.dynamicClient.Resource(RequestResource).Namespace(o.cfg.Namespace).List(ctx, metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		log.Debug().Msgf("%s CRD is not installed, skipping requests", RequestKind)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", RequestKind, err)
+	}
+
+	for i := range requests.Items {
+		req := &requests.Items[i]
+		if phase, _, _ := unstructured.NestedString(req.Object, "status", "phase"); phase != "" {
+			continue
+		}
+
+		status := map[string]interface{}{"completedAt": time.Now().UTC().Format(time.RFC3339)}
+		name, err := o.generateReport(ctx, triggerRequest)
+		if err != nil {
+			log.Error().Err(err).Str("request", req.GetName()).Msg("Failed to generate requested KBOM")
+			status["phase"] = phaseFailed
+			status["message"] = err.Error()
+		} else {
+			log.Info().Str("request", req.GetName()).Str("report", name).Msg("Generated requested KBOM")
+			status["phase"] = phaseCompleted
+			status["report"] = name
+		}
+
+		if err := unstructured.SetNestedMap(req.Object, status, "status"); err != nil {
+			return err
+		}
+		if _, err := o.dynamicClient.Resource(RequestResource).Namespace(o.cfg.Namespace).UpdateStatus(ctx, req,
+			metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update %s %s status: %w", RequestKind, req.GetName(), err)
+		}
+	}
+
+	return nil
+}
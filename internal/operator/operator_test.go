@@ -0,0 +1,157 @@
+package operator
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+func newTestOperator(t *testing.T, storage string, objects ...runtime.Object) *Operator {
+	t.Helper()
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		ReportResource:  ReportKind + "List",
+		RequestResource: RequestKind + "List",
+	}, objects...)
+
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	generate := func(context.Context) (*model.KBOM, error) {
+		at = at.Add(time.Hour)
+		return &model.KBOM{ID: "0123456789abcdef", GeneratedAt: at, Cluster: model.Cluster{Name: "test"}}, nil
+	}
+
+	o, err := New(Config{
+		Namespace:       "kbom",
+		Storage:         storage,
+		Interval:        time.Hour,
+		RequestInterval: time.Minute,
+		Retention:       2,
+	}, fake.NewSimpleClientset(), dynamicClient, generate)
+	require.NoError(t, err)
+
+	return o
+}
+
+func TestConfigMapStoreRetention(t *testing.T) {
+	o := newTestOperator(t, ConfigMapStorage)
+
+	for i := 0; i < 3; i++ {
+		_, err := o.generateReport(context.Background(), triggerSchedule)
+		require.NoError(t, err)
+	}
+
+	list, err := o.client.CoreV1().ConfigMaps("kbom").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(list.Items))
+	for _, cm := range list.Items {
+		names = append(names, cm.Name)
+		assert.NotEmpty(t, cm.BinaryData[reportDataKey])
+		assert.Equal(t, triggerSchedule, cm.Labels[triggerLabel])
+	}
+	assert.ElementsMatch(t, []string{"kbom-20240101-020000-01234567", "kbom-20240101-030000-01234567"}, names)
+}
+
+func TestStoreReportSize(t *testing.T) {
+	tests := []struct {
+		storage string
+		kind    string
+		// fits is the largest compressed report the store accepts
+		fits int
+	}{
+		{storage: ConfigMapStorage, kind: "ConfigMap", fits: maxReportSize},
+		{storage: CRDStorage, kind: ReportKind, fits: maxReportSize / 4 * 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.storage, func(t *testing.T) {
+			s := newTestOperator(t, tt.storage).store
+			r := &report{kbom: &model.KBOM{ID: "0123456789abcdef", GeneratedAt: time.Now()}, data: make([]byte, tt.fits)}
+
+			_, err := s.save(context.Background(), r)
+			require.NoError(t, err)
+
+			r.data = make([]byte, tt.fits+1)
+			r.kbom.ID = "fedcba9876543210"
+			_, err = s.save(context.Background(), r)
+			require.ErrorContains(t, err, "exceeds the 1048576 bytes a "+tt.kind+" can hold")
+		})
+	}
+}
+
+func TestProcessRequests(t *testing.T) {
+	pending := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": Group + "/" + Version,
+		"kind":       RequestKind,
+		"metadata":   map[string]interface{}{"name": "now", "namespace": "kbom"},
+	}}
+	done := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": Group + "/" + Version,
+		"kind":       RequestKind,
+		"metadata":   map[string]interface{}{"name": "earlier", "namespace": "kbom"},
+		"status":     map[string]interface{}{"phase": phaseCompleted, "report": "kbom-old"},
+	}}
+
+	o := newTestOperator(t, CRDStorage, pending, done)
+	require.NoError(t, o.processRequests(context.Background()))
+
+	req, err := o.dynamicClient.Resource(RequestResource).Namespace("kbom").Get(context.Background(), "now", metav1.GetOptions{})
+	require.NoError(t, err)
+	phase, _, _ := unstructured.NestedString(req.Object, "status", "phase")
+	report, _, _ := unstructured.NestedString(req.Object, "status", "report")
+	assert.Equal(t, phaseCompleted, phase)
+	assert.Equal(t, "kbom-20240101-010000-01234567", report)
+
+	reports, err := o.dynamicClient.Resource(ReportResource).Namespace("kbom").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, reports.Items, 1, "only the pending request generates a report")
+	encoding, _, _ := unstructured.NestedString(reports.Items[0].Object, "spec", "encoding")
+	assert.Equal(t, reportEncoding, encoding)
+	assert.Equal(t, triggerRequest, reports.Items[0].GetLabels()[triggerLabel])
+}
+
+func TestCRDs(t *testing.T) {
+	resources := map[string]schema.GroupVersionResource{ReportKind: ReportResource, RequestKind: RequestResource}
+
+	files, err := fs.Glob(CRDs, "crds/*.yaml")
+	require.NoError(t, err)
+	require.Len(t, files, len(resources))
+
+	for _, file := range files {
+		data, err := fs.ReadFile(CRDs, file)
+		require.NoError(t, err)
+
+		var crd struct {
+			Spec struct {
+				Group string `yaml:"group"`
+				Names struct {
+					Kind   string `yaml:"kind"`
+					Plural string `yaml:"plural"`
+				} `yaml:"names"`
+				Versions []struct {
+					Name string `yaml:"name"`
+				} `yaml:"versions"`
+			} `yaml:"spec"`
+		}
+		require.NoError(t, yaml.Unmarshal(data, &crd), file)
+
+		gvr, ok := resources[crd.Spec.Names.Kind]
+		require.True(t, ok, file)
+		assert.Equal(t, gvr.Group, crd.Spec.Group)
+		assert.Equal(t, gvr.Resource, crd.Spec.Names.Plural)
+		assert.Equal(t, gvr.Version, crd.Spec.Versions[0].Name)
+	}
+}
@@ -0,0 +1,38 @@
+package operator
+
+import (
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/rad-security/kbom/internal/kube"
+)
+
+// Section is the section of the permissions the operator needs in its namespace, on top of the ones of generate
+const Section = "operator"
+
+// Permissions returns the permissions the operator needs in its namespace to read the KBOMRequests, store the reports
+// and, with leader election, hold the lease. Creating a lease can not be limited to its name.
+func Permissions(cfg Config) []kube.Permission {
+	permissions := []kube.Permission{
+		{Section: Section, Verb: "list", Group: Group, Resource: RequestResource.Resource},
+		{Section: Section, Verb: "update", Group: Group, Resource: RequestResource.Resource + "/status"},
+	}
+
+	reports := ReportResource.GroupResource()
+	if cfg.Storage == ConfigMapStorage {
+		reports = schema.GroupResource{Resource: "configmaps"}
+	}
+	for _, verb := range []string{"create", "list", "delete"} {
+		permissions = append(permissions, kube.Permission{Section: Section, Verb: verb, Group: reports.Group, Resource: reports.Resource})
+	}
+
+	if cfg.LeaderElect {
+		permissions = append(permissions,
+			kube.Permission{Section: Section, Verb: "create", Group: coordinationv1.GroupName, Resource: "leases"},
+			kube.Permission{Section: Section, Verb: "get", Group: coordinationv1.GroupName, Resource: "leases", Name: cfg.LeaseName},
+			kube.Permission{Section: Section, Verb: "update", Group: coordinationv1.GroupName, Resource: "leases", Name: cfg.LeaseName},
+		)
+	}
+
+	return permissions
+}
@@ -0,0 +1,236 @@
+package operator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+const (
+	Group   = "kbom.rad.security"
+	Version = "v1alpha1"
+
+	ReportKind  = "KBOMReport"
+	RequestKind = "KBOMRequest"
+
+	reportLabel           = Group + "/report"
+	managedByLabel        = "app.kubernetes.io/managed-by"
+	kbomIDAnnotation      = Group + "/id"
+	triggerLabel          = Group + "/trigger"
+	generatedAtAnnotation = Group + "/generated-at"
+	reportDataKey         = "kbom.json.gz"
+	reportEncoding        = "gzip+base64"
+	reportNamePrefix      = "kbom-"
+	managedBy             = "kbom"
+
+	// maxReportSize is the most report data stored in one object. ConfigMaps are limited to 1MiB of data and etcd
+	// rejects requests over 1.5MiB, this leaves room for the metadata.
+	maxReportSize = 1 << 20
+)
+
+var (
+	ReportResource  = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "kbomreports"}
+	RequestResource = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "kbomrequests"}
+)
+
+// report is a generated KBOM, compressed for storage
+type report struct {
+	kbom    *model.KBOM
+	data    []byte
+	trigger string
+}
+
+// store keeps the generated reports, pruning all but the newest retention ones
+type store interface {
+	save(ctx context.Context, r *report) (string, error)
+	prune(ctx context.Context, retention int) error
+}
+
+func newReport(kbom *model.KBOM, trigger string) (*report, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(kbom); err != nil {
+		return nil, fmt.Errorf("failed to encode kbom: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress kbom: %w", err)
+	}
+
+	return &report{kbom: kbom, data: buf.Bytes(), trigger: trigger}, nil
+}
+
+// checkReportSize returns an error when the stored report data would not fit in one object
+func checkReportSize(kind string, size int) error {
+	if size > maxReportSize {
+		return fmt.Errorf("report of %d bytes exceeds the %d bytes a %s can hold, exclude some sections with --exclude",
+			size, maxReportSize, kind)
+	}
+
+	return nil
+}
+
+func (r *report) name() string {
+	id := r.kbom.ID
+	if len(id) > 8 {
+		id = id[:8]
+	}
+
+	return fmt.Sprintf("%s%s-%s", reportNamePrefix, r.kbom.GeneratedAt.UTC().Format("20060102-150405"), id)
+}
+
+func (r *report) labels() map[string]string {
+	return map[string]string{
+		managedByLabel: managedBy,
+		reportLabel:    "true",
+		triggerLabel:   r.trigger,
+	}
+}
+
+func (r *report) annotations() map[string]string {
+	return map[string]string{
+		kbomIDAnnotation:      r.kbom.ID,
+		generatedAtAnnotation: r.kbom.GeneratedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// configMapStore keeps each report gzipped in the binary data of a ConfigMap
+type configMapStore struct {
+	client    kubernetes.Interface
+	namespace string
+}
+
+func (s *configMapStore) save(ctx context.Context, r *report) (string, error) {
+	if err := checkReportSize("ConfigMap", len(r.data)); err != nil {
+		return "", err
+	}
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        r.name(),
+			Namespace:   s.namespace,
+			Labels:      r.labels(),
+			Annotations: r.annotations(),
+		},
+		BinaryData: map[string][]byte{reportDataKey: r.data},
+	}
+
+	if _, err := s.client.CoreV1().ConfigMaps(s.namespace).Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to create report config map: %w", err)
+	}
+
+	return cm.Name, nil
+}
+
+func (s *configMapStore) prune(ctx context.Context, retention int) error {
+	list, err := s.client.CoreV1().ConfigMaps(s.namespace).List(ctx, metav1.ListOptions{LabelSelector: reportLabel + "=true"})
+	if err != nil {
+		return fmt.Errorf("failed to list report config maps: %w", err)
+	}
+
+	reports := make([]metav1.Object, 0, len(list.Items))
+	for i := range list.Items {
+		reports = append(reports, &list.Items[i])
+	}
+
+	for _, name := range expired(reports, retention) {
+		if err := s.client.CoreV1().ConfigMaps(s.namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("failed to delete report config map %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// crdStore keeps each report as a KBOMReport custom resource
+type crdStore struct {
+	client    dynamic.Interface
+	namespace string
+}
+
+func (s *crdStore) save(ctx context.Context, r *report) (string, error) {
+	data := base64.StdEncoding.EncodeToString(r.data)
+	if err := checkReportSize(ReportKind, len(data)); err != nil {
+		return "", err
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": Group + "/" + Version,
+		"kind":       ReportKind,
+		"spec": map[string]interface{}{
+			"id":          r.kbom.ID,
+			"generatedAt": r.kbom.GeneratedAt.UTC().Format(time.RFC3339),
+			"cluster":     r.kbom.Cluster.Name,
+			"k8sVersion":  r.kbom.Cluster.K8sVersion,
+			"trigger":     r.trigger,
+			"encoding":    reportEncoding,
+			"data":        data,
+		},
+	}}
+	obj.SetName(r.name())
+	obj.SetNamespace(s.namespace)
+	obj.SetLabels(r.labels())
+	obj.SetAnnotations(r.annotations())
+
+	if _, err := s.client.Resource(ReportResource).Namespace(s.namespace).Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", ReportKind, err)
+	}
+
+	return obj.GetName(), nil
+}
+
+func (s *crdStore) prune(ctx context.Context, retention int) error {
+	list, err := s.client.Resource(ReportResource).Namespace(s.namespace).List(ctx, metav1.ListOptions{LabelSelector: reportLabel + "=true"})
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", ReportKind, err)
+	}
+
+	reports := make([]metav1.Object, 0, len(list.Items))
+	for i := range list.Items {
+		reports = append(reports, &list.Items[i])
+	}
+
+	for _, name := range expired(reports, retention) {
+		if err := s.client.Resource(ReportResource).Namespace(s.namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("failed to delete %s %s: %w", ReportKind, name, err)
+		}
+	}
+
+	return nil
+}
+
+// expired returns the names of all but the newest retention reports, ordered by their generation time
+func expired(reports []metav1.Object, retention int) []string {
+	if len(reports) <= retention {
+		return nil
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		ti, tj := reports[i].GetAnnotations()[generatedAtAnnotation], reports[j].GetAnnotations()[generatedAtAnnotation]
+		if ti != tj {
+			return ti > tj
+		}
+
+		return reports[i].GetName() > reports[j].GetName()
+	})
+
+	names := make([]string, 0, len(reports)-retention)
+	for _, r := range reports[retention:] {
+		names = append(names, r.GetName())
+	}
+
+	return names
+}
@@ -0,0 +1,328 @@
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+// Fields that can be redacted, and the profiles combining them
+const (
+	Nodes      = "nodes"
+	Metadata   = "metadata"
+	Namespaces = "namespaces"
+	Registries = "registries"
+	Cluster    = "cluster"
+
+	VendorProfile = "vendor"
+	StrictProfile = "strict"
+
+	hashPrefix    = "redacted-"
+	hashLength    = 16
+	namespaceKind = "Namespace"
+)
+
+// selectorTerm matches the keys and values of label selectors, as formatted by metav1.FormatLabelSelector
+var selectorTerm = regexp.MustCompile(`[^\s,()!=]+`)
+
+// Profiles are the named sets of redacted fields
+var Profiles = map[string][]string{
+	VendorProfile: {Nodes, Metadata, Registries},
+	StrictProfile: {Nodes, Metadata, Namespaces, Registries, Cluster},
+}
+
+// Names returns the profiles followed by the fields, as accepted by NewOptions
+func Names() []string {
+	return []string{VendorProfile, StrictProfile, Nodes, Metadata, Namespaces, Registries, Cluster}
+}
+
+// publicRegistries are the registries of public images, every other registry hostname is considered internal
+var publicRegistries = []string{
+	"docker.io", "index.docker.io", "registry-1.docker.io", "ghcr.io", "quay.io", "registry.k8s.io", "k8s.gcr.io", "gcr.io",
+	"*.gcr.io", "public.ecr.aws", "mcr.microsoft.com", "registry.gitlab.com", "docker.elastic.co", "nvcr.io", "cgr.dev",
+}
+
+// Options select the fields to redact
+type Options struct {
+	// Nodes hashes node names and hostnames wherever they appear, and drops the machine and boot IDs
+	Nodes bool
+	// Metadata drops the labels and annotations with keys matching MetadataPatterns, in which * matches any characters
+	Metadata         bool
+	MetadataPatterns []string
+	// Namespaces hashes namespace names
+	Namespaces bool
+	// Registries hashes the hostnames of internal registries in image references
+	Registries bool
+	// Cluster hashes the cluster name wherever it appears
+	Cluster bool
+}
+
+// NewOptions returns the options redacting the fields of the given profiles and fields
+func NewOptions(names []string, metadataPatterns []string) (Options, error) {
+	opts := Options{MetadataPatterns: metadataPatterns}
+	for _, name := range names {
+		fields, ok := Profiles[name]
+		if !ok {
+			fields = []string{name}
+		}
+
+		for _, field := range fields {
+			switch field {
+			case Nodes:
+				opts.Nodes = true
+			case Metadata:
+				opts.Metadata = true
+			case Namespaces:
+				opts.Namespaces = true
+			case Registries:
+				opts.Registries = true
+			case Cluster:
+				opts.Cluster = true
+			default:
+				return Options{}, fmt.Errorf("redaction %q is not supported, use one of: %s", name, strings.Join(Names(), ", "))
+			}
+		}
+	}
+
+	return opts, nil
+}
+
+// hashes reports whether any of the options replaces values with their keyed hash
+func (o *Options) hashes() bool {
+	return o.Nodes || o.Namespaces || o.Registries || o.Cluster
+}
+
+// Redactor hashes or drops sensitive fields of KBOMs. Values are replaced with their HMAC-SHA256 under the key, so
+// KBOMs redacted with the same key can still be joined on them.
+type Redactor struct {
+	opts     Options
+	key      []byte
+	metadata []*regexp.Regexp
+}
+
+// New returns a redactor, the key is required when any value is hashed
+func New(opts Options, key []byte) (*Redactor, error) {
+	if opts.hashes() && len(key) == 0 {
+		return nil, errors.New("a key is required to hash the redacted values")
+	}
+
+	metadata := make([]*regexp.Regexp, 0, len(opts.MetadataPatterns))
+	for _, pattern := range opts.MetadataPatterns {
+		expr := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+		metadata = append(metadata, regexp.MustCompile("^"+expr+"$"))
+	}
+
+	return &Redactor{opts: opts, key: key, metadata: metadata}, nil
+}
+
+// Redact redacts the KBOM in place, a nil redactor leaves it unchanged
+func (r *Redactor) Redact(kbom *model.KBOM) {
+	if r == nil {
+		return
+	}
+
+	red := &redaction{Redactor: r, values: make(map[string]bool)}
+	cluster := &kbom.Cluster
+	if r.opts.Cluster && cluster.Name != "" {
+		red.values[cluster.Name] = true
+	}
+	if r.opts.Nodes {
+		red.redactNodes(cluster.Nodes)
+	}
+	if r.opts.Namespaces {
+		red.recordNamespaces(&cluster.Components)
+	}
+	if r.opts.Registries {
+		red.collectRegistries(&cluster.Components)
+	}
+
+	red.walk(reflect.ValueOf(kbom).Elem(), "")
+}
+
+// Context returns the kubeconfig context name, hashed when the cluster is redacted as contexts are usually named after
+// their cluster. A nil redactor returns it unchanged.
+func (r *Redactor) Context(name string) string {
+	if r == nil || !r.opts.Cluster {
+		return name
+	}
+
+	return r.hash(name)
+}
+
+// hash returns the keyed hash of the value, values hashed before are kept so redacting a KBOM again keeps them joinable
+func (r *Redactor) hash(value string) string {
+	if value == "" || strings.HasPrefix(value, hashPrefix) {
+		return value
+	}
+
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(value))
+
+	return hashPrefix + hex.EncodeToString(mac.Sum(nil))[:hashLength]
+}
+
+// redaction is the redaction of one KBOM, with the node and cluster names and the internal registries found in it
+type redaction struct {
+	*Redactor
+	values map[string]bool
+	hosts  []string
+}
+
+// redactNodes drops the machine and boot IDs of the nodes, and records their names and hostnames to be hashed
+func (red *redaction) redactNodes(nodes []model.Node) {
+	for i := range nodes {
+		for _, name := range []string{nodes[i].Name, nodes[i].Hostname} {
+			if name != "" {
+				red.values[name] = true
+			}
+		}
+		nodes[i].MachineID, nodes[i].BootID = "", ""
+	}
+}
+
+// recordNamespaces records the names of the Namespace resources and of the pod security namespaces to be hashed, the
+// namespaces of other resources are hashed by the field holding them
+func (red *redaction) recordNamespaces(components *model.Components) {
+	for _, list := range components.Resources {
+		if list.Kind != namespaceKind {
+			continue
+		}
+		for i := range list.Resources {
+			red.values[list.Resources[i].Name] = true
+		}
+	}
+	if components.PodSecurity != nil {
+		for i := range components.PodSecurity.Namespaces {
+			red.values[components.PodSecurity.Namespaces[i].Name] = true
+		}
+	}
+}
+
+// collectRegistries records the internal registry hostnames of the images and pull secrets
+func (red *redaction) collectRegistries(components *model.Components) {
+	var hosts []string
+	for i := range components.Images {
+		hosts = append(hosts, components.Images[i].Registry)
+	}
+	if components.PullSecrets != nil {
+		for i := range components.PullSecrets.Secrets {
+			hosts = append(hosts, components.PullSecrets.Secrets[i].Registries...)
+		}
+	}
+
+	for _, host := range hosts {
+		if host != "" && !isPublicRegistry(host) && !slices.Contains(red.hosts, host) {
+			red.hosts = append(red.hosts, host)
+		}
+	}
+}
+
+// walk redacts the strings, string slices and maps of v, key being the JSON name of the field holding it
+func (red *redaction) walk(v reflect.Value, key string) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			red.walk(v.Elem(), key)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				red.walk(v.Field(i), jsonName(t.Field(i)))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			red.walk(v.Index(i), key)
+		}
+	case reflect.Map:
+		red.walkMap(v, key)
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(red.redactString(key, v.String()))
+		}
+	}
+}
+
+// walkMap drops the labels and annotations matching the metadata patterns and redacts the other map values
+func (red *redaction) walkMap(v reflect.Value, key string) {
+	metadata := red.opts.Metadata && (key == "labels" || key == "annotations") && v.Type().Key().Kind() == reflect.String
+	for _, k := range v.MapKeys() {
+		if metadata && red.matchesMetadata(k.String()) {
+			v.SetMapIndex(k, reflect.Value{})
+			continue
+		}
+
+		value := reflect.New(v.Type().Elem()).Elem()
+		value.Set(v.MapIndex(k))
+		red.walk(value, key)
+		v.SetMapIndex(k, value)
+	}
+}
+
+func (red *redaction) matchesMetadata(key string) bool {
+	for _, pattern := range red.metadata {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactString hashes namespaces by the field they are held in, the recorded names wherever they appear, including
+// in label selectors, and the internal registry hostname of image references
+func (red *redaction) redactString(key, value string) string {
+	if value == "" {
+		return value
+	}
+	if red.opts.Namespaces && (key == "namespace" || key == "namespaces_without_network_policy") {
+		return red.hash(value)
+	}
+	if red.values[value] {
+		return red.hash(value)
+	}
+	if strings.HasSuffix(key, "selector") {
+		return selectorTerm.ReplaceAllStringFunc(value, func(term string) string {
+			if red.values[term] {
+				return red.hash(term)
+			}
+			return term
+		})
+	}
+	for _, host := range red.hosts {
+		if value == host || strings.HasPrefix(value, host+"/") {
+			return red.hash(host) + strings.TrimPrefix(value, host)
+		}
+	}
+
+	return value
+}
+
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" {
+		return f.Name
+	}
+
+	return name
+}
+
+func isPublicRegistry(host string) bool {
+	for _, pattern := range publicRegistries {
+		if ok, _ := path.Match(pattern, host); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,169 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+func testKBOM() *model.KBOM {
+	return &model.KBOM{
+		Cluster: model.Cluster{
+			Name: "prod-eu",
+			Nodes: []model.Node{{
+				Name:        "ip-10-0-1-2.ec2.internal",
+				Hostname:    "ip-10-0-1-2",
+				MachineID:   "ec2d4b4c",
+				BootID:      "0b5c7b4a",
+				Labels:      map[string]string{"kubernetes.io/hostname": "ip-10-0-1-2", "team.example.com/owner": "payments"},
+				Annotations: map[string]string{"node.alpha.kubernetes.io/ttl": "0"},
+			}},
+			Components: model.Components{
+				Images: []model.Image{
+					{FullName: "registry.corp.example.com:5000/payments/api:1.0", Name: "registry.corp.example.com:5000/payments/api",
+						Registry: "registry.corp.example.com:5000"},
+					{FullName: "docker.io/library/nginx:1.25", Name: "docker.io/library/nginx", Registry: "docker.io"},
+				},
+				PullSecrets: &model.PullSecrets{Secrets: []model.PullSecret{
+					{Name: "corp", Namespace: "payments", Registries: []string{"registry.corp.example.com:5000", "vault.corp.example.com"}},
+				}},
+				Resources: map[string]model.ResourceList{
+					"namespaces": {Kind: "Namespace", Resources: []model.Resource{{Name: "payments"}}},
+					"leases": {Kind: "Lease", Resources: []model.Resource{
+						{Name: "ip-10-0-1-2.ec2.internal", Namespace: "kube-node-lease"},
+					}},
+				},
+				Workloads: &model.Workloads{Items: []model.Workload{{Kind: "Deployment", Name: "api", Namespace: "payments",
+					Containers: []model.WorkloadContainer{{Name: "api", Image: "registry.corp.example.com:5000/payments/api:1.0"}}}}},
+			},
+		},
+	}
+}
+
+func TestRedactVendor(t *testing.T) {
+	opts, err := NewOptions([]string{VendorProfile}, []string{"*.example.com/*"})
+	require.NoError(t, err)
+	redactor, err := New(opts, []byte("secret"))
+	require.NoError(t, err)
+
+	kbom := testKBOM()
+	redactor.Redact(kbom)
+
+	node := kbom.Cluster.Nodes[0]
+	nodeName := redactor.hash("ip-10-0-1-2.ec2.internal")
+	assert.Equal(t, nodeName, node.Name)
+	assert.Equal(t, redactor.hash("ip-10-0-1-2"), node.Hostname)
+	assert.Empty(t, node.MachineID)
+	assert.Empty(t, node.BootID)
+	assert.Equal(t, map[string]string{"kubernetes.io/hostname": redactor.hash("ip-10-0-1-2")}, node.Labels)
+	assert.Equal(t, map[string]string{"node.alpha.kubernetes.io/ttl": "0"}, node.Annotations)
+
+	registry := redactor.hash("registry.corp.example.com:5000")
+	images := kbom.Cluster.Components.Images
+	assert.Equal(t, registry+"/payments/api:1.0", images[0].FullName)
+	assert.Equal(t, registry, images[0].Registry)
+	assert.Equal(t, "docker.io/library/nginx:1.25", images[1].FullName)
+	assert.Equal(t, registry+"/payments/api:1.0", kbom.Cluster.Components.Workloads.Items[0].Containers[0].Image)
+	assert.Equal(t, []string{registry, redactor.hash("vault.corp.example.com")}, kbom.Cluster.Components.PullSecrets.Secrets[0].Registries)
+
+	lease := kbom.Cluster.Components.Resources["leases"].Resources[0]
+	assert.Equal(t, nodeName, lease.Name)
+	assert.Equal(t, "kube-node-lease", lease.Namespace)
+	assert.Equal(t, "prod-eu", kbom.Cluster.Name)
+	assert.Equal(t, "payments", kbom.Cluster.Components.Workloads.Items[0].Namespace)
+}
+
+func TestRedactStrict(t *testing.T) {
+	opts, err := NewOptions([]string{StrictProfile}, []string{"*"})
+	require.NoError(t, err)
+	redactor, err := New(opts, []byte("secret"))
+	require.NoError(t, err)
+
+	kbom := testKBOM()
+	redactor.Redact(kbom)
+
+	assert.Equal(t, redactor.hash("prod-eu"), kbom.Cluster.Name)
+	assert.Empty(t, kbom.Cluster.Nodes[0].Labels)
+	assert.Empty(t, kbom.Cluster.Nodes[0].Annotations)
+	assert.Equal(t, redactor.hash("payments"), kbom.Cluster.Components.Resources["namespaces"].Resources[0].Name)
+	assert.Equal(t, redactor.hash("payments"), kbom.Cluster.Components.Workloads.Items[0].Namespace)
+	assert.Equal(t, redactor.hash("kube-node-lease"), kbom.Cluster.Components.Resources["leases"].Resources[0].Namespace)
+
+	// redacting again keeps the hashes, and another key gives other hashes
+	redacted := kbom.Cluster.Name
+	redactor.Redact(kbom)
+	assert.Equal(t, redacted, kbom.Cluster.Name)
+
+	other, err := New(opts, []byte("other"))
+	require.NoError(t, err)
+	assert.NotEqual(t, redacted, other.hash("prod-eu"))
+}
+
+func TestRedactNamespaces(t *testing.T) {
+	opts, err := NewOptions([]string{Namespaces}, nil)
+	require.NoError(t, err)
+	redactor, err := New(opts, []byte("secret"))
+	require.NoError(t, err)
+
+	kbom := &model.KBOM{Cluster: model.Cluster{Components: model.Components{
+		Resources: map[string]model.ResourceList{
+			"namespaces": {Kind: "Namespace", Resources: []model.Resource{{Name: "kyverno"}}},
+		},
+		PodSecurity: &model.PodSecurity{Namespaces: []model.NamespaceSecurity{{
+			Name:      "payments",
+			Workloads: []model.WorkloadSecurity{{Kind: "Deployment", Name: "api", Namespace: "payments"}},
+		}}},
+		Admission: &model.Admission{ValidatingWebhooks: []model.WebhookConfiguration{{
+			Name: "kyverno",
+			Webhooks: []model.Webhook{{
+				Name:              "validate.kyverno.svc",
+				Service:           &model.ServiceReference{Namespace: "kyverno", Name: "kyverno-svc"},
+				NamespaceSelector: "kubernetes.io/metadata.name notin (kyverno,payments)",
+				ObjectSelector:    "app!=payments",
+			}},
+		}}},
+	}}}
+	redactor.Redact(kbom)
+
+	kyverno, payments := redactor.hash("kyverno"), redactor.hash("payments")
+	assert.Equal(t, kyverno, kbom.Cluster.Components.Resources["namespaces"].Resources[0].Name)
+
+	namespace := kbom.Cluster.Components.PodSecurity.Namespaces[0]
+	assert.Equal(t, payments, namespace.Name)
+	assert.Equal(t, payments, namespace.Workloads[0].Namespace)
+	assert.Equal(t, "api", namespace.Workloads[0].Name)
+
+	webhook := kbom.Cluster.Components.Admission.ValidatingWebhooks[0].Webhooks[0]
+	assert.Equal(t, kyverno, webhook.Service.Namespace)
+	assert.Equal(t, "kubernetes.io/metadata.name notin ("+kyverno+","+payments+")", webhook.NamespaceSelector)
+	assert.Equal(t, "app!="+payments, webhook.ObjectSelector)
+	assert.Equal(t, kyverno, kbom.Cluster.Components.Admission.ValidatingWebhooks[0].Name,
+		"every exact occurrence of a namespace name is hashed")
+}
+
+func TestNew(t *testing.T) {
+	opts, err := NewOptions([]string{Metadata}, []string{"*"})
+	require.NoError(t, err)
+	_, err = New(opts, nil)
+	assert.NoError(t, err)
+
+	opts, err = NewOptions([]string{Nodes}, nil)
+	require.NoError(t, err)
+	_, err = New(opts, nil)
+	assert.EqualError(t, err, "a key is required to hash the redacted values")
+
+	_, err = NewOptions([]string{"labels"}, nil)
+	assert.EqualError(t, err,
+		`redaction "labels" is not supported, use one of: vendor, strict, nodes, metadata, namespaces, registries, cluster`)
+}
+
+func TestRedactNil(t *testing.T) {
+	var redactor *Redactor
+	kbom := testKBOM()
+	redactor.Redact(kbom)
+
+	assert.Equal(t, testKBOM(), kbom)
+}
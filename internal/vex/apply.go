@@ -0,0 +1,183 @@
+package vex
+
+import (
+	"slices"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/rad-security/kbom/internal/model"
+	"github.com/rad-security/kbom/internal/vuln"
+)
+
+const Source = "openvex"
+
+type product struct {
+	pkg     string
+	version string
+	target  model.FindingTarget
+}
+
+// Apply applies statements to the KBOM findings. Statements are applied in order, so later
+// statements override earlier ones. Statements about a KBOM product without a matching finding
+// are recorded as new findings, so triage decisions are kept even without a vulnerability database.
+func Apply(kbom *model.KBOM, statements []Statement) {
+	products := kbomProducts(kbom)
+
+	for i := range statements {
+		s := statements[i]
+		for _, p := range s.Products {
+			matched := false
+			for j := range kbom.Findings {
+				f := &kbom.Findings[j]
+				if matchesVulnerability(f, &s.Vulnerability) && productMatches(p.ID, f.Target.Ref) {
+					setStatus(f, &s)
+					matched = true
+				}
+			}
+
+			if matched {
+				continue
+			}
+
+			matches := findProducts(products, p.ID)
+			if len(matches) == 0 {
+				log.Debug().Str("product", p.ID).Str("vulnerability", s.Vulnerability.Name).Msg("Skipping VEX statement for unknown product")
+				continue
+			}
+
+			for _, prod := range matches {
+				f := model.Finding{
+					ID:      s.Vulnerability.Name,
+					Aliases: s.Vulnerability.Aliases,
+					Source:  Source,
+					Package: prod.pkg,
+					Version: prod.version,
+					Target:  prod.target,
+				}
+				setStatus(&f, &s)
+				kbom.Findings = append(kbom.Findings, f)
+			}
+		}
+	}
+}
+
+// Skeleton returns an OpenVEX document with a statement for every finding attached to a product, once per product
+// found on several nodes. When there are no such findings, a single statement listing all products is returned,
+// ready to be filled in with a vulnerability and a status.
+func Skeleton(kbom *model.KBOM, id, author, tooling string, timestamp time.Time) *Document {
+	doc := &Document{
+		Context:    Context,
+		ID:         id,
+		Author:     author,
+		Timestamp:  timestamp,
+		Version:    1,
+		Tooling:    tooling,
+		Statements: make([]Statement, 0),
+	}
+
+	products := kbomProducts(kbom)
+	seen := make(map[string]bool)
+	for i := range kbom.Findings {
+		f := kbom.Findings[i]
+		key := f.ID + "/" + f.Target.Ref
+		if seen[key] || len(findProducts(products, f.Target.Ref)) == 0 {
+			continue
+		}
+		seen[key] = true
+
+		status := f.Status
+		if status == "" {
+			status = StatusUnderInvestigation
+		}
+
+		doc.Statements = append(doc.Statements, Statement{
+			Vulnerability:   Vulnerability{Name: f.ID, Aliases: f.Aliases},
+			Products:        []Product{{ID: f.Target.Ref}},
+			Status:          status,
+			Justification:   f.Justification,
+			ImpactStatement: f.ImpactStatement,
+			ActionStatement: f.ActionStatement,
+		})
+	}
+
+	if len(doc.Statements) == 0 {
+		all := make([]Product, 0, len(products))
+		for _, p := range products {
+			if !slices.Contains(all, Product{ID: p.target.Ref}) {
+				all = append(all, Product{ID: p.target.Ref})
+			}
+		}
+
+		doc.Statements = append(doc.Statements, Statement{
+			Products: all,
+			Status:   StatusUnderInvestigation,
+		})
+	}
+
+	return doc
+}
+
+// kbomProducts returns the cluster, the node software, the resource and helm chart versions and all images, which are
+// the products VEX statements can refer to
+func kbomProducts(kbom *model.KBOM) []product {
+	pkgs := vuln.Packages(kbom)
+	products := make([]product, 0, len(pkgs)+len(kbom.Cluster.Components.Images))
+	for _, pkg := range pkgs {
+		products = append(products, product{pkg: pkg.Names[0].Name, version: pkg.Version, target: pkg.Target})
+	}
+
+	for i := range kbom.Cluster.Components.Images {
+		img := kbom.Cluster.Components.Images[i]
+		version := img.Version
+		if img.Digest != "" {
+			version = img.Digest
+		}
+
+		products = append(products, product{
+			pkg:     img.Name,
+			version: version,
+			target: model.FindingTarget{
+				Type: model.ImageTarget,
+				Name: img.FullName,
+				Ref:  img.PkgID(),
+			},
+		})
+	}
+
+	return products
+}
+
+// findProducts returns the products with the given package URL, e.g. the container runtime of every node running it
+func findProducts(products []product, id string) []product {
+	var matches []product
+	for _, p := range products {
+		if productMatches(id, p.target.Ref) {
+			matches = append(matches, p)
+		}
+	}
+
+	return matches
+}
+
+func matchesVulnerability(f *model.Finding, v *Vulnerability) bool {
+	names := append([]string{v.Name}, v.Aliases...)
+	ids := append([]string{f.ID}, f.Aliases...)
+
+	for _, name := range names {
+		for _, id := range ids {
+			if name == id {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func setStatus(f *model.Finding, s *Statement) {
+	f.Status = s.Status
+	f.Justification = s.Justification
+	f.ImpactStatement = s.ImpactStatement
+	f.ActionStatement = s.ActionStatement
+}
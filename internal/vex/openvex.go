@@ -0,0 +1,128 @@
+package vex
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	Context = "https://openvex.dev/ns/v0.2.0"
+
+	StatusNotAffected        = "not_affected"
+	StatusAffected           = "affected"
+	StatusFixed              = "fixed"
+	StatusUnderInvestigation = "under_investigation"
+)
+
+// Document is an OpenVEX document (https://github.com/openvex/spec)
+type Document struct {
+	Context    string      `json:"@context"`
+	ID         string      `json:"@id"`
+	Author     string      `json:"author"`
+	Timestamp  time.Time   `json:"timestamp"`
+	Version    int         `json:"version"`
+	Tooling    string      `json:"tooling,omitempty"`
+	Statements []Statement `json:"statements"`
+}
+
+type Statement struct {
+	Vulnerability   Vulnerability `json:"vulnerability"`
+	Products        []Product     `json:"products"`
+	Status          string        `json:"status"`
+	StatusNotes     string        `json:"status_notes,omitempty"`
+	Justification   string        `json:"justification,omitempty"`
+	ImpactStatement string        `json:"impact_statement,omitempty"`
+	ActionStatement string        `json:"action_statement,omitempty"`
+}
+
+type Vulnerability struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// UnmarshalJSON accepts both the v0.2 object form and the pre-v0.2 plain string form
+func (v *Vulnerability) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		v.Name = name
+		return nil
+	}
+
+	type vulnerability Vulnerability
+	return json.Unmarshal(data, (*vulnerability)(v))
+}
+
+type Product struct {
+	ID string `json:"@id"`
+}
+
+// UnmarshalJSON accepts both the v0.2 object form and the pre-v0.2 plain string form
+func (p *Product) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil {
+		p.ID = id
+		return nil
+	}
+
+	type product Product
+	return json.Unmarshal(data, (*product)(p))
+}
+
+// Load reads statements from all the given OpenVEX documents, in order
+func Load(paths []string) ([]Statement, error) {
+	statements := make([]Statement, 0)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		doc := &Document{}
+		if err := json.Unmarshal(data, doc); err != nil {
+			return nil, fmt.Errorf("failed to read OpenVEX document %q: %w", path, err)
+		}
+
+		for i := range doc.Statements {
+			if err := validate(&doc.Statements[i]); err != nil {
+				return nil, fmt.Errorf("invalid statement in OpenVEX document %q: %w", path, err)
+			}
+		}
+
+		statements = append(statements, doc.Statements...)
+	}
+
+	return statements, nil
+}
+
+func validate(s *Statement) error {
+	if s.Vulnerability.Name == "" {
+		return fmt.Errorf("vulnerability name is empty")
+	}
+
+	switch s.Status {
+	case StatusNotAffected, StatusAffected, StatusFixed, StatusUnderInvestigation:
+		return nil
+	default:
+		return fmt.Errorf("unknown status %q for %s", s.Status, s.Vulnerability.Name)
+	}
+}
+
+// productMatches compares package URLs ignoring qualifiers, so that e.g. a product without
+// the repository_url qualifier still matches the image purl
+func productMatches(product, purl string) bool {
+	if product == "" || purl == "" {
+		return false
+	}
+
+	if product == purl {
+		return true
+	}
+
+	p, _, _ := strings.Cut(product, "?")
+	u, _, _ := strings.Cut(purl, "?")
+
+	return p == u
+}
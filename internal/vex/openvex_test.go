@@ -0,0 +1,195 @@
+package vex
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+const (
+	clusterPurl = "pkg:k8s/k8s.io%2Fkubernetes@1.25.1"
+	imagePurl   = "pkg:oci/nginx@sha256%3A0001?repository_url=docker.io%2Flibrary%2Fnginx&tag=1.25"
+	runtimePurl = "pkg:generic/containerd@1.7.2"
+	chartPurl   = "pkg:generic/ingress-nginx@4.7.1"
+)
+
+const documentV020 = `{
+  "@context": "https://openvex.dev/ns/v0.2.0",
+  "@id": "https://example.com/vex-1",
+  "author": "security",
+  "timestamp": "2023-04-26T10:00:00Z",
+  "version": 1,
+  "statements": [
+    {
+      "vulnerability": {"name": "CVE-2023-0001"},
+      "products": [{"@id": "pkg:k8s/k8s.io%2Fkubernetes@1.25.1"}],
+      "status": "not_affected",
+      "justification": "vulnerable_code_not_in_execute_path",
+      "impact_statement": "feature gate disabled"
+    },
+    {
+      "vulnerability": {"name": "CVE-2023-0002"},
+      "products": [{"@id": "pkg:oci/nginx@sha256%3A0001"}, {"@id": "pkg:oci/unknown@sha256%3A0002"}],
+      "status": "under_investigation"
+    }
+  ]
+}`
+
+const documentV001 = `{
+  "@context": "https://openvex.dev/ns",
+  "@id": "https://example.com/vex-2",
+  "author": "security",
+  "timestamp": "2023-04-27T10:00:00Z",
+  "version": 1,
+  "statements": [
+    {
+      "vulnerability": "CVE-2023-0002",
+      "products": ["pkg:oci/nginx@sha256%3A0001?repository_url=docker.io%2Flibrary%2Fnginx&tag=1.25"],
+      "status": "fixed"
+    }
+  ]
+}`
+
+func testKBOM() *model.KBOM {
+	return &model.KBOM{
+		Cluster: model.Cluster{
+			Name:       "test-cluster",
+			K8sVersion: "1.25.1",
+			Nodes: []model.Node{
+				{Name: "node-1", Runtime: &model.Software{Name: "containerd", Version: "1.7.2"}},
+				{Name: "node-2", Runtime: &model.Software{Name: "containerd", Version: "1.7.2"}},
+			},
+			Components: model.Components{
+				Images: []model.Image{
+					{
+						FullName: "nginx:1.25",
+						Name:     "docker.io/library/nginx",
+						Version:  "1.25",
+						Digest:   "sha256:0001",
+					},
+				},
+			},
+		},
+		Findings: []model.Finding{
+			{
+				ID:      "GO-2023-0001",
+				Aliases: []string{"CVE-2023-0001"},
+				Source:  "osv",
+				Target: model.FindingTarget{
+					Type: model.ClusterTarget,
+					Name: "test-cluster",
+					Ref:  clusterPurl,
+				},
+			},
+			{
+				ID:     "GO-2023-0003",
+				Source: "osv",
+				Target: model.FindingTarget{Type: model.NodeTarget, Name: "node-1", Ref: runtimePurl},
+			},
+			{
+				ID:     "GO-2023-0003",
+				Source: "osv",
+				Target: model.FindingTarget{Type: model.NodeTarget, Name: "node-2", Ref: runtimePurl},
+			},
+		},
+	}
+}
+
+func TestLoadAndApply(t *testing.T) {
+	dir := t.TempDir()
+	v2 := filepath.Join(dir, "v2.json")
+	v1 := filepath.Join(dir, "v1.json")
+	require.NoError(t, os.WriteFile(v2, []byte(documentV020), 0o600))
+	require.NoError(t, os.WriteFile(v1, []byte(documentV001), 0o600))
+
+	statements, err := Load([]string{v2, v1})
+	require.NoError(t, err)
+	require.Len(t, statements, 3)
+	assert.Equal(t, "CVE-2023-0002", statements[2].Vulnerability.Name)
+	assert.Equal(t, imagePurl, statements[2].Products[0].ID)
+
+	kbom := testKBOM()
+	Apply(kbom, statements)
+
+	require.Len(t, kbom.Findings, 4)
+
+	assert.Equal(t, StatusNotAffected, kbom.Findings[0].Status)
+	assert.Equal(t, "vulnerable_code_not_in_execute_path", kbom.Findings[0].Justification)
+	assert.Equal(t, "feature gate disabled", kbom.Findings[0].ImpactStatement)
+
+	assert.Empty(t, kbom.Findings[1].Status)
+
+	assert.Equal(t, "CVE-2023-0002", kbom.Findings[3].ID)
+	assert.Equal(t, Source, kbom.Findings[3].Source)
+	assert.Equal(t, model.ImageTarget, kbom.Findings[3].Target.Type)
+	assert.Equal(t, imagePurl, kbom.Findings[3].Target.Ref)
+	assert.Equal(t, "sha256:0001", kbom.Findings[3].Version)
+	assert.Equal(t, StatusFixed, kbom.Findings[3].Status, "later statements override earlier ones")
+}
+
+func TestApplyNodeAndResourceProducts(t *testing.T) {
+	kbom := testKBOM()
+	kbom.Cluster.Components.Resources = map[string]model.ResourceList{
+		"deployments": {Kind: "Deployment", Resources: []model.Resource{{
+			Name:                 "ingress-nginx-controller",
+			Namespace:            "ingress-nginx",
+			AdditionalProperties: map[string]string{"chart": "ingress-nginx-4.7.1"},
+		}}},
+	}
+
+	Apply(kbom, []Statement{
+		{Vulnerability: Vulnerability{Name: "GO-2023-0003"}, Products: []Product{{ID: runtimePurl}}, Status: StatusNotAffected},
+		{Vulnerability: Vulnerability{Name: "CVE-2023-0004"}, Products: []Product{{ID: chartPurl}}, Status: StatusAffected},
+	})
+
+	require.Len(t, kbom.Findings, 4)
+	assert.Equal(t, StatusNotAffected, kbom.Findings[1].Status, "the container runtime of every node is not affected")
+	assert.Equal(t, StatusNotAffected, kbom.Findings[2].Status)
+
+	chart := kbom.Findings[3]
+	assert.Equal(t, "CVE-2023-0004", chart.ID)
+	assert.Equal(t, StatusAffected, chart.Status)
+	assert.Equal(t, "ingress-nginx", chart.Package)
+	assert.Equal(t, "4.7.1", chart.Version)
+	assert.Equal(t, model.FindingTarget{
+		Type:      model.ResourceTarget,
+		Kind:      "Deployment",
+		Name:      "ingress-nginx-controller",
+		Namespace: "ingress-nginx",
+		Ref:       chartPurl,
+	}, chart.Target)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalid.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"statements": [{"vulnerability": "CVE-1", "status": "maybe"}]}`), 0o600))
+
+	_, err := Load([]string{path})
+	assert.ErrorContains(t, err, `unknown status "maybe" for CVE-1`)
+}
+
+func TestSkeleton(t *testing.T) {
+	timestamp := time.Date(2023, 4, 26, 10, 0, 0, 0, time.UTC)
+
+	doc := Skeleton(testKBOM(), "urn:uuid:1", "author", "kbom/1.0.0", timestamp)
+	assert.Equal(t, Context, doc.Context)
+	require.Len(t, doc.Statements, 2, "the runtime found on both nodes gets a single statement")
+	assert.Equal(t, "GO-2023-0001", doc.Statements[0].Vulnerability.Name)
+	assert.Equal(t, []Product{{ID: clusterPurl}}, doc.Statements[0].Products)
+	assert.Equal(t, StatusUnderInvestigation, doc.Statements[0].Status)
+	assert.Equal(t, "GO-2023-0003", doc.Statements[1].Vulnerability.Name)
+	assert.Equal(t, []Product{{ID: runtimePurl}}, doc.Statements[1].Products)
+
+	kbom := testKBOM()
+	kbom.Findings = nil
+
+	doc = Skeleton(kbom, "urn:uuid:2", "author", "kbom/1.0.0", timestamp)
+	require.Len(t, doc.Statements, 1)
+	assert.Equal(t, []Product{{ID: clusterPurl}, {ID: runtimePurl}, {ID: imagePurl}}, doc.Statements[0].Products)
+}
@@ -0,0 +1,188 @@
+package vuln
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+const (
+	Source = "osv"
+
+	kubernetesPkgName = "k8s.io/kubernetes"
+	ecosystemGo       = "Go"
+	chartProperty     = "chart"
+	versionProperty   = "version"
+)
+
+// runtimePackages maps container runtime names reported by the kubelet to their OSV package names
+var runtimePackages = map[string]string{
+	"containerd": "github.com/containerd/containerd",
+	"cri-o":      "github.com/cri-o/cri-o",
+	"docker":     "github.com/docker/docker",
+}
+
+// Package is a versioned piece of software found in the KBOM which can be matched against advisories
+type Package struct {
+	Names   []PackageName
+	Version string
+	Target  model.FindingTarget
+}
+
+// PackageName is a name advisories may refer to the package by. Ecosystem is the OSV ecosystem of the name, empty for
+// names only advisories without an ecosystem use, like those of a custom database.
+type PackageName struct {
+	Ecosystem string
+	Name      string
+}
+
+// Packages returns all packages from the KBOM that can be matched against the vulnerability database
+func Packages(kbom *model.KBOM) []Package {
+	pkgs := []Package{
+		{
+			Names:   []PackageName{{Ecosystem: ecosystemGo, Name: kubernetesPkgName}, {Name: "kubernetes"}},
+			Version: kbom.Cluster.K8sVersion,
+			Target: model.FindingTarget{
+				Type: model.ClusterTarget,
+				Name: kbom.Cluster.Name,
+				Ref:  kbom.Cluster.BOMRef(),
+			},
+		},
+	}
+
+	for i := range kbom.Cluster.Nodes {
+		n := kbom.Cluster.Nodes[i]
+
+		if n.KubeletVersion != "" {
+			pkgs = append(pkgs, Package{
+				Names:   []PackageName{{Ecosystem: ecosystemGo, Name: kubernetesPkgName}, {Name: "kubernetes"}, {Name: "kubelet"}},
+				Version: n.KubeletVersion,
+				Target:  nodeTarget(&n, &model.Software{Name: "kubelet", Version: n.KubeletVersion}),
+			})
+		}
+
+		// KBOMs generated before the runtime was parsed only have the raw version
+		runtime := n.Runtime
+		if runtime == nil {
+			runtime = model.ParseRuntime(n.ContainerRuntimeVersion)
+		}
+
+		if runtime != nil && runtime.Version != "" {
+			names := []PackageName{{Name: runtime.Name}}
+			if pkgName, ok := runtimePackages[runtime.Name]; ok {
+				names = append(names, PackageName{Ecosystem: ecosystemGo, Name: pkgName})
+			}
+
+			pkgs = append(pkgs, Package{
+				Names:   names,
+				Version: runtime.Version,
+				Target:  nodeTarget(&n, runtime),
+			})
+		}
+	}
+
+	for _, resList := range kbom.Cluster.Components.Resources {
+		for _, res := range resList.Resources {
+			if version, ok := res.AdditionalProperties[versionProperty]; ok {
+				name := strings.ToLower(resList.Kind)
+				pkgs = append(pkgs, Package{
+					Names:   []PackageName{{Name: name}},
+					Version: version,
+					Target:  resourceTarget(resList.Kind, &res, &model.Software{Name: name, Version: version}),
+				})
+			}
+
+			if chart, ok := res.AdditionalProperties[chartProperty]; ok {
+				if name, version, ok := splitChart(chart); ok {
+					pkgs = append(pkgs, Package{
+						Names:   []PackageName{{Name: name}},
+						Version: version,
+						Target:  resourceTarget(resList.Kind, &res, &model.Software{Name: name, Version: version}),
+					})
+				}
+			}
+		}
+	}
+
+	return pkgs
+}
+
+// nodeTarget returns the target of the node software, referenced by its package URL so VEX statements can refer to it
+func nodeTarget(n *model.Node, software *model.Software) model.FindingTarget {
+	return model.FindingTarget{Type: model.NodeTarget, Name: n.Name, Ref: software.PkgID()}
+}
+
+// resourceTarget returns the target of the resource or helm chart version, referenced by its package URL so VEX
+// statements can refer to it
+func resourceTarget(kind string, res *model.Resource, software *model.Software) model.FindingTarget {
+	return model.FindingTarget{Type: model.ResourceTarget, Kind: kind, Name: res.Name, Namespace: res.Namespace, Ref: software.PkgID()}
+}
+
+// Match returns findings for all packages affected by advisories in the database
+func (db *DB) Match(pkgs []Package) []model.Finding {
+	findings := make([]model.Finding, 0)
+	for _, pkg := range pkgs {
+		version, err := parseVersion(pkg.Version)
+		if err != nil {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, name := range pkg.Names {
+			for _, adv := range db.advisories[strings.ToLower(name.Name)] {
+				if seen[adv.ID] {
+					continue
+				}
+
+				affected, fixed := adv.affects(name, version)
+				if !affected {
+					continue
+				}
+
+				seen[adv.ID] = true
+				severity, cvss := adv.severity()
+				findings = append(findings, model.Finding{
+					ID:       adv.ID,
+					Aliases:  adv.Aliases,
+					Summary:  adv.Summary,
+					Severity: severity,
+					CVSS:     cvss,
+					Source:   Source,
+					Package:  name.Name,
+					Version:  pkg.Version,
+					Fixed:    fixed,
+					Target:   pkg.Target,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Target != findings[j].Target {
+			return targetKey(findings[i].Target) < targetKey(findings[j].Target)
+		}
+
+		return findings[i].ID < findings[j].ID
+	})
+
+	return findings
+}
+
+func targetKey(t model.FindingTarget) string {
+	return strings.Join([]string{t.Type, t.Kind, t.Namespace, t.Name, t.Ref}, "/")
+}
+
+// splitChart splits helm chart label value (e.g. "ingress-nginx-4.7.1") into chart name and version
+func splitChart(chart string) (name, version string, ok bool) {
+	idx := strings.LastIndex(chart, "-")
+	for idx > 0 {
+		if _, err := parseVersion(chart[idx+1:]); err == nil {
+			return chart[:idx], chart[idx+1:], true
+		}
+
+		idx = strings.LastIndex(chart[:idx], "-")
+	}
+
+	return "", "", false
+}
@@ -0,0 +1,259 @@
+package vuln
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	rangeSemver    = "SEMVER"
+	rangeEcosystem = "ECOSYSTEM"
+)
+
+// preRelease matches the pre-release identifiers of upstream releases, e.g. rc.1 or alpha2, unlike vendor suffixes
+var preRelease = regexp.MustCompile(`(?i)^(alpha|beta|rc|pre)(\.?\d+)*$`)
+
+// Advisory is the subset of the OSV schema (https://ossf.github.io/osv-schema/) used for matching
+type Advisory struct {
+	ID               string                 `json:"id"`
+	Aliases          []string               `json:"aliases"`
+	Summary          string                 `json:"summary"`
+	Details          string                 `json:"details"`
+	Severity         []Severity             `json:"severity"`
+	Affected         []Affected             `json:"affected"`
+	DatabaseSpecific map[string]interface{} `json:"database_specific"`
+}
+
+type Severity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type Affected struct {
+	Package  AffectedPackage `json:"package"`
+	Ranges   []Range         `json:"ranges"`
+	Versions []string        `json:"versions"`
+}
+
+type AffectedPackage struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	Purl      string `json:"purl"`
+}
+
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// DB is an in-memory index of OSV advisories keyed by lower-cased package name
+type DB struct {
+	advisories map[string][]*Advisory
+}
+
+// LoadDB reads all OSV advisories (*.json) found in the dir and its subdirectories
+func LoadDB(dir string) (*DB, error) {
+	db := &DB{advisories: make(map[string][]*Advisory)}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		adv := &Advisory{}
+		if err := json.Unmarshal(data, adv); err != nil {
+			log.Debug().Err(err).Str("path", path).Msg("Skipping file which is not an OSV advisory")
+			return nil
+		}
+
+		db.add(adv)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vulnerability database: %w", err)
+	}
+
+	return db, nil
+}
+
+func (db *DB) add(adv *Advisory) {
+	if adv.ID == "" {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, affected := range adv.Affected {
+		name := strings.ToLower(affected.Package.Name)
+		if name == "" || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+		db.advisories[name] = append(db.advisories[name], adv)
+	}
+}
+
+// Len returns the number of indexed advisory entries
+func (db *DB) Len() int {
+	count := 0
+	for _, advs := range db.advisories {
+		count += len(advs)
+	}
+
+	return count
+}
+
+// affects returns true and the list of fixed versions if the advisory affects the given package version
+func (a *Advisory) affects(name PackageName, version *semver.Version) (bool, []string) {
+	for _, affected := range a.Affected {
+		if !strings.EqualFold(affected.Package.Name, name.Name) || !matchesEcosystem(affected.Package.Ecosystem, name.Ecosystem) {
+			continue
+		}
+
+		fixed := fixedVersions(affected.Ranges)
+		for _, v := range affected.Versions {
+			if parsed, err := parseVersion(v); err == nil && parsed.Equal(version) {
+				return true, fixed
+			}
+		}
+
+		for _, r := range affected.Ranges {
+			if (r.Type == rangeSemver || r.Type == rangeEcosystem) && inRange(r.Events, version) {
+				return true, fixed
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func (a *Advisory) severity() (severity, cvss string) {
+	for _, s := range a.Severity {
+		if strings.HasPrefix(s.Type, "CVSS_") {
+			cvss = s.Score
+		}
+	}
+
+	if a.DatabaseSpecific != nil {
+		if s, ok := a.DatabaseSpecific["severity"].(string); ok {
+			severity = strings.ToLower(s)
+		}
+	}
+
+	if severity == "moderate" {
+		severity = "medium"
+	}
+
+	return severity, cvss
+}
+
+// matchesEcosystem reports whether the OSV ecosystem of an affected package is the one of the package name, ignoring
+// the release suffix of ecosystems like Debian:12
+func matchesEcosystem(ecosystem, expected string) bool {
+	ecosystem, _, _ = strings.Cut(ecosystem, ":")
+
+	return strings.EqualFold(ecosystem, expected)
+}
+
+// inRange evaluates OSV range events as described in the OSV schema specification
+func inRange(events []Event, version *semver.Version) bool {
+	type point struct {
+		version *semver.Version
+		event   Event
+	}
+
+	points := make([]point, 0, len(events))
+	for _, e := range events {
+		raw := e.Introduced + e.Fixed + e.LastAffected + e.Limit
+		if e.Introduced == "0" {
+			raw = "0.0.0"
+		}
+
+		v, err := parseVersion(raw)
+		if err != nil {
+			continue
+		}
+
+		points = append(points, point{version: v, event: e})
+	}
+
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].version.LessThan(points[j].version)
+	})
+
+	affected := false
+	for _, p := range points {
+		switch {
+		case p.event.Introduced != "":
+			if !p.version.GreaterThan(version) {
+				affected = true
+			}
+		case p.event.Fixed != "", p.event.Limit != "":
+			if !p.version.GreaterThan(version) {
+				affected = false
+			}
+		case p.event.LastAffected != "":
+			if p.version.LessThan(version) {
+				affected = false
+			}
+		}
+	}
+
+	return affected
+}
+
+func fixedVersions(ranges []Range) []string {
+	fixed := make([]string, 0)
+	for _, r := range ranges {
+		for _, e := range r.Events {
+			if e.Fixed != "" {
+				fixed = append(fixed, e.Fixed)
+			}
+		}
+	}
+
+	return fixed
+}
+
+// parseVersion parses version ignoring leading "v", build metadata and vendor suffixes, so that vendor builds like
+// "v1.24.6-eks-4360b32" or "1.6.8+bottlerocket" match upstream releases. Pre-releases like "1.29.0-rc.1" are kept,
+// as they come before the release by semver rules.
+func parseVersion(version string) (*semver.Version, error) {
+	v, err := semver.NewVersion(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return nil, err
+	}
+
+	core := fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor(), v.Patch())
+	if preRelease.MatchString(v.Prerelease()) {
+		core += "-" + v.Prerelease()
+	}
+
+	return semver.NewVersion(core)
+}
@@ -0,0 +1,191 @@
+package vuln
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rad-security/kbom/internal/model"
+)
+
+const k8sAdvisory = `{
+  "id": "GO-2023-0001",
+  "aliases": ["CVE-2023-0001"],
+  "summary": "Kubernetes privilege escalation",
+  "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H"}],
+  "affected": [{
+    "package": {"ecosystem": "Go", "name": "k8s.io/kubernetes"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.24.7"}, {"introduced": "1.25.0"}, {"fixed": "1.25.3"}]}]
+  }],
+  "database_specific": {"severity": "HIGH"}
+}`
+
+const containerdAdvisory = `{
+  "id": "GHSA-0002",
+  "summary": "containerd issue",
+  "affected": [{
+    "package": {"ecosystem": "Go", "name": "github.com/containerd/containerd"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "1.6.0"}, {"last_affected": "1.6.8"}]}]
+  }],
+  "database_specific": {"severity": "MODERATE"}
+}`
+
+const chartAdvisory = `{
+  "id": "OSV-0003",
+  "affected": [{
+    "package": {"name": "ingress-nginx"},
+    "versions": ["4.7.1"]
+  }]
+}`
+
+// debianAdvisory is about the Debian package of containerd, whose versions are not the upstream ones
+const debianAdvisory = `{
+  "id": "DSA-0004",
+  "affected": [{
+    "package": {"ecosystem": "Debian:12", "name": "containerd"},
+    "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "1.6.20~ds1-1"}]}]
+  }]
+}`
+
+func writeDB(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "go"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go", "GO-2023-0001.json"), []byte(k8sAdvisory), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "GHSA-0002.json"), []byte(containerdAdvisory), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "OSV-0003.json"), []byte(chartAdvisory), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "DSA-0004.json"), []byte(debianAdvisory), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not an advisory"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o600))
+
+	return dir
+}
+
+func TestMatch(t *testing.T) {
+	db, err := LoadDB(writeDB(t))
+	require.NoError(t, err)
+	assert.Equal(t, 4, db.Len())
+
+	kbom := &model.KBOM{
+		Cluster: model.Cluster{
+			Name:       "test-cluster",
+			K8sVersion: "1.25.1",
+			Nodes: []model.Node{
+				{
+					Name:                    "node-1",
+					KubeletVersion:          "v1.24.6-eks-4360b32",
+					ContainerRuntimeVersion: "containerd://1.6.8+bottlerocket",
+					Runtime:                 &model.Software{Name: "containerd", Version: "1.6.8"},
+				},
+				{
+					Name:                    "node-2",
+					KubeletVersion:          "v1.25.3",
+					ContainerRuntimeVersion: "containerd://1.7.2",
+					Runtime:                 &model.Software{Name: "containerd", Version: "1.7.2"},
+				},
+				{
+					// KBOMs generated before the runtime was parsed
+					Name:                    "node-3",
+					KubeletVersion:          "v1.25.3",
+					ContainerRuntimeVersion: "containerd://1.6.2",
+				},
+			},
+			Components: model.Components{
+				Resources: map[string]model.ResourceList{
+					"apps/v1, Resource=deployments": {
+						Kind: "Deployment",
+						Resources: []model.Resource{
+							{
+								Name:                 "ingress-nginx-controller",
+								Namespace:            "ingress-nginx",
+								AdditionalProperties: map[string]string{"chart": "ingress-nginx-4.7.1"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	findings := db.Match(Packages(kbom))
+
+	require.Len(t, findings, 5, "the advisory of the Debian containerd package does not match the runtime")
+
+	assert.Equal(t, "GO-2023-0001", findings[0].ID)
+	assert.Equal(t, model.ClusterTarget, findings[0].Target.Type)
+	assert.Equal(t, kbom.Cluster.BOMRef(), findings[0].Target.Ref)
+	assert.Equal(t, "high", findings[0].Severity)
+	assert.Equal(t, []string{"1.24.7", "1.25.3"}, findings[0].Fixed)
+
+	assert.Equal(t, "GHSA-0002", findings[1].ID)
+	assert.Equal(t, "node-1", findings[1].Target.Name)
+	assert.Equal(t, "pkg:generic/containerd@1.6.8", findings[1].Target.Ref)
+	assert.Equal(t, "medium", findings[1].Severity)
+
+	assert.Equal(t, "GO-2023-0001", findings[2].ID)
+	assert.Equal(t, "node-1", findings[2].Target.Name)
+	assert.Equal(t, "pkg:generic/kubelet@v1.24.6-eks-4360b32", findings[2].Target.Ref)
+
+	assert.Equal(t, "GHSA-0002", findings[3].ID)
+	assert.Equal(t, "node-3", findings[3].Target.Name, "the runtime is parsed from the raw version of older KBOMs")
+	assert.Equal(t, "1.6.2", findings[3].Version)
+
+	assert.Equal(t, "OSV-0003", findings[4].ID)
+	assert.Equal(t, model.ResourceTarget, findings[4].Target.Type)
+	assert.Equal(t, "Deployment", findings[4].Target.Kind)
+	assert.Equal(t, "4.7.1", findings[4].Version)
+	assert.Equal(t, "pkg:generic/ingress-nginx@4.7.1", findings[4].Target.Ref)
+}
+
+func TestInRange(t *testing.T) {
+	events := []Event{{Introduced: "0"}, {Fixed: "1.24.7"}, {Introduced: "1.25.0"}, {LastAffected: "1.25.2"}}
+
+	testCases := []struct {
+		version  string
+		expected bool
+	}{
+		{version: "1.23.0", expected: true},
+		{version: "1.24.7", expected: false},
+		{version: "1.24.9", expected: false},
+		{version: "1.25.0", expected: true},
+		{version: "1.25.2", expected: true},
+		{version: "1.25.3", expected: false},
+		{version: "1.24.7-rc.1", expected: true},
+		{version: "1.24.7-eks-4360b32", expected: false},
+		{version: "1.25.0-alpha.2", expected: false},
+		{version: "v1.25.3-gke.1200+build", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.version, func(t *testing.T) {
+			v, err := parseVersion(tc.version)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, inRange(events, v))
+		})
+	}
+}
+
+func TestSplitChart(t *testing.T) {
+	testCases := []struct {
+		chart   string
+		name    string
+		version string
+		ok      bool
+	}{
+		{chart: "ingress-nginx-4.7.1", name: "ingress-nginx", version: "4.7.1", ok: true},
+		{chart: "cert-manager-v1.13.0", name: "cert-manager", version: "v1.13.0", ok: true},
+		{chart: "app-1.0.0-rc.1", name: "app", version: "1.0.0-rc.1", ok: true},
+		{chart: "no-version", ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.chart, func(t *testing.T) {
+			name, version, ok := splitChart(tc.chart)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.name, name)
+			assert.Equal(t, tc.version, version)
+		})
+	}
+}